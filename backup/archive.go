@@ -0,0 +1,331 @@
+// Package backup snapshots the league into archives, and restores them
+// into empty databases.
+//
+// Archives are gzipped tarballs. They hold a manifest describing the
+// archive, and a file for each collection with a document per line, in
+// MongoDB's canonical Extended JSON so IDs and dates survive the trip.
+package backup
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"gomoney-mock-epl/database"
+	customErrors "gomoney-mock-epl/errors"
+	"io"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+const (
+	// Format tells archives apart from other gzipped tarballs.
+	Format = "mock-epl-backup"
+	// Version is the version of the archive format this server writes.
+	// Archives of later versions can't be restored.
+	Version = 1
+	// ContentType is the media type of archives.
+	ContentType  = "application/gzip"
+	manifestFile = "manifest.json"
+	// maxDocument is the size of the largest document MongoDB stores.
+	maxDocument = 16 * 1024 * 1024
+)
+
+// sections are the collections in archives, in the order they are
+// restored. Collections come before the collections that reference them.
+var sections = []string{
+	database.StadiumsCollection,
+	database.TeamsCollection,
+	database.SeasonsCollection,
+	database.OfficialsCollection,
+	database.PlayersCollection,
+	database.TransfersCollection,
+	database.FixturesCollection,
+	database.UsersCollection,
+	database.AdminsCollection,
+}
+
+// reference is a field holding the IDs of documents in another section.
+// Fields inside arrays of documents are written with dots, like
+// officials.official.
+type reference struct {
+	section, field, to string
+	// required references must be set.
+	required bool
+}
+
+// references are the references between sections that restored
+// documents must not break.
+var references = []reference{
+	{section: database.TeamsCollection, field: "stadium", to: database.StadiumsCollection},
+	{section: database.SeasonsCollection, field: "teams", to: database.TeamsCollection},
+	{section: database.PlayersCollection, field: "team", to: database.TeamsCollection},
+	{section: database.PlayersCollection, field: "season", to: database.SeasonsCollection},
+	{section: database.TransfersCollection, field: "player", to: database.PlayersCollection, required: true},
+	{section: database.TransfersCollection, field: "from", to: database.TeamsCollection},
+	{section: database.TransfersCollection, field: "to", to: database.TeamsCollection},
+	{section: database.FixturesCollection, field: "home_team", to: database.TeamsCollection, required: true},
+	{section: database.FixturesCollection, field: "away_team", to: database.TeamsCollection, required: true},
+	{section: database.FixturesCollection, field: "season", to: database.SeasonsCollection},
+	{section: database.FixturesCollection, field: "venue", to: database.StadiumsCollection},
+	{section: database.FixturesCollection, field: "officials.official", to: database.OfficialsCollection},
+	{section: database.FixturesCollection, field: "performances.player", to: database.PlayersCollection},
+}
+
+func sectionFile(section string) string {
+	return section + ".jsonl"
+}
+
+// FileName is the name archives made at t are saved under.
+func FileName(t time.Time) string {
+	return "mock-epl-" + t.UTC().Format("20060102T150405Z") + ".tar.gz"
+}
+
+// Manifest describes an archive.
+type Manifest struct {
+	Format    string    `json:"format"`
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"created_at"`
+	// Passwords reports whether the password hashes of users and admins
+	// were exported. Accounts restored without them can't log in.
+	Passwords bool `json:"passwords"`
+	// Counts are the number of documents in each collection.
+	Counts map[string]int `json:"counts"`
+}
+
+// archive is the contents of an archive: the manifest, and the
+// documents of each collection.
+type archive struct {
+	Manifest
+	docs map[string][]bson.D
+}
+
+// invalidArchive is the error for archives that can't be restored.
+func invalidArchive(details ...customErrors.ValidationErrorDetails) error {
+	return customErrors.ValidationError{
+		Code:    "backup/invalid-archive",
+		Message: "The archive can't be restored.",
+		Details: details,
+	}
+}
+
+func invalidFile(file, message string) error {
+	return invalidArchive(customErrors.ValidationErrorDetails{Field: file, Message: message})
+}
+
+func writeFile(tw *tar.Writer, name string, data []byte, modTime time.Time) error {
+	err := tw.WriteHeader(&tar.Header{
+		Name:    name,
+		Mode:    0644,
+		Size:    int64(len(data)),
+		ModTime: modTime,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = tw.Write(data)
+	return err
+}
+
+// write writes the archive to w, the manifest first.
+func (a archive) write(w io.Writer) error {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	manifest, err := json.MarshalIndent(a.Manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := writeFile(tw, manifestFile, manifest, a.CreatedAt); err != nil {
+		return err
+	}
+	for _, section := range sections {
+		lines := bytes.Buffer{}
+		for _, doc := range a.docs[section] {
+			line, err := bson.MarshalExtJSON(doc, true, false)
+			if err != nil {
+				return err
+			}
+			lines.Write(line)
+			lines.WriteByte('\n')
+		}
+		if err := writeFile(tw, sectionFile(section), lines.Bytes(), a.CreatedAt); err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// readDocuments reads the documents of a collection, one per line.
+func readDocuments(file string, data []byte) ([]bson.D, error) {
+	docs := []bson.D{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(nil, maxDocument)
+	for scanner.Scan() {
+		doc := bson.D{}
+		if err := bson.UnmarshalExtJSON(scanner.Bytes(), true, &doc); err != nil {
+			return nil, invalidFile(fmt.Sprintf("%s line %d", file, len(docs)+1), err.Error())
+		}
+		docs = append(docs, doc)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, invalidFile(file, err.Error())
+	}
+	return docs, nil
+}
+
+// read reads an archive written by this version of the server or an
+// earlier one. The documents must add up to the counts in the manifest.
+func read(r io.Reader) (*archive, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, invalidFile("archive", "The archive is not gzipped")
+	}
+	defer gz.Close()
+	known := map[string]bool{manifestFile: true}
+	for _, section := range sections {
+		known[sectionFile(section)] = true
+	}
+	files := map[string][]byte{}
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, invalidFile("archive", err.Error())
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		if !known[header.Name] {
+			return nil, invalidFile(header.Name, "The file doesn't belong in archives")
+		}
+		if files[header.Name], err = ioutil.ReadAll(tr); err != nil {
+			return nil, invalidFile(header.Name, err.Error())
+		}
+	}
+
+	data, ok := files[manifestFile]
+	if !ok {
+		return nil, invalidFile(manifestFile, "The manifest is missing")
+	}
+	a := &archive{docs: map[string][]bson.D{}}
+	if err := json.Unmarshal(data, &a.Manifest); err != nil {
+		return nil, invalidFile(manifestFile, err.Error())
+	}
+	if a.Format != Format {
+		return nil, invalidFile(manifestFile, fmt.Sprintf("The archive is not a %s archive", Format))
+	}
+	if a.Version < 1 || a.Version > Version {
+		return nil, invalidFile(manifestFile,
+			fmt.Sprintf("Version %d archives can't be restored, only up to version %d", a.Version, Version))
+	}
+	for _, section := range sections {
+		file := sectionFile(section)
+		data, ok := files[file]
+		if !ok {
+			return nil, invalidFile(file, "The file is missing")
+		}
+		docs, err := readDocuments(file, data)
+		if err != nil {
+			return nil, err
+		}
+		if len(docs) != a.Counts[section] {
+			return nil, invalidFile(file,
+				fmt.Sprintf("The manifest counts %d documents, found %d", a.Counts[section], len(docs)))
+		}
+		a.docs[section] = docs
+	}
+	return a, nil
+}
+
+// lookup finds the value of a field in a document.
+func lookup(doc bson.D, key string) (interface{}, bool) {
+	for _, e := range doc {
+		if e.Key == key {
+			return e.Value, true
+		}
+	}
+	return nil, false
+}
+
+// values finds the values at a path in a document, going into arrays.
+func values(v interface{}, path []string) []interface{} {
+	switch v := v.(type) {
+	case bson.A:
+		found := []interface{}{}
+		for _, e := range v {
+			found = append(found, values(e, path)...)
+		}
+		return found
+	case bson.D:
+		if len(path) == 0 {
+			break
+		}
+		field, ok := lookup(v, path[0])
+		if !ok {
+			return nil
+		}
+		return values(field, path[1:])
+	}
+	if len(path) > 0 {
+		return nil
+	}
+	return []interface{}{v}
+}
+
+// check checks that every document has an ID no other document in its
+// collection has, and that the documents it references are in the
+// archive: the teams fixtures are between, their seasons, stadiums,
+// officials and players, and so on.
+func (a archive) check() error {
+	details := []customErrors.ValidationErrorDetails{}
+	invalid := func(section string, i int, message string) {
+		details = append(details, customErrors.ValidationErrorDetails{
+			Field:   fmt.Sprintf("%s line %d", sectionFile(section), i+1),
+			Message: message,
+		})
+	}
+	ids := map[string]map[interface{}]bool{}
+	for _, section := range sections {
+		ids[section] = map[interface{}]bool{}
+		for i, doc := range a.docs[section] {
+			id, ok := lookup(doc, "_id")
+			switch {
+			case !ok:
+				invalid(section, i, "The document has no ID")
+			case ids[section][id]:
+				invalid(section, i, fmt.Sprintf("The ID %v is already taken", id))
+			}
+			ids[section][id] = true
+		}
+	}
+	for _, ref := range references {
+		path := strings.Split(ref.field, ".")
+		for i, doc := range a.docs[ref.section] {
+			found := values(doc, path)
+			if len(found) == 0 && ref.required {
+				found = []interface{}{nil}
+			}
+			for _, value := range found {
+				if id, ok := value.(string); !ok || !ids[ref.to][id] {
+					invalid(ref.section, i, fmt.Sprintf("The %s %v is not in the archive", ref.field, value))
+				}
+			}
+		}
+	}
+	if len(details) > 0 {
+		return invalidArchive(details...)
+	}
+	return nil
+}
@@ -0,0 +1,182 @@
+package backup
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"testing"
+	"time"
+
+	"gomoney-mock-epl/database"
+	customErrors "gomoney-mock-epl/errors"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func league() archive {
+	created := time.Date(2021, 1, 4, 9, 0, 0, 0, time.UTC)
+	docs := map[string][]bson.D{
+		database.StadiumsCollection: {
+			{{Key: "_id", Value: "anfield"}, {Key: "name", Value: "Anfield"}},
+		},
+		database.TeamsCollection: {
+			{{Key: "_id", Value: "liverpool"}, {Key: "name", Value: "Liverpool"}, {Key: "stadium", Value: "anfield"}},
+			{{Key: "_id", Value: "man-city"}, {Key: "name", Value: "Manchester City"}},
+		},
+		database.SeasonsCollection: {
+			{{Key: "_id", Value: "2020-21"}, {Key: "teams", Value: bson.A{"liverpool", "man-city"}}},
+		},
+		database.OfficialsCollection: {
+			{{Key: "_id", Value: "michael-oliver"}, {Key: "role", Value: "referee"}},
+		},
+		database.PlayersCollection: {
+			{{Key: "_id", Value: "mohamed-salah"}, {Key: "team", Value: "liverpool"}, {Key: "season", Value: "2020-21"}},
+		},
+		database.TransfersCollection: {
+			{{Key: "_id", Value: "salah-2017"}, {Key: "player", Value: "mohamed-salah"}, {Key: "to", Value: "liverpool"}},
+		},
+		database.FixturesCollection: {
+			{
+				{Key: "_id", Value: primitive.NewObjectID()},
+				{Key: "home_team", Value: "liverpool"},
+				{Key: "away_team", Value: "man-city"},
+				{Key: "match_date", Value: primitive.NewDateTimeFromTime(created.AddDate(0, 1, 0))},
+				{Key: "season", Value: "2020-21"},
+				{Key: "matchweek", Value: int32(23)},
+				{Key: "venue", Value: "anfield"},
+				{Key: "officials", Value: bson.A{
+					bson.D{{Key: "official", Value: "michael-oliver"}, {Key: "role", Value: "referee"}},
+				}},
+				{Key: "performances", Value: bson.A{
+					bson.D{{Key: "player", Value: "mohamed-salah"}, {Key: "goals", Value: int32(1)}},
+				}},
+			},
+		},
+		database.UsersCollection: {
+			{{Key: "_id", Value: "jane"}, {Key: "email", Value: "jane.doe@gomoney.local"}},
+		},
+		database.AdminsCollection: {},
+	}
+	counts := map[string]int{}
+	for section, sectionDocs := range docs {
+		counts[section] = len(sectionDocs)
+	}
+	return archive{
+		Manifest: Manifest{Format: Format, Version: Version, CreatedAt: created, Counts: counts},
+		docs:     docs,
+	}
+}
+
+func validationDetails(t *testing.T, err error) []customErrors.ValidationErrorDetails {
+	validationErr, ok := err.(customErrors.ValidationError)
+	if assert.True(t, ok, "expected a validation error, got %v", err) {
+		assert.Equal(t, "backup/invalid-archive", validationErr.Code)
+	}
+	return validationErr.Details
+}
+
+func TestArchive_roundTrip(t *testing.T) {
+	a := league()
+	buf := bytes.Buffer{}
+	assert.Nil(t, a.write(&buf))
+
+	read, err := read(&buf)
+	assert.Nil(t, err)
+	assert.Equal(t, a.Manifest, read.Manifest)
+	assert.Equal(t, a.docs, read.docs)
+	assert.Nil(t, read.check())
+}
+
+func TestRead_laterVersion(t *testing.T) {
+	a := league()
+	a.Version = Version + 1
+	buf := bytes.Buffer{}
+	assert.Nil(t, a.write(&buf))
+
+	_, err := read(&buf)
+	details := validationDetails(t, err)
+	assert.Equal(t, "manifest.json", details[0].Field)
+}
+
+func TestRead_countsMismatch(t *testing.T) {
+	a := league()
+	a.Counts[database.TeamsCollection] = 3
+	buf := bytes.Buffer{}
+	assert.Nil(t, a.write(&buf))
+
+	_, err := read(&buf)
+	details := validationDetails(t, err)
+	assert.Equal(t, "teams.jsonl", details[0].Field)
+}
+
+func TestRead_missingSection(t *testing.T) {
+	buf := bytes.Buffer{}
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	manifest := `{"format": "mock-epl-backup", "version": 1, "counts": {"teams": 1}}`
+	assert.Nil(t, writeFile(tw, manifestFile, []byte(manifest), time.Now()))
+	assert.Nil(t, writeFile(tw, "teams.jsonl", []byte(`{"_id": "liverpool"}`+"\n"), time.Now()))
+	assert.Nil(t, tw.Close())
+	assert.Nil(t, gz.Close())
+
+	_, err := read(&buf)
+	details := validationDetails(t, err)
+	assert.Equal(t, "stadiums.jsonl", details[0].Field)
+	assert.Equal(t, "The file is missing", details[0].Message)
+}
+
+func TestRead_notAnArchive(t *testing.T) {
+	_, err := read(bytes.NewBufferString("home_team,away_team\n"))
+	validationDetails(t, err)
+
+	buf := bytes.Buffer{}
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	assert.Nil(t, writeFile(tw, "teams.jsonl", []byte{}, time.Now()))
+	assert.Nil(t, tw.Close())
+	assert.Nil(t, gz.Close())
+	_, err = read(&buf)
+	details := validationDetails(t, err)
+	assert.Equal(t, "The manifest is missing", details[0].Message)
+}
+
+func TestArchive_check(t *testing.T) {
+	a := league()
+	a.docs[database.TeamsCollection] = append(a.docs[database.TeamsCollection],
+		bson.D{{Key: "_id", Value: "liverpool"}, {Key: "name", Value: "Liverpool FC"}})
+	a.docs[database.FixturesCollection] = append(a.docs[database.FixturesCollection], bson.D{
+		{Key: "_id", Value: primitive.NewObjectID()},
+		{Key: "home_team", Value: "everton"},
+		{Key: "away_team", Value: "liverpool"},
+	})
+
+	details := validationDetails(t, a.check())
+	assert.Equal(t, []customErrors.ValidationErrorDetails{
+		{Field: "teams.jsonl line 3", Message: "The ID liverpool is already taken"},
+		{Field: "fixtures.jsonl line 2", Message: "The home_team everton is not in the archive"},
+	}, details)
+}
+
+func TestArchive_checkReferences(t *testing.T) {
+	a := league()
+	a.docs[database.StadiumsCollection] = []bson.D{}
+	a.docs[database.OfficialsCollection] = []bson.D{}
+	a.docs[database.TransfersCollection] = append(a.docs[database.TransfersCollection],
+		bson.D{{Key: "_id", Value: "unknown"}, {Key: "from", Value: "liverpool"}})
+
+	details := validationDetails(t, a.check())
+	assert.Equal(t, []customErrors.ValidationErrorDetails{
+		{Field: "teams.jsonl line 1", Message: "The stadium anfield is not in the archive"},
+		{Field: "transfers.jsonl line 2", Message: "The player <nil> is not in the archive"},
+		{Field: "fixtures.jsonl line 1", Message: "The venue anfield is not in the archive"},
+		{Field: "fixtures.jsonl line 1", Message: "The officials.official michael-oliver is not in the archive"},
+	}, details)
+}
+
+func TestWithoutPasswords(t *testing.T) {
+	docs := []bson.D{{{Key: "_id", Value: "jane"}, {Key: "password_hash", Value: "$2a$10$"}}}
+	assert.Equal(t, []bson.D{{{Key: "_id", Value: "jane"}}}, withoutPasswords(docs))
+	assert.Len(t, docs[0], 2)
+}
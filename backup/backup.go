@@ -0,0 +1,170 @@
+package backup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gomoney-mock-epl/clock"
+	"gomoney-mock-epl/database"
+	"io"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// passwordField holds the password hashes of users and admins.
+const passwordField = "password_hash"
+
+var ErrNotEmpty = errors.New("archives can only be restored into a database without a league or users")
+
+// Options are what goes into an archive.
+type Options struct {
+	// Passwords exports the password hashes of users and admins.
+	// They're left out unless asked for.
+	Passwords bool
+}
+
+// Restored reports on a restored archive. Admins that are already
+// in the database are skipped.
+type Restored struct {
+	Manifest
+	SkippedAdmins int `json:"skipped_admins"`
+}
+
+// Service exports the league in the database, its stadiums, teams,
+// seasons, officials, players, transfers and fixtures, and its users and
+// admins to archives, and restores them.
+type Service struct {
+	DB    *mongo.Database
+	Clock clock.Clock
+}
+
+// withoutPasswords copies the documents, leaving out password hashes.
+func withoutPasswords(docs []bson.D) []bson.D {
+	stripped := make([]bson.D, 0, len(docs))
+	for _, doc := range docs {
+		kept := make(bson.D, 0, len(doc))
+		for _, e := range doc {
+			if e.Key != passwordField {
+				kept = append(kept, e)
+			}
+		}
+		stripped = append(stripped, kept)
+	}
+	return stripped
+}
+
+// Export writes an archive of the database to w.
+func (s Service) Export(ctx context.Context, w io.Writer, opts Options) (*Manifest, error) {
+	a := archive{
+		Manifest: Manifest{
+			Format:    Format,
+			Version:   Version,
+			CreatedAt: clock.Now(s.Clock).UTC(),
+			Passwords: opts.Passwords,
+			Counts:    map[string]int{},
+		},
+		docs: map[string][]bson.D{},
+	}
+	for _, section := range sections {
+		cursor, err := s.DB.Collection(section).Find(ctx, bson.D{},
+			options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}))
+		if err != nil {
+			return nil, err
+		}
+		docs := []bson.D{}
+		if err := cursor.All(ctx, &docs); err != nil {
+			return nil, err
+		}
+		if !opts.Passwords && (section == database.UsersCollection || section == database.AdminsCollection) {
+			docs = withoutPasswords(docs)
+		}
+		a.docs[section] = docs
+		a.Counts[section] = len(docs)
+	}
+	if err := a.write(w); err != nil {
+		return nil, err
+	}
+	return &a.Manifest, nil
+}
+
+// existingAdmins leaves out the admins in the database already, going
+// by ID or email, and returns the others.
+func (s Service) existingAdmins(ctx context.Context, admins []bson.D) ([]bson.D, error) {
+	cursor, err := s.DB.Collection(database.AdminsCollection).Find(ctx, bson.D{})
+	if err != nil {
+		return nil, err
+	}
+	existing := []bson.M{}
+	if err := cursor.All(ctx, &existing); err != nil {
+		return nil, err
+	}
+	taken := map[interface{}]bool{}
+	for _, admin := range existing {
+		taken[admin["_id"]] = true
+		taken[admin["email"]] = true
+	}
+	kept := []bson.D{}
+	for _, admin := range admins {
+		id, _ := lookup(admin, "_id")
+		email, _ := lookup(admin, "email")
+		if !taken[id] && !taken[email] {
+			kept = append(kept, admin)
+		}
+	}
+	return kept, nil
+}
+
+// Restore reads an archive from r and inserts its documents with the
+// IDs they had. The database must only have admins, or it fails with
+// ErrNotEmpty. Admins already in the database are kept,
+// so the admin restoring the archive stays signed in, and the admins in
+// the archive with the same ID or email are skipped.
+//
+// Collections are restored one after another, not in a transaction. If
+// an insert fails, the documents before it stay behind and the database
+// has to be emptied before trying again.
+func (s Service) Restore(ctx context.Context, r io.Reader) (*Restored, error) {
+	a, err := read(r)
+	if err != nil {
+		return nil, err
+	}
+	if err := a.check(); err != nil {
+		return nil, err
+	}
+	for _, section := range sections {
+		if section == database.AdminsCollection {
+			continue
+		}
+		count, err := s.DB.Collection(section).CountDocuments(ctx, bson.D{})
+		if err != nil {
+			return nil, err
+		}
+		if count > 0 {
+			return nil, fmt.Errorf("%w: found %d %s", ErrNotEmpty, count, section)
+		}
+	}
+	admins, err := s.existingAdmins(ctx, a.docs[database.AdminsCollection])
+	if err != nil {
+		return nil, err
+	}
+	restored := &Restored{
+		Manifest:      a.Manifest,
+		SkippedAdmins: len(a.docs[database.AdminsCollection]) - len(admins),
+	}
+	a.docs[database.AdminsCollection] = admins
+	for _, section := range sections {
+		if len(a.docs[section]) == 0 {
+			continue
+		}
+		docs := make([]interface{}, 0, len(a.docs[section]))
+		for _, doc := range a.docs[section] {
+			docs = append(docs, doc)
+		}
+		if _, err := s.DB.Collection(section).InsertMany(ctx, docs); err != nil {
+			return nil, err
+		}
+	}
+	return restored, nil
+}
@@ -0,0 +1,151 @@
+// Package calendar writes fixtures as RFC 5545 iCalendar feeds, for
+// supporters to subscribe to from their calendar apps.
+package calendar
+
+import (
+	"bufio"
+	"fmt"
+	"gomoney-mock-epl/fixtures"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// ContentType is the media type of the feeds.
+	ContentType = "text/calendar; charset=utf-8"
+	prodID      = "-//gomoney//Mock EPL//EN"
+	// uidDomain makes the fixture IDs globally unique, as UIDs must be.
+	uidDomain = "mock-epl.gomoney"
+	// matchLength is how long a fixture shows in calendars for: the
+	// match, the break and stoppage time.
+	matchLength = 2 * time.Hour
+	// lineLength is the most octets in a line before it's folded.
+	lineLength = 75
+	dateTime   = "20060102T150405Z"
+)
+
+// Calendar is a feed of fixtures.
+type Calendar struct {
+	Name     string
+	Fixtures []fixtures.Fixture
+	// Venues are the names of the stadiums fixtures were moved to, by ID.
+	// Other fixtures are played at the home team's stadium.
+	Venues map[string]string
+}
+
+// escape escapes the characters that are special in text values.
+var escape = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace
+
+// fold breaks a content line into lines of at most lineLength octets,
+// continuing each with a space. Characters are never split.
+func fold(line string) string {
+	if len(line) <= lineLength {
+		return line
+	}
+	folded := strings.Builder{}
+	limit := lineLength
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		folded.WriteString(line[:cut])
+		folded.WriteString("\r\n ")
+		line = line[cut:]
+		// The space starting a continuation line counts towards it.
+		limit = lineLength - 1
+	}
+	folded.WriteString(line)
+	return folded.String()
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(dateTime)
+}
+
+// sequence is the revision of a fixture: the number of writes to it
+// since it was created. It goes up with every change whatever the
+// clock says, so calendar apps pick up reschedules and renamed teams.
+func sequence(fixture fixtures.Fixture) int {
+	if fixture.Version < 1 {
+		return 0
+	}
+	return fixture.Version - 1
+}
+
+func summary(fixture fixtures.Fixture) string {
+	if fixture.Result != nil {
+		return fmt.Sprintf("%s %d-%d %s", fixture.HomeTeam.Name, fixture.Result.FullTime.Home,
+			fixture.Result.FullTime.Away, fixture.AwayTeam.Name)
+	}
+	return fmt.Sprintf("%s v %s", fixture.HomeTeam.Name, fixture.AwayTeam.Name)
+}
+
+// status is how sure the fixture is to take place. Postponed and
+// abandoned fixtures are waiting to be rescheduled.
+func status(fixture fixtures.Fixture) string {
+	switch fixture.Status {
+	case fixtures.Cancelled:
+		return "CANCELLED"
+	case fixtures.Postponed, fixtures.Abandoned:
+		return "TENTATIVE"
+	}
+	return "CONFIRMED"
+}
+
+func (c Calendar) location(fixture fixtures.Fixture) string {
+	if venue, ok := c.Venues[fixture.Venue]; ok && fixture.Venue != "" {
+		return venue
+	}
+	return fixture.HomeTeam.HomeStadium
+}
+
+func (c Calendar) event(fixture fixtures.Fixture) []string {
+	lines := []string{
+		"BEGIN:VEVENT",
+		"UID:" + fixture.ID.Hex() + "@" + uidDomain,
+		"DTSTAMP:" + formatTime(fixture.UpdatedAt),
+		"CREATED:" + formatTime(fixture.CreatedAt),
+		"LAST-MODIFIED:" + formatTime(fixture.UpdatedAt),
+		fmt.Sprintf("SEQUENCE:%d", sequence(fixture)),
+		"DTSTART:" + formatTime(fixture.MatchDate),
+		"DTEND:" + formatTime(fixture.MatchDate.Add(matchLength)),
+		"SUMMARY:" + escape(summary(fixture)),
+	}
+	if location := c.location(fixture); location != "" {
+		lines = append(lines, "LOCATION:"+escape(location))
+	}
+	if fixture.Matchweek > 0 {
+		lines = append(lines, fmt.Sprintf("DESCRIPTION:Matchweek %d", fixture.Matchweek))
+	}
+	return append(lines, "STATUS:"+status(fixture), "END:VEVENT")
+}
+
+// Encode writes the calendar, with an event for each fixture.
+func (c Calendar) Encode(w io.Writer) error {
+	lines := []string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:" + prodID,
+		"CALSCALE:GREGORIAN",
+		"METHOD:PUBLISH",
+		"X-WR-CALNAME:" + escape(c.Name),
+	}
+	for _, fixture := range c.Fixtures {
+		if fixture.HomeTeam == nil || fixture.AwayTeam == nil {
+			continue
+		}
+		lines = append(lines, c.event(fixture)...)
+	}
+	lines = append(lines, "END:VCALENDAR")
+
+	buf := bufio.NewWriter(w)
+	for _, line := range lines {
+		if _, err := buf.WriteString(fold(line) + "\r\n"); err != nil {
+			return err
+		}
+	}
+	return buf.Flush()
+}
@@ -0,0 +1,93 @@
+package calendar
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"gomoney-mock-epl/fixtures"
+	"gomoney-mock-epl/teams"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func fixture() fixtures.Fixture {
+	created := time.Date(2021, 1, 4, 9, 0, 0, 0, time.UTC)
+	return fixtures.Fixture{
+		ID:        primitive.NewObjectID(),
+		HomeTeam:  &teams.Team{Name: "Liverpool", HomeStadium: "Anfield"},
+		AwayTeam:  &teams.Team{Name: "Manchester City"},
+		MatchDate: time.Date(2021, 2, 7, 16, 30, 0, 0, time.FixedZone("GMT+1", 3600)),
+		Matchweek: 23,
+		Status:    fixtures.Scheduled,
+		Version:   1,
+		CreatedAt: created,
+		UpdatedAt: created,
+	}
+}
+
+func encode(t *testing.T, c Calendar) []string {
+	feed := bytes.Buffer{}
+	assert.Nil(t, c.Encode(&feed))
+	assert.True(t, strings.HasSuffix(feed.String(), "\r\n"))
+	return strings.Split(strings.TrimSuffix(feed.String(), "\r\n"), "\r\n")
+}
+
+func TestCalendar_Encode(t *testing.T) {
+	f := fixture()
+	lines := encode(t, Calendar{Name: "Liverpool fixtures", Fixtures: []fixtures.Fixture{f}})
+	assert.Equal(t, "BEGIN:VCALENDAR", lines[0])
+	assert.Equal(t, "END:VCALENDAR", lines[len(lines)-1])
+	assert.Contains(t, lines, "UID:"+f.ID.Hex()+"@mock-epl.gomoney")
+	assert.Contains(t, lines, "DTSTART:20210207T153000Z")
+	assert.Contains(t, lines, "DTEND:20210207T173000Z")
+	assert.Contains(t, lines, "SUMMARY:Liverpool v Manchester City")
+	assert.Contains(t, lines, "LOCATION:Anfield")
+	assert.Contains(t, lines, "DESCRIPTION:Matchweek 23")
+	assert.Contains(t, lines, "STATUS:CONFIRMED")
+	assert.Contains(t, lines, "SEQUENCE:0")
+}
+
+func TestCalendar_Encode_updates(t *testing.T) {
+	f := fixture()
+	f.UpdatedAt = f.CreatedAt.Add(90 * time.Second)
+	f.Version = 3
+	f.Status = fixtures.Postponed
+	f.Venue = "wembley"
+	lines := encode(t, Calendar{Fixtures: []fixtures.Fixture{f}, Venues: map[string]string{"wembley": "Wembley Stadium"}})
+	assert.Contains(t, lines, "SEQUENCE:2")
+	assert.Contains(t, lines, "LAST-MODIFIED:20210104T090130Z")
+	assert.Contains(t, lines, "STATUS:TENTATIVE")
+	assert.Contains(t, lines, "LOCATION:Wembley Stadium")
+
+	f.Result = &fixtures.Result{FullTime: fixtures.Score{Home: 1, Away: 4}}
+	lines = encode(t, Calendar{Fixtures: []fixtures.Fixture{f}})
+	assert.Contains(t, lines, "SUMMARY:Liverpool 1-4 Manchester City")
+}
+
+func TestEscape(t *testing.T) {
+	assert.Equal(t, `Brighton\, Hove\; Albion \\ FC\nEast Sussex`, escape("Brighton, Hove; Albion \\ FC\nEast Sussex"))
+}
+
+func TestFold(t *testing.T) {
+	line := "SUMMARY:" + strings.Repeat("é", 60)
+	folded := strings.Split(fold(line), "\r\n")
+	assert.Len(t, folded, 2)
+	for _, l := range folded {
+		assert.LessOrEqual(t, len(l), lineLength)
+	}
+	assert.True(t, strings.HasPrefix(folded[1], " "))
+	assert.Equal(t, line, folded[0]+strings.TrimPrefix(folded[1], " "))
+	assert.Equal(t, "SUMMARY:short", fold("SUMMARY:short"))
+}
+
+func TestSequence(t *testing.T) {
+	f := fixture()
+	f.Version = 2
+	f.UpdatedAt = f.CreatedAt.Add(-time.Hour)
+	assert.Equal(t, 1, sequence(f), "moving the clock back doesn't undo writes")
+	f.Version = 0
+	assert.Equal(t, 0, sequence(f))
+}
@@ -0,0 +1,105 @@
+// Package clock provides the server's notion of the current time, so
+// that it can be moved around in tests and non-production deployments.
+package clock
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// Clock tells the time.
+type Clock interface {
+	Now() time.Time
+}
+
+// Now returns the time on c, or the real time if c is nil. It lets
+// types with an optional clock use the real time by default.
+func Now(c Clock) time.Time {
+	if c == nil {
+		return time.Now()
+	}
+	return c.Now()
+}
+
+// Real is the system clock.
+type Real struct{}
+
+func (Real) Now() time.Time {
+	return time.Now()
+}
+
+var ErrNegativeRate = errors.New("the clock can't run backwards")
+
+// Virtual is a clock that can be set to any time, frozen, or run faster
+// than real time. A new virtual clock shows the real time.
+type Virtual struct {
+	mu sync.RWMutex
+	// The virtual clock showed base at the real time anchor, and has
+	// moved rate times as fast as real time since.
+	base   time.Time
+	anchor time.Time
+	rate   float64
+	real   func() time.Time
+}
+
+func NewVirtual() *Virtual {
+	return newVirtual(time.Now)
+}
+
+func newVirtual(real func() time.Time) *Virtual {
+	now := real()
+	return &Virtual{base: now, anchor: now, rate: 1, real: real}
+}
+
+// State describes a virtual clock.
+type State struct {
+	Now    time.Time `json:"now"`
+	Rate   float64   `json:"rate"`
+	Frozen bool      `json:"frozen"`
+}
+
+func (v *Virtual) now(real time.Time) time.Time {
+	return v.base.Add(time.Duration(float64(real.Sub(v.anchor)) * v.rate))
+}
+
+func (v *Virtual) Now() time.Time {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	return v.now(v.real())
+}
+
+// State returns the time on the clock and how fast it runs.
+func (v *Virtual) State() State {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	return State{Now: v.now(v.real()), Rate: v.rate, Frozen: v.rate == 0}
+}
+
+// Set moves the clock to t. The clock keeps running at the same rate.
+func (v *Virtual) Set(t time.Time) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.base, v.anchor = t, v.real()
+}
+
+// SetRate changes how many times as fast as real time the clock runs,
+// from now on. A rate of 0 freezes the clock.
+func (v *Virtual) SetRate(rate float64) error {
+	if rate < 0 {
+		return ErrNegativeRate
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	real := v.real()
+	v.base, v.anchor, v.rate = v.now(real), real, rate
+	return nil
+}
+
+// Reset puts the clock back to the real time, running at real speed.
+func (v *Virtual) Reset() {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	now := v.real()
+	v.base, v.anchor, v.rate = now, now, 1
+}
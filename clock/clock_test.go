@@ -0,0 +1,78 @@
+package clock
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeReal is a real clock that only moves when told to.
+type fakeReal struct {
+	now time.Time
+}
+
+func (f *fakeReal) Now() time.Time {
+	return f.now
+}
+
+func (f *fakeReal) advance(d time.Duration) {
+	f.now = f.now.Add(d)
+}
+
+func TestVirtual(t *testing.T) {
+	start := time.Date(2021, time.August, 14, 12, 0, 0, 0, time.UTC)
+
+	t.Run("Shows the real time until it is changed", func(t *testing.T) {
+		real := &fakeReal{now: start}
+		v := newVirtual(real.Now)
+		real.advance(time.Minute)
+		assert.Equal(t, real.now, v.Now())
+	})
+
+	t.Run("Can be set to any time", func(t *testing.T) {
+		real := &fakeReal{now: start}
+		v := newVirtual(real.Now)
+		matchday := start.Add(72 * time.Hour)
+		v.Set(matchday)
+		real.advance(time.Minute)
+		assert.Equal(t, matchday.Add(time.Minute), v.Now())
+	})
+
+	t.Run("Can be frozen", func(t *testing.T) {
+		real := &fakeReal{now: start}
+		v := newVirtual(real.Now)
+		assert.NoError(t, v.SetRate(0))
+		real.advance(time.Hour)
+		assert.Equal(t, start, v.Now())
+		assert.True(t, v.State().Frozen)
+	})
+
+	t.Run("Can run faster than real time", func(t *testing.T) {
+		real := &fakeReal{now: start}
+		v := newVirtual(real.Now)
+		real.advance(time.Minute)
+		assert.NoError(t, v.SetRate(60))
+		real.advance(time.Minute)
+		assert.Equal(t, start.Add(61*time.Minute), v.Now())
+		assert.Equal(t, ErrNegativeRate, v.SetRate(-1))
+	})
+
+	t.Run("Can be reset to the real time", func(t *testing.T) {
+		real := &fakeReal{now: start}
+		v := newVirtual(real.Now)
+		v.Set(start.Add(-time.Hour))
+		v.SetRate(10)
+		v.Reset()
+		real.advance(time.Minute)
+		assert.Equal(t, State{Now: real.now, Rate: 1}, v.State())
+	})
+}
+
+func TestNow(t *testing.T) {
+	assert.WithinDuration(t, time.Now(), Now(nil), time.Second)
+	v := NewVirtual()
+	v.SetRate(0)
+	v.Set(time.Unix(0, 0))
+	assert.Equal(t, time.Unix(0, 0), Now(v))
+}
@@ -6,14 +6,28 @@ import (
 	"strings"
 )
 
+// Production is the deployment environment of the live service. Features
+// meant for testing, like moving the server's clock, are disabled there.
+const Production = "production"
+
 type Config struct {
 	HttpBindPort uint
 	MongoURL     string
+	// Environment is where the server is deployed, like "testing". It is
+	// production unless set otherwise.
+	Environment string
+	// The simulator plays out fixtures when they kick off. The same seed
+	// always plays a fixture out the same way. Speed is how many times
+	// faster than real time matches are played.
+	SimulatorEnabled bool
+	SimulatorSeed    int64
+	SimulatorSpeed   float64
 }
 
 func LoadConfig() (*Config, error) {
 	port := strings.TrimSpace(os.Getenv("PORT"))
 	mongoURL := strings.TrimSpace(os.Getenv("MONGO_URL"))
+	environment := strings.TrimSpace(os.Getenv("DEPLOY_ENV"))
 
 	var httpPort uint = 8080
 	if port != "" {
@@ -28,8 +42,46 @@ func LoadConfig() (*Config, error) {
 		mongoURL = "mongodb://localhost:27017/hf?ssl=false"
 	}
 
+	if environment == "" {
+		environment = Production
+	}
+
+	simulatorEnabled := false
+	if enabled := strings.TrimSpace(os.Getenv("SIMULATOR_ENABLED")); enabled != "" {
+		e, err := strconv.ParseBool(enabled)
+		if err != nil {
+			return nil, err
+		}
+		simulatorEnabled = e
+	}
+	var simulatorSeed int64 = 1
+	if seed := strings.TrimSpace(os.Getenv("SIMULATOR_SEED")); seed != "" {
+		s, err := strconv.ParseInt(seed, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		simulatorSeed = s
+	}
+	simulatorSpeed := 1.0
+	if speed := strings.TrimSpace(os.Getenv("SIMULATOR_SPEED")); speed != "" {
+		s, err := strconv.ParseFloat(speed, 64)
+		if err != nil {
+			return nil, err
+		}
+		simulatorSpeed = s
+	}
+
 	return &Config{
-		HttpBindPort: httpPort,
-		MongoURL:     mongoURL,
+		HttpBindPort:     httpPort,
+		MongoURL:         mongoURL,
+		Environment:      environment,
+		SimulatorEnabled: simulatorEnabled,
+		SimulatorSeed:    simulatorSeed,
+		SimulatorSpeed:   simulatorSpeed,
 	}, nil
 }
+
+// IsProduction reports whether the server is deployed to production.
+func (c Config) IsProduction() bool {
+	return c.Environment == Production
+}
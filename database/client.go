@@ -11,11 +11,16 @@ import (
 )
 
 const (
-	MockEPLDatabase    = "mock_epl"
-	AdminsCollection   = "admins"
-	UsersCollection    = "users"
-	TeamsCollection    = "teams"
-	FixturesCollection = "fixtures"
+	MockEPLDatabase     = "mock_epl"
+	AdminsCollection    = "admins"
+	UsersCollection     = "users"
+	TeamsCollection     = "teams"
+	FixturesCollection  = "fixtures"
+	SeasonsCollection   = "seasons"
+	PlayersCollection   = "players"
+	TransfersCollection = "transfers"
+	StadiumsCollection  = "stadiums"
+	OfficialsCollection = "officials"
 )
 
 func ConnectToDB(mongoURL string) (*mongo.Client, error) {
@@ -78,16 +83,131 @@ var teamIndexModel = []mongo.IndexModel{
 			},
 		},
 	},
+	// Fixtures near a point are found through their home team's stadium.
+	{
+		Keys: bson.D{{Key: "stadium", Value: 1}},
+	},
 }
 
 var fixturesSearch = "fixtures_search"
-var fixturesIndexModel = mongo.IndexModel{
-	Keys: bson.D{
-		{Key: "home_team_name", Value: "text"},
-		{Key: "away_team_name", Value: "text"}},
-	Options: &options.IndexOptions{
-		DefaultLanguage: &defaultSearchLanguage,
-		Name:            &fixturesSearch,
+var fixturesIndexModel = []mongo.IndexModel{
+	{
+		Keys: bson.D{
+			{Key: "home_team_name", Value: "text"},
+			{Key: "away_team_name", Value: "text"}},
+		Options: &options.IndexOptions{
+			DefaultLanguage: &defaultSearchLanguage,
+			Name:            &fixturesSearch,
+		},
+	},
+	{
+		Keys: bson.D{{Key: "status", Value: 1}, {Key: "match_date", Value: 1}},
+	},
+	{
+		Keys: bson.D{{Key: "season", Value: 1}, {Key: "matchweek", Value: 1}},
+	},
+	// A team's fixtures are found by looking up each side.
+	{
+		Keys: bson.D{{Key: "home_team", Value: 1}, {Key: "match_date", Value: 1}},
+	},
+	{
+		Keys: bson.D{{Key: "away_team", Value: 1}, {Key: "match_date", Value: 1}},
+	},
+	// Fixtures moved to a stadium are found by their venue.
+	{
+		Keys: bson.D{{Key: "venue", Value: 1}, {Key: "match_date", Value: 1}},
+	},
+	// Player stats add up the performances in a player's fixtures.
+	{
+		Keys: bson.D{{Key: "performances.player", Value: 1}, {Key: "match_date", Value: 1}},
+	},
+	// Officials are checked for clashes, and their history listed,
+	// through the fixtures they are assigned to.
+	{
+		Keys: bson.D{{Key: "officials.official", Value: 1}, {Key: "match_date", Value: 1}},
+	},
+	// Fixture listings are paged through in these orders.
+	{
+		Keys: bson.D{{Key: "match_date", Value: 1}, {Key: "_id", Value: 1}},
+	},
+	{
+		Keys: bson.D{{Key: "created_at", Value: 1}, {Key: "_id", Value: 1}},
+	},
+}
+
+var stadiumIndexModel = []mongo.IndexModel{
+	// Stadiums are found by distance from a point.
+	{
+		Keys: bson.D{{Key: "location", Value: "2dsphere"}},
+	},
+	{
+		Keys: bson.D{{Key: "name", Value: 1}},
+	},
+}
+
+var officialIndexModel = mongo.IndexModel{
+	Keys: bson.D{{Key: "role", Value: 1}, {Key: "name", Value: 1}},
+}
+
+var seasonIndexModel = mongo.IndexModel{
+	Keys:    bson.D{{Key: "name", Value: 1}},
+	Options: &options.IndexOptions{Unique: &unique},
+}
+
+var uniqueShirtNumbers = "unique_shirt_numbers"
+var playersSearch = "players_search"
+var playerIndexModel = []mongo.IndexModel{
+	// Shirt numbers are unique within a team's squad for a season.
+	// Free agents and players without numbers are left out.
+	{
+		Keys: bson.D{
+			{Key: "team", Value: 1},
+			{Key: "season", Value: 1},
+			{Key: "shirt_number", Value: 1}},
+		Options: &options.IndexOptions{
+			Name:   &uniqueShirtNumbers,
+			Unique: &unique,
+			PartialFilterExpression: bson.D{
+				{Key: "team", Value: bson.D{{Key: "$exists", Value: true}}},
+				{Key: "shirt_number", Value: bson.D{{Key: "$exists", Value: true}}},
+			},
+		},
+	},
+	// The unique index is partial, so squads need one of their own.
+	{
+		Keys: bson.D{{Key: "team", Value: 1}, {Key: "season", Value: 1}},
+	},
+	{
+		Keys: bson.D{
+			{Key: "name", Value: "text"},
+			{Key: "nationality", Value: "text"}},
+		Options: &options.IndexOptions{
+			DefaultLanguage: &defaultSearchLanguage,
+			Name:            &playersSearch,
+			Weights: bson.D{
+				{Key: "name", Value: 4},
+				{Key: "nationality", Value: 1},
+			},
+		},
+	},
+}
+
+var transferIndexModel = []mongo.IndexModel{
+	{
+		Keys: bson.D{{Key: "player", Value: 1}, {Key: "date", Value: 1}},
+	},
+	{
+		Keys: bson.D{{Key: "from", Value: 1}, {Key: "window", Value: 1}},
+	},
+	{
+		Keys: bson.D{{Key: "to", Value: 1}, {Key: "window", Value: 1}},
+	},
+	// Transfers are settled as they take effect.
+	{
+		Keys: bson.D{{Key: "applied", Value: 1}, {Key: "date", Value: 1}},
+	},
+	{
+		Keys: bson.D{{Key: "loan", Value: 1}, {Key: "loan_end", Value: 1}},
 	},
 }
 
@@ -110,7 +230,37 @@ func CreateIndexes(db *mongo.Database) error {
 	}
 	fixturesIndexes := db.Collection(FixturesCollection).Indexes()
 	fixturesIndexes.DropAll(ctx)
-	_, err = fixturesIndexes.CreateOne(ctx, fixturesIndexModel)
+	_, err = fixturesIndexes.CreateMany(ctx, fixturesIndexModel)
+	if err != nil {
+		return err
+	}
+	seasonIndexes := db.Collection(SeasonsCollection).Indexes()
+	seasonIndexes.DropAll(ctx)
+	_, err = seasonIndexes.CreateOne(ctx, seasonIndexModel)
+	if err != nil {
+		return err
+	}
+	playerIndexes := db.Collection(PlayersCollection).Indexes()
+	playerIndexes.DropAll(ctx)
+	_, err = playerIndexes.CreateMany(ctx, playerIndexModel)
+	if err != nil {
+		return err
+	}
+	transferIndexes := db.Collection(TransfersCollection).Indexes()
+	transferIndexes.DropAll(ctx)
+	_, err = transferIndexes.CreateMany(ctx, transferIndexModel)
+	if err != nil {
+		return err
+	}
+	stadiumIndexes := db.Collection(StadiumsCollection).Indexes()
+	stadiumIndexes.DropAll(ctx)
+	_, err = stadiumIndexes.CreateMany(ctx, stadiumIndexModel)
+	if err != nil {
+		return err
+	}
+	officialIndexes := db.Collection(OfficialsCollection).Indexes()
+	officialIndexes.DropAll(ctx)
+	_, err = officialIndexes.CreateOne(ctx, officialIndexModel)
 	if err != nil {
 		return err
 	}
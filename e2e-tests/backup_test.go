@@ -0,0 +1,140 @@
+package tests
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"gomoney-mock-epl/backup"
+	"gomoney-mock-epl/fixtures"
+	"gomoney-mock-epl/stadiums"
+	"gomoney-mock-epl/users"
+	"gomoney-mock-epl/web"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func exportBackup(query, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/admin/export"+query, nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec := httptest.NewRecorder()
+	testApp.app.ServeHTTP(rec, req)
+	return rec
+}
+
+func restoreBackup(archive []byte, token string) (*http.Response, web.DataDto) {
+	req := httptest.NewRequest(http.MethodPost, "/admin/import", bytes.NewReader(archive))
+	req.Header.Set("Content-Type", backup.ContentType)
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec := httptest.NewRecorder()
+	testApp.app.ServeHTTP(rec, req)
+	dto := web.DataDto{}
+	readJsonResponse(rec.Result().Body, &dto)
+	return rec.Result(), dto
+}
+
+// archiveFile reads a file out of an archive.
+func archiveFile(t *testing.T, archive []byte, name string) string {
+	gz, err := gzip.NewReader(bytes.NewReader(archive))
+	assert.NoError(t, err)
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		assert.NoError(t, err)
+		if header.Name == name {
+			data, _ := ioutil.ReadAll(tr)
+			return string(data)
+		}
+	}
+	return ""
+}
+
+// clearLeague removes everything archives hold but users and admins.
+func clearLeague() {
+	clearStadiums()
+	clearTeamsDB()
+	clearSeasons()
+	clearOfficials()
+	clearPlayers()
+	clearTransfers()
+	clearFixtures()
+}
+
+func Test_backups(t *testing.T) {
+	clearLeague()
+
+	ctx := context.Background()
+	lvpl, _ := testApp.app.TeamsDB.Create(ctx, liverpool.ToTeam(""))
+	city, _ := testApp.app.TeamsDB.Create(ctx, manCity.ToTeam(""))
+	wembley, _ := testApp.app.StadiumsDB.Create(ctx, stadiums.StadiumRequest{Name: "Wembley Stadium", City: "London"})
+	fixture, err := testApp.app.FixturesDB.Create(ctx, fixtures.CreateFixtureRequest{
+		HomeTeam:  lvpl.ID,
+		AwayTeam:  city.ID,
+		MatchDate: time.Date(2030, 8, 14, 15, 0, 0, 0, time.UTC),
+		Venue:     &wembley.ID,
+	})
+	assert.NoError(t, err)
+
+	t.Run("only admins can export and restore", func(t *testing.T) {
+		assert.Equal(t, http.StatusForbidden, exportBackup("", userToken).Code)
+		result, _ := restoreBackup(nil, userToken)
+		assert.Equal(t, http.StatusForbidden, result.StatusCode)
+	})
+
+	t.Run("password hashes are only exported when asked for", func(t *testing.T) {
+		rec := exportBackup("", adminToken)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, backup.ContentType, rec.Header().Get("Content-Type"))
+		assert.Contains(t, rec.Header().Get("Content-Disposition"), ".tar.gz")
+		assert.Contains(t, archiveFile(t, rec.Body.Bytes(), "manifest.json"), `"version": 1`)
+		assert.Contains(t, archiveFile(t, rec.Body.Bytes(), "users.jsonl"), testUserEmail)
+		assert.NotContains(t, archiveFile(t, rec.Body.Bytes(), "users.jsonl"), "password_hash")
+
+		rec = exportBackup("?passwords=true", adminToken)
+		assert.Contains(t, archiveFile(t, rec.Body.Bytes(), "admins.jsonl"), "password_hash")
+		assert.Equal(t, http.StatusBadRequest, exportBackup("?passwords=maybe", adminToken).Code)
+	})
+
+	t.Run("archives are restored into empty databases with their IDs", func(t *testing.T) {
+		archive := exportBackup("?passwords=true", adminToken).Body.Bytes()
+		result, _ := restoreBackup(archive, adminToken)
+		assert.Equal(t, http.StatusConflict, result.StatusCode)
+
+		clearLeague()
+		testApp.app.UsersDB.DeleteMany(ctx, bson.D{})
+		result, body := restoreBackup(archive, adminToken)
+		assert.Equal(t, http.StatusCreated, result.StatusCode)
+		assert.Equal(t, "Restore", body.Type)
+		assert.EqualValues(t, 1, body.Data.(map[string]interface{})["skipped_admins"])
+
+		restored, err := testApp.app.FixturesDB.ByID(ctx, fixture.ID)
+		assert.NoError(t, err)
+		if assert.NotNil(t, restored) {
+			assert.Equal(t, lvpl.ID, restored.HomeTeam.ID)
+			assert.Equal(t, wembley.ID, restored.Venue)
+			assert.True(t, fixture.MatchDate.Equal(restored.MatchDate))
+		}
+		token, err := users.LoginAsUser(ctx, testApp.app.UsersDB, users.LoginDto{Email: testUserEmail, Password: testPassword})
+		assert.NoError(t, err)
+		assert.NotNil(t, token)
+		stadium, err := testApp.app.StadiumsDB.ByID(ctx, wembley.ID)
+		assert.NoError(t, err)
+		assert.NotNil(t, stadium)
+	})
+
+	t.Run("archives that can't be read are rejected", func(t *testing.T) {
+		clearLeague()
+		result, _ := restoreBackup([]byte("not an archive"), adminToken)
+		assert.Equal(t, http.StatusUnprocessableEntity, result.StatusCode)
+	})
+}
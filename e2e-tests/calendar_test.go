@@ -0,0 +1,67 @@
+package tests
+
+import (
+	"context"
+	"gomoney-mock-epl/fixtures"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func getCalendar(path string) (*http.Response, string) {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	rec := httptest.NewRecorder()
+	testApp.app.ServeHTTP(rec, req)
+	return rec.Result(), rec.Body.String()
+}
+
+func Test_fixture_calendars(t *testing.T) {
+	clearTeamsDB()
+	clearFixtures()
+
+	ctx := context.Background()
+	lvpl, _ := testApp.app.TeamsDB.Create(ctx, liverpool.ToTeam(""))
+	mct, _ := testApp.app.TeamsDB.Create(ctx, manCity.ToTeam(""))
+	mun, _ := testApp.app.TeamsDB.Create(ctx, manUtd.ToTeam(""))
+	kickOff := time.Date(2030, 2, 7, 16, 30, 0, 0, time.UTC)
+	derby, _ := testApp.app.FixturesDB.Create(ctx, fixtures.CreateFixtureRequest{
+		HomeTeam: mct.ID, AwayTeam: mun.ID, MatchDate: kickOff,
+	})
+	testApp.app.FixturesDB.Create(ctx, fixtures.CreateFixtureRequest{
+		HomeTeam: lvpl.ID, AwayTeam: mct.ID, MatchDate: kickOff.Add(24 * time.Hour),
+	})
+
+	t.Run("all fixtures are in the league calendar, without a token", func(t *testing.T) {
+		result, feed := getCalendar("/fixtures.ics")
+		assert.Equal(t, http.StatusOK, result.StatusCode)
+		assert.Equal(t, "text/calendar; charset=utf-8", result.Header.Get("Content-Type"))
+		assert.True(t, strings.HasPrefix(feed, "BEGIN:VCALENDAR\r\n"))
+		assert.Equal(t, 2, strings.Count(feed, "BEGIN:VEVENT"))
+		assert.Contains(t, feed, "UID:"+derby.ID.Hex()+"@")
+		assert.Contains(t, feed, "DTSTART:20300207T163000Z")
+		assert.Contains(t, feed, "LOCATION:"+mct.HomeStadium)
+	})
+
+	t.Run("team calendars only have the team's fixtures", func(t *testing.T) {
+		result, feed := getCalendar("/teams/" + mun.ID + "/fixtures.ics")
+		assert.Equal(t, http.StatusOK, result.StatusCode)
+		assert.Equal(t, 1, strings.Count(feed, "BEGIN:VEVENT"))
+
+		result, _ = getCalendar("/teams/unknown/fixtures.ics")
+		assert.Equal(t, http.StatusNotFound, result.StatusCode)
+	})
+
+	t.Run("rescheduled fixtures get a new sequence", func(t *testing.T) {
+		_, feed := getCalendar("/teams/" + mun.ID + "/fixtures.ics")
+		assert.Contains(t, feed, "SEQUENCE:0\r\n")
+
+		testApp.app.FixturesDB.Update(ctx, derby.ID, fixtures.CreateFixtureRequest{MatchDate: kickOff.Add(time.Hour)})
+		_, feed = getCalendar("/teams/" + mun.ID + "/fixtures.ics")
+		assert.Contains(t, feed, "SEQUENCE:1\r\n")
+		assert.Contains(t, feed, "DTSTART:20300207T173000Z")
+	})
+}
@@ -0,0 +1,93 @@
+package tests
+
+import (
+	"context"
+	"gomoney-mock-epl/fixtures"
+	"gomoney-mock-epl/users"
+	"gomoney-mock-epl/web"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func setClock(dto web.ClockRequest, token string) *http.Response {
+	req, rec := jsonRequest(http.MethodPut, "/admin/clock", dto, token)
+	testApp.app.ServeHTTP(rec, req)
+	return rec.Result()
+}
+
+func Test_admins_can_move_the_clock(t *testing.T) {
+	clearTeamsDB()
+	clearFixtures()
+	defer func() {
+		req, rec := jsonRequest(http.MethodDelete, "/admin/clock", nil, adminToken)
+		testApp.app.ServeHTTP(rec, req)
+	}()
+
+	// Tokens expire by the clock, so it's only moved a little forward,
+	// to keep the tokens used in the tests valid.
+	matchday := time.Now().Add(30 * time.Minute).UTC().Truncate(time.Second)
+	frozen := 0.0
+
+	t.Run("users can't move the clock", func(t *testing.T) {
+		result := setClock(web.ClockRequest{Now: &matchday}, userToken)
+		assert.Equal(t, http.StatusForbidden, result.StatusCode)
+	})
+
+	t.Run("the clock can't run backwards", func(t *testing.T) {
+		backwards := -1.0
+		result := setClock(web.ClockRequest{Rate: &backwards}, adminToken)
+		assert.Equal(t, http.StatusUnprocessableEntity, result.StatusCode)
+	})
+
+	t.Run("admins can set and freeze the clock", func(t *testing.T) {
+		result := setClock(web.ClockRequest{Now: &matchday, Rate: &frozen}, adminToken)
+		assert.Equal(t, http.StatusOK, result.StatusCode)
+		body := web.DataDto{}
+		readJsonResponse(result.Body, &body)
+		state := body.Data.(map[string]interface{})
+		assert.Equal(t, matchday.Format(time.RFC3339), state["now"])
+		assert.Equal(t, true, state["frozen"])
+	})
+
+	t.Run("records are stamped with the time on the clock", func(t *testing.T) {
+		rec := createTeam(liverpool)
+		body := web.DataDto{}
+		readJsonResponse(rec.Result().Body, &body)
+		assert.Equal(t, matchday.Format(time.RFC3339), body.Data.(map[string]interface{})["created_at"])
+	})
+
+	t.Run("fixtures are played according to the clock", func(t *testing.T) {
+		ctx := context.Background()
+		lvpl, _ := testApp.app.TeamsDB.Create(ctx, manCity.ToTeam(""))
+		mct, _ := testApp.app.TeamsDB.Create(ctx, manUtd.ToTeam(""))
+		fixture, _ := testApp.app.FixturesDB.Create(ctx, fixtures.CreateFixtureRequest{
+			HomeTeam:  lvpl.ID,
+			AwayTeam:  mct.ID,
+			MatchDate: matchday.Add(-10 * time.Minute),
+		})
+		_, err := testApp.app.FixturesDB.RecordResult(ctx, fixture.ID, fixtures.RecordResultRequest{
+			FullTime: fixtures.Score{Home: 1},
+		})
+		assert.NoError(t, err, "the match date has passed on the clock")
+	})
+
+	t.Run("tokens are still valid when the clock is moved back", func(t *testing.T) {
+		yesterday := time.Now().AddDate(0, 0, -1).UTC()
+		result := setClock(web.ClockRequest{Now: &yesterday}, adminToken)
+		assert.Equal(t, http.StatusOK, result.StatusCode)
+
+		loginResponse := web.DataDto{}
+		readJsonResponse(loginAsAdmin(users.LoginDto{
+			Email:    testAdminEmail,
+			Password: testPassword,
+		}, *testApp).Result().Body, &loginResponse)
+		for _, token := range []string{adminToken, loginResponse.Data.(map[string]interface{})["token"].(string)} {
+			req, rec := jsonRequest(http.MethodGet, "/admin/clock", nil, token)
+			testApp.app.ServeHTTP(rec, req)
+			assert.Equal(t, http.StatusOK, rec.Code)
+		}
+	})
+}
@@ -0,0 +1,108 @@
+package tests
+
+import (
+	"context"
+	"gomoney-mock-epl/fixtures"
+	"gomoney-mock-epl/web"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func addFixtureEvent(id string, dto fixtures.EventRequest) *http.Response {
+	req, rec := jsonRequest(http.MethodPost, "/fixtures/"+id+"/events", dto, adminToken)
+	testApp.app.ServeHTTP(rec, req)
+	return rec.Result()
+}
+
+func Test_fixture_event_timelines(t *testing.T) {
+	clearTeamsDB()
+	clearFixtures()
+
+	ctx := context.Background()
+	lvpl, _ := testApp.app.TeamsDB.Create(ctx, liverpool.ToTeam(""))
+	mct, _ := testApp.app.TeamsDB.Create(ctx, manCity.ToTeam(""))
+	fixture, _ := testApp.app.FixturesDB.Create(ctx, fixtures.CreateFixtureRequest{
+		HomeTeam:  lvpl.ID,
+		AwayTeam:  mct.ID,
+		MatchDate: time.Now().Add(-1 * time.Hour),
+	})
+	id := fixture.ID.Hex()
+
+	t.Run("events can't be added before the fixture starts", func(t *testing.T) {
+		result := addFixtureEvent(id, fixtures.EventRequest{
+			Type: fixtures.Goal, Team: lvpl.ID, Player: "Salah", Minute: 12,
+		})
+		assert.Equal(t, http.StatusConflict, result.StatusCode)
+	})
+
+	var disallowed string
+	t.Run("admins can add events to live fixtures", func(t *testing.T) {
+		transitionFixture(id, fixtures.Live)
+		for _, event := range []fixtures.EventRequest{
+			{Type: fixtures.Goal, Team: lvpl.ID, Player: "Salah", Minute: 12},
+			{Type: fixtures.OwnGoal, Team: lvpl.ID, Player: "Konate", Minute: 45, StoppageTime: 2},
+			{Type: fixtures.Goal, Team: mct.ID, Player: "Haaland", Minute: 70},
+		} {
+			result := addFixtureEvent(id, event)
+			assert.Equal(t, http.StatusCreated, result.StatusCode)
+			body := web.DataDto{}
+			readJsonResponse(result.Body, &body)
+			disallowed = body.Data.(map[string]interface{})["id"].(string)
+		}
+	})
+
+	t.Run("events must be about the teams playing", func(t *testing.T) {
+		result := addFixtureEvent(id, fixtures.EventRequest{
+			Type: fixtures.Goal, Team: "unknown", Player: "Salah", Minute: 80,
+		})
+		assert.Equal(t, http.StatusUnprocessableEntity, result.StatusCode)
+	})
+
+	t.Run("VAR decisions overturn goals", func(t *testing.T) {
+		result := addFixtureEvent(id, fixtures.EventRequest{
+			Type: fixtures.VARDecision, Team: mct.ID, Minute: 71, Overturns: disallowed,
+		})
+		assert.Equal(t, http.StatusCreated, result.StatusCode)
+	})
+
+	t.Run("the result is derived from the events", func(t *testing.T) {
+		req, rec := jsonRequest(http.MethodGet, "/fixtures/"+id, nil, userToken)
+		testApp.app.ServeHTTP(rec, req)
+		body := web.DataDto{}
+		readJsonResponse(rec.Result().Body, &body)
+		result := body.Data.(map[string]interface{})["result"].(map[string]interface{})
+		assert.Equal(t, map[string]interface{}{"home": 1.0, "away": 1.0}, result["full_time"])
+		assert.Equal(t, map[string]interface{}{"home": 1.0, "away": 1.0}, result["half_time"])
+	})
+
+	t.Run("anyone can view the timeline in match order", func(t *testing.T) {
+		req, rec := jsonRequest(http.MethodGet, "/fixtures/"+id+"/events", nil, userToken)
+		testApp.app.ServeHTTP(rec, req)
+		body := web.DataDto{}
+		readJsonResponse(rec.Result().Body, &body)
+		events := body.Data.([]interface{})
+		assert.Len(t, events, 4)
+		assert.Equal(t, "Salah", events[0].(map[string]interface{})["player"])
+		assert.Equal(t, "var_decision", events[3].(map[string]interface{})["type"])
+	})
+
+	t.Run("results can't be recorded over the timeline", func(t *testing.T) {
+		req, rec := jsonRequest(http.MethodPut, "/fixtures/"+id+"/result", fixtures.RecordResultRequest{
+			FullTime: fixtures.Score{Home: 3},
+		}, adminToken)
+		testApp.app.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusConflict, rec.Result().StatusCode)
+	})
+
+	t.Run("removing an event removes the VAR decisions on it", func(t *testing.T) {
+		req, rec := jsonRequest(http.MethodDelete, "/fixtures/"+id+"/events/"+disallowed, nil, adminToken)
+		testApp.app.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusOK, rec.Result().StatusCode)
+
+		events, _ := testApp.app.FixturesDB.Events(ctx, fixture.ID)
+		assert.Len(t, events, 2)
+	})
+}
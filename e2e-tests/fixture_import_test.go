@@ -0,0 +1,99 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"gomoney-mock-epl/web"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func importFixtures(body, contentType, query, token string) (*http.Response, web.DataDto) {
+	req := httptest.NewRequest(http.MethodPost, "/fixtures/import"+query, strings.NewReader(body))
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec := httptest.NewRecorder()
+	testApp.app.ServeHTTP(rec, req)
+	dto := web.DataDto{}
+	readJsonResponse(rec.Result().Body, &dto)
+	return rec.Result(), dto
+}
+
+func Test_importing_fixtures(t *testing.T) {
+	clearTeamsDB()
+	clearFixtures()
+
+	ctx := context.Background()
+	lvpl, _ := testApp.app.TeamsDB.Create(ctx, liverpool.ToTeam(""))
+	testApp.app.TeamsDB.Create(ctx, manCity.ToTeam(""))
+	testApp.app.TeamsDB.Create(ctx, manUtd.ToTeam(""))
+	csv := "home_team,away_team,kickoff\n" +
+		lvpl.ID + "," + manCity.Name + ",2030-08-14 15:00\n" +
+		manUtd.NameAbbr + "," + manCity.ShortName + ",2030-08-21T12:30:00Z\n"
+
+	countFixtures := func() int64 {
+		count, _ := testApp.app.FixturesDB.CountDocuments(ctx, bson.D{})
+		return count
+	}
+
+	t.Run("only admins can import fixtures", func(t *testing.T) {
+		result, _ := importFixtures(csv, "text/csv", "", userToken)
+		assert.Equal(t, http.StatusForbidden, result.StatusCode)
+		result, _ = importFixtures(csv, "application/xml", "", adminToken)
+		assert.Equal(t, http.StatusUnsupportedMediaType, result.StatusCode)
+	})
+
+	t.Run("dry runs report on every row without writing", func(t *testing.T) {
+		result, body := importFixtures(csv, "text/csv", "?dry_run=true", adminToken)
+		assert.Equal(t, http.StatusOK, result.StatusCode)
+		report := body.Data.(map[string]interface{})
+		assert.Equal(t, true, report["valid"])
+		assert.Len(t, report["rows"], 2)
+		assert.EqualValues(t, 0, countFixtures())
+
+		invalid := csv + "Everton,Liverpool,2030-08-28 15:00\n" + "Liverpool,Liverpool,2030-09-04 15:00\n"
+		result, body = importFixtures(invalid, "text/csv", "?dry_run=true", adminToken)
+		assert.Equal(t, http.StatusUnprocessableEntity, result.StatusCode)
+		rows := body.Data.(map[string]interface{})["rows"].([]interface{})
+		assert.Nil(t, rows[0].(map[string]interface{})["errors"])
+		assert.Len(t, rows[2].(map[string]interface{})["errors"], 1)
+		assert.Len(t, rows[3].(map[string]interface{})["errors"], 1)
+		assert.EqualValues(t, 0, countFixtures())
+	})
+
+	t.Run("valid imports create every fixture", func(t *testing.T) {
+		jsonLines := fmt.Sprintf(`{"home_team": %q, "away_team": %q, "kickoff": "2030-08-28 15:00"}`,
+			manCity.NameAbbr, liverpool.Name)
+		result, body := importFixtures(jsonLines, "application/x-ndjson", "", adminToken)
+		assert.Equal(t, http.StatusCreated, result.StatusCode)
+		assert.EqualValues(t, 1, body.Data.(map[string]interface{})["created"])
+
+		result, _ = importFixtures(csv, "text/csv", "", adminToken)
+		assert.Equal(t, http.StatusCreated, result.StatusCode)
+		assert.EqualValues(t, 3, countFixtures())
+	})
+
+	t.Run("fixtures that already exist are flagged", func(t *testing.T) {
+		result, body := importFixtures(csv, "text/csv", "", adminToken)
+		assert.Equal(t, http.StatusUnprocessableEntity, result.StatusCode)
+		rows := body.Data.(map[string]interface{})["rows"].([]interface{})
+		errs := rows[0].(map[string]interface{})["errors"].([]interface{})
+		assert.Contains(t, errs[0].(map[string]interface{})["message"], "already exists")
+		assert.EqualValues(t, 3, countFixtures())
+	})
+
+	t.Run("imports can be larger than other requests", func(t *testing.T) {
+		large := "home_team,away_team,kickoff\n" + strings.Repeat("Everton,Liverpool,2030-08-28 15:00\n", 400)
+		result, _ := importFixtures(large, "text/csv", "?dry_run=true", adminToken)
+		assert.Equal(t, http.StatusUnprocessableEntity, result.StatusCode)
+
+		req, rec := jsonRequest(http.MethodPost, "/fixtures/", map[string]string{"home_team": large}, adminToken)
+		testApp.app.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+	})
+}
@@ -0,0 +1,111 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"gomoney-mock-epl/fixtures"
+	"gomoney-mock-epl/players"
+	"gomoney-mock-epl/web"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func submitLineup(id string, dto fixtures.LineupRequest, token string) *http.Response {
+	req, rec := jsonRequest(http.MethodPut, "/fixtures/"+id+"/lineups", dto, token)
+	testApp.app.ServeHTTP(rec, req)
+	return rec.Result()
+}
+
+func lineupFor(team string) fixtures.LineupRequest {
+	dto := fixtures.LineupRequest{
+		Team:      team,
+		Formation: "4-3-3",
+		Starters:  []fixtures.LineupPlayer{{Name: "Keeper", ShirtNumber: 1, Position: players.Goalkeeper}},
+		Bench:     []fixtures.LineupPlayer{{Name: "Reserve", ShirtNumber: 13, Position: players.Goalkeeper}},
+	}
+	for i := 2; i <= 11; i++ {
+		dto.Starters = append(dto.Starters, fixtures.LineupPlayer{
+			Name: fmt.Sprintf("Player %d", i), ShirtNumber: i, Position: players.Defender,
+		})
+	}
+	return dto
+}
+
+func Test_fixture_lineups(t *testing.T) {
+	clearTeamsDB()
+	clearFixtures()
+
+	ctx := context.Background()
+	lvpl, _ := testApp.app.TeamsDB.Create(ctx, liverpool.ToTeam(""))
+	mct, _ := testApp.app.TeamsDB.Create(ctx, manCity.ToTeam(""))
+	upcoming, _ := testApp.app.FixturesDB.Create(ctx, fixtures.CreateFixtureRequest{
+		HomeTeam:  lvpl.ID,
+		AwayTeam:  mct.ID,
+		MatchDate: time.Now().Add(24 * time.Hour),
+	})
+	played, _ := testApp.app.FixturesDB.Create(ctx, fixtures.CreateFixtureRequest{
+		HomeTeam:  mct.ID,
+		AwayTeam:  lvpl.ID,
+		MatchDate: time.Now().Add(-1 * time.Hour),
+	})
+	id := upcoming.ID.Hex()
+
+	t.Run("only admins can submit lineups", func(t *testing.T) {
+		result := submitLineup(id, lineupFor(lvpl.ID), userToken)
+		assert.Equal(t, http.StatusForbidden, result.StatusCode)
+	})
+
+	t.Run("admins can submit lineups before the match", func(t *testing.T) {
+		result := submitLineup(id, lineupFor(lvpl.ID), adminToken)
+		assert.Equal(t, http.StatusOK, result.StatusCode)
+
+		changed := lineupFor(lvpl.ID)
+		changed.Formation = "4-2-3-1"
+		result = submitLineup(id, changed, adminToken)
+		assert.Equal(t, http.StatusOK, result.StatusCode)
+	})
+
+	t.Run("lineups must follow the rules", func(t *testing.T) {
+		invalid := lineupFor(mct.ID)
+		invalid.Starters = invalid.Starters[1:]
+		result := submitLineup(id, invalid, adminToken)
+		assert.Equal(t, http.StatusUnprocessableEntity, result.StatusCode)
+
+		result = submitLineup(id, lineupFor("unknown"), adminToken)
+		assert.Equal(t, http.StatusUnprocessableEntity, result.StatusCode)
+	})
+
+	t.Run("anyone can view the lineups", func(t *testing.T) {
+		req, rec := jsonRequest(http.MethodGet, "/fixtures/"+id+"/lineups", nil, userToken)
+		testApp.app.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		body := web.DataDto{}
+		readJsonResponse(rec.Result().Body, &body)
+		lineups := body.Data.(map[string]interface{})
+		home := lineups["home"].(map[string]interface{})
+		assert.Equal(t, "4-2-3-1", home["formation"])
+		assert.Len(t, home["starters"], 11)
+		assert.Nil(t, lineups["away"])
+		assert.Equal(t, false, lineups["locked"])
+	})
+
+	t.Run("lineups are locked once the match date has passed", func(t *testing.T) {
+		result := submitLineup(played.ID.Hex(), lineupFor(lvpl.ID), adminToken)
+		assert.Equal(t, http.StatusConflict, result.StatusCode)
+
+		req, rec := jsonRequest(http.MethodGet, "/fixtures/"+played.ID.Hex()+"/lineups", nil, userToken)
+		testApp.app.ServeHTTP(rec, req)
+		body := web.DataDto{}
+		readJsonResponse(rec.Result().Body, &body)
+		assert.Equal(t, true, body.Data.(map[string]interface{})["locked"])
+	})
+
+	t.Run("lineups of unknown fixtures are not found", func(t *testing.T) {
+		req, rec := jsonRequest(http.MethodGet, "/fixtures/unknown/lineups", nil, userToken)
+		testApp.app.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+}
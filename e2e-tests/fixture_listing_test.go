@@ -0,0 +1,103 @@
+package tests
+
+import (
+	"context"
+	"gomoney-mock-epl/fixtures"
+	"gomoney-mock-epl/web"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func listFixtures(t *testing.T, query url.Values) ([]string, *web.Pagination) {
+	req, rec := jsonRequest(http.MethodGet, "/fixtures/?"+query.Encode(), nil, userToken)
+	testApp.app.ServeHTTP(rec, req)
+	result := rec.Result()
+	assert.Equal(t, http.StatusOK, result.StatusCode)
+	body := web.DataDto{}
+	readJsonResponse(result.Body, &body)
+	ids := []string{}
+	for _, fixture := range body.Data.([]interface{}) {
+		ids = append(ids, fixture.(map[string]interface{})["id"].(string))
+	}
+	return ids, body.Pagination
+}
+
+func Test_paging_through_fixtures(t *testing.T) {
+	clearTeamsDB()
+	clearFixtures()
+
+	ctx := context.Background()
+	lvpl, _ := testApp.app.TeamsDB.Create(ctx, liverpool.ToTeam(""))
+	mct, _ := testApp.app.TeamsDB.Create(ctx, manCity.ToTeam(""))
+	mun, _ := testApp.app.TeamsDB.Create(ctx, manUtd.ToTeam(""))
+	kickOff := time.Date(2021, time.August, 14, 15, 0, 0, 0, time.UTC)
+	byDate := []string{}
+	for i, pairing := range [][2]string{
+		{lvpl.ID, mct.ID}, {mct.ID, mun.ID}, {mun.ID, lvpl.ID}, {mct.ID, lvpl.ID}, {lvpl.ID, mun.ID},
+	} {
+		fixture, _ := testApp.app.FixturesDB.Create(ctx, fixtures.CreateFixtureRequest{
+			HomeTeam:  pairing[0],
+			AwayTeam:  pairing[1],
+			MatchDate: kickOff.AddDate(0, 0, 7*i),
+		})
+		byDate = append(byDate, fixture.ID.Hex())
+	}
+
+	t.Run("fixtures are paged through in match date order", func(t *testing.T) {
+		page, pagination := listFixtures(t, url.Values{"limit": {"2"}})
+		assert.Equal(t, byDate[0:2], page)
+		assert.Empty(t, pagination.Prev)
+
+		page, pagination = listFixtures(t, url.Values{"limit": {"2"}, "cursor": {pagination.Next}})
+		assert.Equal(t, byDate[2:4], page)
+
+		page, last := listFixtures(t, url.Values{"limit": {"2"}, "cursor": {pagination.Next}})
+		assert.Equal(t, byDate[4:], page)
+		assert.Empty(t, last.Next)
+
+		page, pagination = listFixtures(t, url.Values{"limit": {"2"}, "cursor": {last.Prev}})
+		assert.Equal(t, byDate[2:4], page)
+		page, pagination = listFixtures(t, url.Values{"limit": {"2"}, "cursor": {pagination.Prev}})
+		assert.Equal(t, byDate[0:2], page)
+		assert.Empty(t, pagination.Prev)
+	})
+
+	t.Run("fixtures can be sorted latest first", func(t *testing.T) {
+		page, _ := listFixtures(t, url.Values{"sort": {"-match_date"}, "limit": {"1"}})
+		assert.Equal(t, byDate[4:], page)
+	})
+
+	t.Run("fixtures can be filtered by team and venue", func(t *testing.T) {
+		page, _ := listFixtures(t, url.Values{"team": {lvpl.ID}})
+		assert.Equal(t, []string{byDate[0], byDate[2], byDate[3], byDate[4]}, page)
+		page, _ = listFixtures(t, url.Values{"team": {lvpl.ID}, "venue": {"away"}})
+		assert.Equal(t, []string{byDate[2], byDate[3]}, page)
+	})
+
+	t.Run("fixtures can be filtered by date", func(t *testing.T) {
+		page, _ := listFixtures(t, url.Values{"from": {"2021-08-21"}, "to": {"2021-09-04"}})
+		assert.Equal(t, byDate[1:3], page)
+	})
+
+	t.Run("invalid queries are refused", func(t *testing.T) {
+		req, rec := jsonRequest(http.MethodGet, "/fixtures/?sort=name", nil, userToken)
+		testApp.app.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Result().StatusCode)
+	})
+
+	t.Run("fixtures whose teams are gone don't shorten pages", func(t *testing.T) {
+		_, err := testApp.app.FixturesDB.Collection.InsertOne(ctx, bson.D{
+			{Key: "home_team", Value: "gone"},
+			{Key: "away_team", Value: lvpl.ID},
+			{Key: "match_date", Value: kickOff.AddDate(0, 0, -7)},
+		})
+		assert.NoError(t, err)
+		page, _ := listFixtures(t, url.Values{"limit": {"2"}})
+		assert.Equal(t, byDate[0:2], page)
+	})
+}
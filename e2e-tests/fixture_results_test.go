@@ -0,0 +1,72 @@
+package tests
+
+import (
+	"context"
+	"gomoney-mock-epl/fixtures"
+	"gomoney-mock-epl/web"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_recording_fixture_results(t *testing.T) {
+	clearTeamsDB()
+	clearFixtures()
+
+	ctx := context.Background()
+	lvpl, _ := testApp.app.TeamsDB.Create(ctx, liverpool.ToTeam(""))
+	mct, _ := testApp.app.TeamsDB.Create(ctx, manCity.ToTeam(""))
+	played, _ := testApp.app.FixturesDB.Create(ctx, fixtures.CreateFixtureRequest{
+		HomeTeam:  lvpl.ID,
+		AwayTeam:  mct.ID,
+		MatchDate: time.Now().Add(-3 * time.Hour),
+	})
+	upcoming, _ := testApp.app.FixturesDB.Create(ctx, fixtures.CreateFixtureRequest{
+		HomeTeam:  mct.ID,
+		AwayTeam:  lvpl.ID,
+		MatchDate: time.Now().Add(72 * time.Hour),
+	})
+	result := fixtures.RecordResultRequest{
+		FullTime: fixtures.Score{Home: 2, Away: 1},
+		HalfTime: &fixtures.Score{Home: 1, Away: 1},
+	}
+
+	t.Run("admins can record results of played fixtures", func(t *testing.T) {
+		req, rec := jsonRequest(http.MethodPut, "/fixtures/"+played.ID.Hex()+"/result", result, adminToken)
+		testApp.app.ServeHTTP(rec, req)
+		response := rec.Result()
+		assert.Equal(t, http.StatusOK, response.StatusCode)
+
+		req, rec = jsonRequest(http.MethodGet, "/fixtures/"+played.ID.Hex(), nil, userToken)
+		testApp.app.ServeHTTP(rec, req)
+		body := web.DataDto{}
+		readJsonResponse(rec.Result().Body, &body)
+		fullTime := body.Data.(map[string]interface{})["result"].(map[string]interface{})["full_time"].(map[string]interface{})
+		assert.Equal(t, float64(2), fullTime["home"])
+		assert.Equal(t, float64(1), fullTime["away"])
+	})
+
+	t.Run("results cannot be recorded before the match date", func(t *testing.T) {
+		req, rec := jsonRequest(http.MethodPut, "/fixtures/"+upcoming.ID.Hex()+"/result", result, adminToken)
+		testApp.app.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Result().StatusCode)
+	})
+
+	t.Run("users cannot record results", func(t *testing.T) {
+		req, rec := jsonRequest(http.MethodPut, "/fixtures/"+played.ID.Hex()+"/result", result, userToken)
+		testApp.app.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusForbidden, rec.Result().StatusCode)
+	})
+
+	t.Run("admins can remove results", func(t *testing.T) {
+		req, rec := jsonRequest(http.MethodDelete, "/fixtures/"+played.ID.Hex()+"/result", nil, adminToken)
+		testApp.app.ServeHTTP(rec, req)
+		response := rec.Result()
+		assert.Equal(t, http.StatusOK, response.StatusCode)
+		body := web.DataDto{}
+		readJsonResponse(response.Body, &body)
+		assert.Nil(t, body.Data.(map[string]interface{})["result"])
+	})
+}
@@ -0,0 +1,68 @@
+package tests
+
+import (
+	"context"
+	"gomoney-mock-epl/fixtures"
+	"gomoney-mock-epl/web"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_fixture_status_transitions(t *testing.T) {
+	clearTeamsDB()
+	clearFixtures()
+
+	ctx := context.Background()
+	lvpl, _ := testApp.app.TeamsDB.Create(ctx, liverpool.ToTeam(""))
+	mct, _ := testApp.app.TeamsDB.Create(ctx, manCity.ToTeam(""))
+	fixture, _ := testApp.app.FixturesDB.Create(ctx, fixtures.CreateFixtureRequest{
+		HomeTeam:  lvpl.ID,
+		AwayTeam:  mct.ID,
+		MatchDate: time.Now().Add(-1 * time.Hour),
+	})
+	id := fixture.ID.Hex()
+
+	t.Run("new fixtures are scheduled", func(t *testing.T) {
+		assert.Equal(t, fixtures.Scheduled, fixture.Status)
+	})
+
+	t.Run("admins can move fixtures through legal transitions", func(t *testing.T) {
+		for _, status := range []fixtures.Status{fixtures.Live, fixtures.HalfTime, fixtures.Live, fixtures.Finished} {
+			result := transitionFixture(id, status)
+			assert.Equal(t, http.StatusOK, result.StatusCode)
+			body := web.DataDto{}
+			readJsonResponse(result.Body, &body)
+			assert.Equal(t, string(status), body.Data.(map[string]interface{})["status"])
+		}
+	})
+
+	t.Run("illegal transitions are refused", func(t *testing.T) {
+		result := transitionFixture(id, fixtures.Postponed)
+		assert.Equal(t, http.StatusConflict, result.StatusCode)
+	})
+
+	t.Run("unknown statuses are refused", func(t *testing.T) {
+		result := transitionFixture(id, fixtures.Status("completed"))
+		assert.Equal(t, http.StatusUnprocessableEntity, result.StatusCode)
+	})
+
+	t.Run("postponed fixtures are not listed as completed", func(t *testing.T) {
+		postponed, _ := testApp.app.FixturesDB.Create(ctx, fixtures.CreateFixtureRequest{
+			HomeTeam:  mct.ID,
+			AwayTeam:  lvpl.ID,
+			MatchDate: time.Now().Add(-48 * time.Hour),
+		})
+		transitionFixture(postponed.ID.Hex(), fixtures.Postponed)
+
+		req, rec := jsonRequest(http.MethodGet, "/fixtures/?status=completed", nil, userToken)
+		testApp.app.ServeHTTP(rec, req)
+		body := web.DataDto{}
+		readJsonResponse(rec.Result().Body, &body)
+		data := body.Data.([]interface{})
+		assert.Len(t, data, 1)
+		assert.Equal(t, id, data[0].(map[string]interface{})["id"])
+	})
+}
@@ -143,7 +143,11 @@ func Test_actions_on_fixtures(t *testing.T) {
 		completedFixture := createFixtureDto
 		completedFixture.MatchDate = time.Now().Add(-72 * time.Hour)
 		createFixture(pendingFixture)
-		createFixture(completedFixture)
+		completed := web.DataDto{}
+		readJsonResponse(createFixture(completedFixture).Body, &completed)
+		completedID := completed.Data.(map[string]interface{})["id"].(string)
+		transitionFixture(completedID, fixtures.Live)
+		transitionFixture(completedID, fixtures.Finished)
 
 		req, rec := jsonRequest(http.MethodGet, "/fixtures/?status=pending", nil, userToken)
 		testApp.app.ServeHTTP(rec, req)
@@ -169,6 +173,13 @@ func Test_actions_on_fixtures(t *testing.T) {
 	})
 }
 
+func transitionFixture(id string, status fixtures.Status) *http.Response {
+	dto := fixtures.TransitionRequest{Status: string(status)}
+	req, rec := jsonRequest(http.MethodPost, "/fixtures/"+id+"/status", dto, adminToken)
+	testApp.app.ServeHTTP(rec, req)
+	return rec.Result()
+}
+
 func createFixture(dto fixtures.CreateFixtureRequest) *http.Response {
 	req, rec := jsonRequest(http.MethodPost, "/fixtures/", dto, adminToken)
 	testApp.app.ServeHTTP(rec, req)
@@ -0,0 +1,83 @@
+package tests
+
+import (
+	"context"
+	"gomoney-mock-epl/fixtures"
+	"gomoney-mock-epl/web"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_head_to_head(t *testing.T) {
+	clearTeamsDB()
+	clearFixtures()
+
+	ctx := context.Background()
+	lvpl, _ := testApp.app.TeamsDB.Create(ctx, liverpool.ToTeam(""))
+	mct, _ := testApp.app.TeamsDB.Create(ctx, manCity.ToTeam(""))
+	mutd, _ := testApp.app.TeamsDB.Create(ctx, manUtd.ToTeam(""))
+	for i, meeting := range []struct {
+		home, away string
+		score      fixtures.Score
+	}{
+		{lvpl.ID, mct.ID, fixtures.Score{Home: 4, Away: 0}},
+		{mct.ID, lvpl.ID, fixtures.Score{Home: 2, Away: 2}},
+		{mct.ID, lvpl.ID, fixtures.Score{Home: 1, Away: 0}},
+		{lvpl.ID, mutd.ID, fixtures.Score{Home: 5, Away: 0}},
+	} {
+		fixture, _ := testApp.app.FixturesDB.Create(ctx, fixtures.CreateFixtureRequest{
+			HomeTeam:  meeting.home,
+			AwayTeam:  meeting.away,
+			MatchDate: time.Now().Add(-time.Duration(i+1) * 7 * 24 * time.Hour),
+		})
+		finishFixture(t, fixture, meeting.score)
+	}
+	testApp.app.FixturesDB.Create(ctx, fixtures.CreateFixtureRequest{
+		HomeTeam:  lvpl.ID,
+		AwayTeam:  mct.ID,
+		MatchDate: time.Now().Add(7 * 24 * time.Hour),
+	})
+	abandoned, _ := testApp.app.FixturesDB.Create(ctx, fixtures.CreateFixtureRequest{
+		HomeTeam:  mct.ID,
+		AwayTeam:  lvpl.ID,
+		MatchDate: time.Now().Add(-time.Hour),
+	})
+	testApp.app.FixturesDB.Transition(ctx, abandoned.ID, fixtures.TransitionRequest{Status: string(fixtures.Live)})
+	testApp.app.FixturesDB.AddEvent(ctx, abandoned.ID, fixtures.EventRequest{Type: fixtures.Goal, Team: mct.ID, Minute: 10})
+	testApp.app.FixturesDB.Transition(ctx, abandoned.ID, fixtures.TransitionRequest{Status: string(fixtures.Abandoned)})
+
+	t.Run("finished meetings between the teams are compared", func(t *testing.T) {
+		req, rec := jsonRequest(http.MethodGet, "/teams/"+lvpl.ID+"/head-to-head/"+mct.ID, nil, userToken)
+		testApp.app.ServeHTTP(rec, req)
+		result := rec.Result()
+		assert.Equal(t, http.StatusOK, result.StatusCode)
+		body := web.DataDto{}
+		readJsonResponse(result.Body, &body)
+		assert.Equal(t, "HeadToHead", body.Type)
+		h2h := body.Data.(map[string]interface{})
+		assert.Equal(t, float64(3), h2h["played"])
+		assert.Len(t, h2h["fixtures"], 3)
+
+		team := h2h["team"].(map[string]interface{})
+		assert.Equal(t, lvpl.ID, team["team"].(map[string]interface{})["id"])
+		assert.Equal(t, float64(1), team["won"])
+		assert.Equal(t, float64(1), team["drawn"])
+		assert.Equal(t, float64(1), team["lost"])
+		assert.Equal(t, float64(6), team["goals_scored"])
+		biggestWin := team["biggest_win"].(map[string]interface{})
+		assert.Equal(t, float64(4), biggestWin["result"].(map[string]interface{})["full_time"].(map[string]interface{})["home"])
+
+		other := h2h["other_team"].(map[string]interface{})
+		assert.Equal(t, float64(1), other["won"])
+		assert.Equal(t, float64(3), other["goals_scored"])
+	})
+
+	t.Run("unknown teams are not found", func(t *testing.T) {
+		req, rec := jsonRequest(http.MethodGet, "/teams/"+lvpl.ID+"/head-to-head/nobody", nil, userToken)
+		testApp.app.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusNotFound, rec.Result().StatusCode)
+	})
+}
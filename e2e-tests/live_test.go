@@ -0,0 +1,121 @@
+package tests
+
+import (
+	"bufio"
+	"context"
+	"gomoney-mock-epl/fixtures"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// readServerSentEvent reads the next event from a stream,
+// skipping comments and the retry interval.
+func readServerSentEvent(stream *bufio.Reader) map[string]string {
+	event := map[string]string{}
+	for {
+		line, err := stream.ReadString('\n')
+		if err != nil {
+			return event
+		}
+		line = strings.TrimRight(line, "\n")
+		if line == "" && len(event) > 0 {
+			return event
+		}
+		if parts := strings.SplitN(line, ": ", 2); len(parts) == 2 && parts[0] != "" && parts[0] != "retry" {
+			event[parts[0]] = parts[1]
+		}
+	}
+}
+
+func openStream(t *testing.T, url, lastEventID string) (*bufio.Reader, func()) {
+	req, _ := http.NewRequest(http.MethodGet, url+"?token="+userToken, nil)
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+	res, err := http.DefaultClient.Do(req)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "text/event-stream", res.Header.Get("Content-Type"))
+	return bufio.NewReader(res.Body), func() { res.Body.Close() }
+}
+
+func Test_following_live_fixtures(t *testing.T) {
+	clearTeamsDB()
+	clearFixtures()
+
+	server := httptest.NewServer(testApp.app)
+	defer server.Close()
+
+	ctx := context.Background()
+	lvpl, _ := testApp.app.TeamsDB.Create(ctx, liverpool.ToTeam(""))
+	mct, _ := testApp.app.TeamsDB.Create(ctx, manCity.ToTeam(""))
+	fixture, _ := testApp.app.FixturesDB.Create(ctx, fixtures.CreateFixtureRequest{
+		HomeTeam:  lvpl.ID,
+		AwayTeam:  mct.ID,
+		MatchDate: time.Now().Add(-1 * time.Hour),
+	})
+	id := fixture.ID.Hex()
+	streamURL := server.URL + "/fixtures/" + id + "/stream"
+
+	t.Run("streams require authentication", func(t *testing.T) {
+		res, err := http.Get(streamURL)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+	})
+
+	t.Run("only existing fixtures can be followed", func(t *testing.T) {
+		req, rec := jsonRequest(http.MethodGet, "/fixtures/"+lvpl.ID+"/stream", nil, userToken)
+		testApp.app.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusNotFound, rec.Result().StatusCode)
+	})
+
+	t.Run("tokens in the query are kept out of the logs", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/fixtures/"+lvpl.ID+"/stream?token="+userToken, nil)
+		rec := httptest.NewRecorder()
+		testApp.app.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusNotFound, rec.Result().StatusCode)
+		assert.NotContains(t, req.RequestURI, userToken)
+		assert.Contains(t, req.RequestURI, "token=redacted")
+	})
+
+	var lastEventID string
+	t.Run("changes to the fixture are pushed to followers", func(t *testing.T) {
+		fixtureStream, closeFixtureStream := openStream(t, streamURL, "")
+		defer closeFixtureStream()
+		leagueStream, closeLeagueStream := openStream(t, server.URL+"/live", "")
+		defer closeLeagueStream()
+
+		transitionFixture(id, fixtures.Live)
+		addFixtureEvent(id, fixtures.EventRequest{
+			Type: fixtures.Goal, Team: lvpl.ID, Player: "Salah", Minute: 3,
+		})
+
+		event := readServerSentEvent(fixtureStream)
+		assert.Equal(t, "status_changed", event["event"])
+		assert.Contains(t, event["data"], `"status":"live"`)
+		event = readServerSentEvent(fixtureStream)
+		assert.Equal(t, "event_added", event["event"])
+		assert.Contains(t, event["data"], `"player":"Salah"`)
+		assert.Contains(t, event["data"], `"full_time":{"home":1,"away":0}`)
+		lastEventID = event["id"]
+
+		assert.Equal(t, "status_changed", readServerSentEvent(leagueStream)["event"])
+	})
+
+	t.Run("reconnecting clients receive the changes they missed", func(t *testing.T) {
+		transitionFixture(id, fixtures.HalfTime)
+
+		stream, closeStream := openStream(t, streamURL, lastEventID)
+		defer closeStream()
+		event := readServerSentEvent(stream)
+		assert.Equal(t, "status_changed", event["event"])
+		assert.Contains(t, event["data"], `"status":"half_time"`)
+	})
+}
@@ -0,0 +1,158 @@
+package tests
+
+import (
+	"context"
+	"gomoney-mock-epl/fixtures"
+	"gomoney-mock-epl/officials"
+	"gomoney-mock-epl/web"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func clearOfficials() {
+	testApp.app.OfficialsDB.DeleteMany(context.Background(), bson.D{})
+}
+
+func assignOfficials(id string, dto fixtures.OfficialsRequest, token string) *http.Response {
+	req, rec := jsonRequest(http.MethodPut, "/fixtures/"+id+"/officials", dto, token)
+	testApp.app.ServeHTTP(rec, req)
+	return rec.Result()
+}
+
+func Test_match_officials(t *testing.T) {
+	clearTeamsDB()
+	clearFixtures()
+	clearOfficials()
+
+	ctx := context.Background()
+	lvpl, _ := testApp.app.TeamsDB.Create(ctx, liverpool.ToTeam(""))
+	mct, _ := testApp.app.TeamsDB.Create(ctx, manCity.ToTeam(""))
+	mun, _ := testApp.app.TeamsDB.Create(ctx, manUtd.ToTeam(""))
+	kickOff := time.Now().Add(48 * time.Hour).Truncate(time.Second)
+	first, _ := testApp.app.FixturesDB.Create(ctx, fixtures.CreateFixtureRequest{
+		HomeTeam: lvpl.ID, AwayTeam: mct.ID, MatchDate: kickOff,
+	})
+	sameDay, _ := testApp.app.FixturesDB.Create(ctx, fixtures.CreateFixtureRequest{
+		HomeTeam: mun.ID, AwayTeam: lvpl.ID, MatchDate: kickOff.Add(2 * time.Hour),
+	})
+	nextDay, _ := testApp.app.FixturesDB.Create(ctx, fixtures.CreateFixtureRequest{
+		HomeTeam: mct.ID, AwayTeam: mun.ID, MatchDate: kickOff.Add(24 * time.Hour),
+	})
+
+	var refereeID string
+	t.Run("only admins can add officials", func(t *testing.T) {
+		dto := officials.OfficialRequest{Name: "Michael Oliver", Role: officials.Referee, Nationality: "England"}
+		req, rec := jsonRequest(http.MethodPost, "/officials/", dto, userToken)
+		testApp.app.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+
+		req, rec = jsonRequest(http.MethodPost, "/officials/", dto, adminToken)
+		testApp.app.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		body := web.DataDto{}
+		readJsonResponse(rec.Result().Body, &body)
+		refereeID = body.Data.(map[string]interface{})["id"].(string)
+
+		req, rec = jsonRequest(http.MethodPost, "/officials/",
+			officials.OfficialRequest{Name: "Stuart Burt", Role: "linesman"}, adminToken)
+		testApp.app.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	})
+
+	assistant, _ := testApp.app.OfficialsDB.Create(ctx, officials.OfficialRequest{Name: "Stuart Burt", Role: officials.Assistant})
+	video, _ := testApp.app.OfficialsDB.Create(ctx, officials.OfficialRequest{Name: "Stuart Attwell", Role: officials.VAR})
+	other, _ := testApp.app.OfficialsDB.Create(ctx, officials.OfficialRequest{Name: "Anthony Taylor", Role: officials.Referee})
+
+	t.Run("officials are listed by role", func(t *testing.T) {
+		req, rec := jsonRequest(http.MethodGet, "/officials/?role=referee", nil, userToken)
+		testApp.app.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		body := web.DataDto{}
+		readJsonResponse(rec.Result().Body, &body)
+		assert.Len(t, body.Data, 2)
+
+		req, rec = jsonRequest(http.MethodGet, "/officials/?role=linesman", nil, userToken)
+		testApp.app.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("admins assign officials in their roles", func(t *testing.T) {
+		dto := fixtures.OfficialsRequest{Referee: refereeID, Assistants: []string{assistant.ID}, VAR: video.ID}
+		assert.Equal(t, http.StatusForbidden, assignOfficials(first.ID.Hex(), dto, userToken).StatusCode)
+		assert.Equal(t, http.StatusOK, assignOfficials(first.ID.Hex(), dto, adminToken).StatusCode)
+
+		wrongRole := fixtures.OfficialsRequest{Referee: assistant.ID}
+		result := assignOfficials(nextDay.ID.Hex(), wrongRole, adminToken)
+		assert.Equal(t, http.StatusUnprocessableEntity, result.StatusCode)
+
+		req, rec := jsonRequest(http.MethodGet, "/fixtures/"+first.ID.Hex()+"/officials", nil, userToken)
+		testApp.app.ServeHTTP(rec, req)
+		body := web.DataDto{}
+		readJsonResponse(rec.Result().Body, &body)
+		assigned := body.Data.(map[string]interface{})
+		assert.Equal(t, "Michael Oliver", assigned["referee"].(map[string]interface{})["name"])
+		assert.Len(t, assigned["assistants"], 1)
+		assert.Equal(t, video.ID, assigned["var"].(map[string]interface{})["id"])
+	})
+
+	t.Run("officials can't work overlapping fixtures", func(t *testing.T) {
+		result := assignOfficials(sameDay.ID.Hex(), fixtures.OfficialsRequest{Referee: other.ID, VAR: video.ID}, adminToken)
+		assert.Equal(t, http.StatusConflict, result.StatusCode)
+		body := web.ErrorDto{}
+		readJsonResponse(result.Body, &body)
+		assert.Equal(t, "fixtures/official-unavailable", body.Code)
+
+		result = assignOfficials(sameDay.ID.Hex(), fixtures.OfficialsRequest{Referee: other.ID}, adminToken)
+		assert.Equal(t, http.StatusOK, result.StatusCode)
+		result = assignOfficials(nextDay.ID.Hex(), fixtures.OfficialsRequest{Referee: refereeID}, adminToken)
+		assert.Equal(t, http.StatusOK, result.StatusCode)
+	})
+
+	t.Run("fixtures can't be rescheduled into a clash", func(t *testing.T) {
+		req, rec := jsonRequest(http.MethodPatch, "/fixtures/"+nextDay.ID.Hex(),
+			fixtures.CreateFixtureRequest{MatchDate: kickOff.Add(time.Hour)}, adminToken)
+		testApp.app.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusConflict, rec.Code)
+	})
+
+	t.Run("postponed fixtures can't be rescheduled into a clash", func(t *testing.T) {
+		result := transitionFixture(first.ID.Hex(), fixtures.Postponed)
+		assert.Equal(t, http.StatusOK, result.StatusCode)
+		result = assignOfficials(sameDay.ID.Hex(), fixtures.OfficialsRequest{Referee: refereeID}, adminToken)
+		assert.Equal(t, http.StatusOK, result.StatusCode)
+		result = transitionFixture(first.ID.Hex(), fixtures.Scheduled)
+		assert.Equal(t, http.StatusConflict, result.StatusCode)
+
+		result = assignOfficials(sameDay.ID.Hex(), fixtures.OfficialsRequest{Referee: other.ID}, adminToken)
+		assert.Equal(t, http.StatusOK, result.StatusCode)
+		result = transitionFixture(first.ID.Hex(), fixtures.Scheduled)
+		assert.Equal(t, http.StatusOK, result.StatusCode)
+	})
+
+	t.Run("officials have a history of the fixtures they worked", func(t *testing.T) {
+		req, rec := jsonRequest(http.MethodGet, "/officials/"+refereeID+"/fixtures", nil, userToken)
+		testApp.app.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		body := web.DataDto{}
+		readJsonResponse(rec.Result().Body, &body)
+		worked := body.Data.([]interface{})
+		assert.Len(t, worked, 2)
+		assert.Equal(t, nextDay.ID.Hex(), worked[0].(map[string]interface{})["id"])
+		assert.Equal(t, "referee", worked[0].(map[string]interface{})["role"])
+	})
+
+	t.Run("officials assigned to fixtures can't be removed", func(t *testing.T) {
+		req, rec := jsonRequest(http.MethodDelete, "/officials/"+refereeID, nil, adminToken)
+		testApp.app.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusConflict, rec.Code)
+
+		unassigned, _ := testApp.app.OfficialsDB.Create(ctx, officials.OfficialRequest{Name: "Peter Bankes", Role: officials.Referee})
+		req, rec = jsonRequest(http.MethodDelete, "/officials/"+unassigned.ID, nil, adminToken)
+		testApp.app.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+}
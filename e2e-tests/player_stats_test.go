@@ -0,0 +1,169 @@
+package tests
+
+import (
+	"context"
+	"gomoney-mock-epl/fixtures"
+	"gomoney-mock-epl/players"
+	"gomoney-mock-epl/web"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func submitPerformance(id string, dto fixtures.PerformanceRequest) *http.Response {
+	req, rec := jsonRequest(http.MethodPut, "/fixtures/"+id+"/performances", dto, adminToken)
+	testApp.app.ServeHTTP(rec, req)
+	return rec.Result()
+}
+
+func leaderboard(t *testing.T, path string) []interface{} {
+	req, rec := jsonRequest(http.MethodGet, path, nil, userToken)
+	testApp.app.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	body := web.DataDto{}
+	readJsonResponse(rec.Result().Body, &body)
+	return body.Data.([]interface{})
+}
+
+func Test_player_stats(t *testing.T) {
+	clearTeamsDB()
+	clearFixtures()
+	clearPlayers()
+
+	ctx := context.Background()
+	lvpl, _ := testApp.app.TeamsDB.Create(ctx, liverpool.ToTeam(""))
+	mct, _ := testApp.app.TeamsDB.Create(ctx, manCity.ToTeam(""))
+	born := time.Date(1995, time.January, 1, 0, 0, 0, 0, time.UTC)
+	salah, _ := testApp.app.PlayersDB.Create(ctx, players.PlayerRequest{
+		Name: "Mohamed Salah", Position: players.Forward, DateOfBirth: born, Team: lvpl.ID,
+	})
+	haaland, _ := testApp.app.PlayersDB.Create(ctx, players.PlayerRequest{
+		Name: "Erling Haaland", Position: players.Forward, DateOfBirth: born, Team: mct.ID,
+	})
+	first, _ := testApp.app.FixturesDB.Create(ctx, fixtures.CreateFixtureRequest{
+		HomeTeam:  lvpl.ID,
+		AwayTeam:  mct.ID,
+		MatchDate: time.Date(2021, time.October, 3, 16, 30, 0, 0, time.UTC),
+	})
+	second, _ := testApp.app.FixturesDB.Create(ctx, fixtures.CreateFixtureRequest{
+		HomeTeam:  mct.ID,
+		AwayTeam:  lvpl.ID,
+		MatchDate: time.Date(2022, time.April, 10, 15, 30, 0, 0, time.UTC),
+	})
+
+	t.Run("performances can't be recorded before the fixture starts", func(t *testing.T) {
+		result := submitPerformance(first.ID.Hex(), fixtures.PerformanceRequest{
+			Player: salah.ID, Team: lvpl.ID, Minutes: 90, Goals: 1,
+		})
+		assert.Equal(t, http.StatusConflict, result.StatusCode)
+	})
+
+	t.Run("admins can record performances", func(t *testing.T) {
+		transitionFixture(first.ID.Hex(), fixtures.Live)
+		transitionFixture(second.ID.Hex(), fixtures.Live)
+		for _, p := range []struct {
+			fixture string
+			dto     fixtures.PerformanceRequest
+		}{
+			{first.ID.Hex(), fixtures.PerformanceRequest{Player: salah.ID, Team: lvpl.ID, Minutes: 90, Goals: 1}},
+			{first.ID.Hex(), fixtures.PerformanceRequest{Player: haaland.ID, Team: mct.ID, Minutes: 90, Goals: 1, YellowCards: 1}},
+			{second.ID.Hex(), fixtures.PerformanceRequest{Player: salah.ID, Team: lvpl.ID, Minutes: 80, Goals: 1, Assists: 1}},
+			{second.ID.Hex(), fixtures.PerformanceRequest{Player: haaland.ID, Team: mct.ID, Minutes: 90, Goals: 1}},
+		} {
+			result := submitPerformance(p.fixture, p.dto)
+			assert.Equal(t, http.StatusOK, result.StatusCode)
+		}
+		transitionFixture(first.ID.Hex(), fixtures.Finished)
+		transitionFixture(second.ID.Hex(), fixtures.Finished)
+	})
+
+	t.Run("performances can be corrected once the fixture is finished", func(t *testing.T) {
+		// Resubmitting replaces the earlier record.
+		result := submitPerformance(second.ID.Hex(), fixtures.PerformanceRequest{
+			Player: haaland.ID, Team: mct.ID, Minutes: 90, Goals: 2,
+		})
+		assert.Equal(t, http.StatusOK, result.StatusCode)
+	})
+
+	t.Run("performances must be about players and teams in the fixture", func(t *testing.T) {
+		result := submitPerformance(first.ID.Hex(), fixtures.PerformanceRequest{
+			Player: "unknown", Team: "unknown", Minutes: 90,
+		})
+		assert.Equal(t, http.StatusUnprocessableEntity, result.StatusCode)
+	})
+
+	t.Run("anyone can view a player's stats", func(t *testing.T) {
+		req, rec := jsonRequest(http.MethodGet, "/players/"+salah.ID+"/stats", nil, userToken)
+		testApp.app.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		body := web.DataDto{}
+		readJsonResponse(rec.Result().Body, &body)
+		stats := body.Data.(map[string]interface{})
+		assert.Equal(t, 2.0, stats["appearances"])
+		assert.Equal(t, 170.0, stats["minutes"])
+		assert.Equal(t, 2.0, stats["goals"])
+		assert.Equal(t, 1.0, stats["assists"])
+	})
+
+	t.Run("player stats can be limited to a period", func(t *testing.T) {
+		req, rec := jsonRequest(http.MethodGet, "/players/"+salah.ID+"/stats?from=2022-01-01", nil, userToken)
+		testApp.app.ServeHTTP(rec, req)
+		body := web.DataDto{}
+		readJsonResponse(rec.Result().Body, &body)
+		assert.Equal(t, 1.0, body.Data.(map[string]interface{})["goals"])
+	})
+
+	t.Run("leaderboards rank players", func(t *testing.T) {
+		scorers := leaderboard(t, "/stats/top-scorers")
+		assert.Len(t, scorers, 2)
+		top := scorers[0].(map[string]interface{})
+		assert.Equal(t, 1.0, top["position"])
+		assert.Equal(t, "Erling Haaland", top["player"].(map[string]interface{})["name"])
+		assert.Equal(t, 3.0, top["goals"])
+
+		assert.Len(t, leaderboard(t, "/stats/assists"), 1)
+		assert.Len(t, leaderboard(t, "/stats/cards?to=2021-12-31"), 1)
+		assert.Len(t, leaderboard(t, "/stats/cards?from=2022-01-01"), 0)
+		assert.Len(t, leaderboard(t, "/stats/top-scorers?limit=1"), 1)
+	})
+
+	t.Run("only finished fixtures count", func(t *testing.T) {
+		live, _ := testApp.app.FixturesDB.Create(ctx, fixtures.CreateFixtureRequest{
+			HomeTeam:  lvpl.ID,
+			AwayTeam:  mct.ID,
+			MatchDate: time.Date(2022, time.May, 1, 15, 0, 0, 0, time.UTC),
+		})
+		transitionFixture(live.ID.Hex(), fixtures.Live)
+		result := submitPerformance(live.ID.Hex(), fixtures.PerformanceRequest{
+			Player: salah.ID, Team: lvpl.ID, Minutes: 45, Goals: 3,
+		})
+		assert.Equal(t, http.StatusOK, result.StatusCode)
+		top := leaderboard(t, "/stats/top-scorers")[0].(map[string]interface{})
+		assert.Equal(t, "Erling Haaland", top["player"].(map[string]interface{})["name"])
+	})
+
+	t.Run("leaderboards refuse bad queries", func(t *testing.T) {
+		for _, query := range []string{"?limit=0", "?from=yesterday", "?from=2022-01-01&to=2021-01-01"} {
+			req, rec := jsonRequest(http.MethodGet, "/stats/top-scorers"+query, nil, userToken)
+			testApp.app.ServeHTTP(rec, req)
+			assert.Equal(t, http.StatusBadRequest, rec.Code, query)
+		}
+	})
+
+	t.Run("stats of unknown players are not found", func(t *testing.T) {
+		req, rec := jsonRequest(http.MethodGet, "/players/unknown/stats", nil, userToken)
+		testApp.app.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("deleted players don't shorten leaderboards", func(t *testing.T) {
+		assert.NoError(t, testApp.app.PlayersDB.Delete(ctx, haaland.ID))
+		scorers := leaderboard(t, "/stats/top-scorers?limit=1")
+		if assert.Len(t, scorers, 1) {
+			top := scorers[0].(map[string]interface{})
+			assert.Equal(t, "Mohamed Salah", top["player"].(map[string]interface{})["name"])
+		}
+	})
+}
@@ -0,0 +1,136 @@
+package tests
+
+import (
+	"context"
+	"gomoney-mock-epl/players"
+	"gomoney-mock-epl/seasons"
+	"gomoney-mock-epl/web"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func clearPlayers() {
+	testApp.app.PlayersDB.DeleteMany(context.Background(), bson.D{})
+}
+
+func createPlayer(dto players.PlayerRequest) *http.Response {
+	req, rec := jsonRequest(http.MethodPost, "/players/", dto, adminToken)
+	testApp.app.ServeHTTP(rec, req)
+	return rec.Result()
+}
+
+func Test_players_and_squads(t *testing.T) {
+	clearTeamsDB()
+	clearSeasons()
+	clearPlayers()
+
+	ctx := context.Background()
+	lvpl, _ := testApp.app.TeamsDB.Create(ctx, liverpool.ToTeam(""))
+	mct, _ := testApp.app.TeamsDB.Create(ctx, manCity.ToTeam(""))
+	start := time.Date(2026, time.August, 15, 0, 0, 0, 0, time.UTC)
+	season, _ := testApp.app.SeasonsDB.Create(ctx, seasons.SeasonRequest{
+		Name:      "2026/27",
+		StartDate: start,
+		EndDate:   start.AddDate(0, 9, 0),
+		Teams:     []string{lvpl.ID, mct.ID},
+	})
+	salah := players.PlayerRequest{
+		Name:        "Mohamed Salah",
+		Position:    players.Forward,
+		ShirtNumber: 11,
+		Nationality: "Egypt",
+		DateOfBirth: time.Date(1992, time.June, 15, 0, 0, 0, 0, time.UTC),
+		Team:        lvpl.ID,
+		Season:      season.ID,
+	}
+	var salahID string
+
+	t.Run("admins can add players to squads", func(t *testing.T) {
+		result := createPlayer(salah)
+		assert.Equal(t, http.StatusCreated, result.StatusCode)
+		body := web.DataDto{}
+		readJsonResponse(result.Body, &body)
+		assert.Equal(t, "Player", body.Type)
+		player := body.Data.(map[string]interface{})
+		salahID = player["id"].(string)
+		assert.Equal(t, float64(11), player["shirt_number"])
+		assert.Equal(t, lvpl.ID, player["team"])
+	})
+
+	t.Run("only admins can add players", func(t *testing.T) {
+		req, rec := jsonRequest(http.MethodPost, "/players/", salah, userToken)
+		testApp.app.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusForbidden, rec.Result().StatusCode)
+	})
+
+	t.Run("shirt numbers are unique in a squad", func(t *testing.T) {
+		mane := salah
+		mane.Name = "Sadio Mané"
+		result := createPlayer(mane)
+		assert.Equal(t, http.StatusConflict, result.StatusCode)
+
+		mane.ShirtNumber = 10
+		assert.Equal(t, http.StatusCreated, createPlayer(mane).StatusCode)
+		mahrez := salah
+		mahrez.Name = "Riyad Mahrez"
+		mahrez.Nationality = "Algeria"
+		mahrez.Team = mct.ID
+		mahrez.ShirtNumber = 26
+		assert.Equal(t, http.StatusCreated, createPlayer(mahrez).StatusCode)
+	})
+
+	t.Run("players must be in a team that plays in the season", func(t *testing.T) {
+		player := salah
+		player.Team = "nobody"
+		assert.Equal(t, http.StatusUnprocessableEntity, createPlayer(player).StatusCode)
+	})
+
+	t.Run("a team's squad is listed by shirt number", func(t *testing.T) {
+		req, rec := jsonRequest(http.MethodGet, "/teams/"+lvpl.ID+"/squad?season="+season.ID, nil, userToken)
+		testApp.app.ServeHTTP(rec, req)
+		result := rec.Result()
+		assert.Equal(t, http.StatusOK, result.StatusCode)
+		body := web.DataDto{}
+		readJsonResponse(result.Body, &body)
+		squad := body.Data.([]interface{})
+		assert.Len(t, squad, 2)
+		assert.Equal(t, "Sadio Mané", squad[0].(map[string]interface{})["name"])
+	})
+
+	t.Run("admins can release players", func(t *testing.T) {
+		req, rec := jsonRequest(http.MethodPatch, "/players/"+salahID, map[string]interface{}{
+			"team": "", "season": "", "shirt_number": 0,
+		}, adminToken)
+		testApp.app.ServeHTTP(rec, req)
+		result := rec.Result()
+		assert.Equal(t, http.StatusOK, result.StatusCode)
+		body := web.DataDto{}
+		readJsonResponse(result.Body, &body)
+		player := body.Data.(map[string]interface{})
+		assert.Nil(t, player["team"])
+		assert.Equal(t, "Mohamed Salah", player["name"])
+	})
+
+	t.Run("players can be searched for", func(t *testing.T) {
+		req, rec := jsonRequest(http.MethodGet, "/search?q=salah", nil, "")
+		testApp.app.ServeHTTP(rec, req)
+		body := web.DataDto{}
+		readJsonResponse(rec.Result().Body, &body)
+		found := body.Data.(map[string]interface{})["players"].([]interface{})
+		assert.Len(t, found, 1)
+		assert.Equal(t, salahID, found[0].(map[string]interface{})["id"])
+	})
+
+	t.Run("admins can remove players", func(t *testing.T) {
+		req, rec := jsonRequest(http.MethodDelete, "/players/"+salahID, nil, adminToken)
+		testApp.app.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusOK, rec.Result().StatusCode)
+		req, rec = jsonRequest(http.MethodGet, "/players/"+salahID, nil, userToken)
+		testApp.app.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusNotFound, rec.Result().StatusCode)
+	})
+}
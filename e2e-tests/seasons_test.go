@@ -0,0 +1,170 @@
+package tests
+
+import (
+	"context"
+	"gomoney-mock-epl/fixtures"
+	"gomoney-mock-epl/seasons"
+	"gomoney-mock-epl/web"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func clearSeasons() {
+	testApp.app.SeasonsDB.DeleteMany(context.Background(), bson.D{})
+}
+
+func Test_seasons_and_matchweeks(t *testing.T) {
+	clearTeamsDB()
+	clearFixtures()
+	clearSeasons()
+
+	ctx := context.Background()
+	lvpl, _ := testApp.app.TeamsDB.Create(ctx, liverpool.ToTeam(""))
+	mct, _ := testApp.app.TeamsDB.Create(ctx, manCity.ToTeam(""))
+	mutd, _ := testApp.app.TeamsDB.Create(ctx, manUtd.ToTeam(""))
+	start := time.Now().Truncate(time.Hour)
+	seasonDto := seasons.SeasonRequest{
+		Name:      "2026/27",
+		StartDate: start,
+		EndDate:   start.AddDate(0, 9, 0),
+		Teams:     []string{lvpl.ID, mct.ID},
+	}
+	var seasonID string
+
+	t.Run("admins can create seasons", func(t *testing.T) {
+		req, rec := jsonRequest(http.MethodPost, "/seasons/", seasonDto, adminToken)
+		testApp.app.ServeHTTP(rec, req)
+		result := rec.Result()
+		assert.Equal(t, http.StatusCreated, result.StatusCode)
+		body := web.DataDto{}
+		readJsonResponse(result.Body, &body)
+		assert.Equal(t, "Season", body.Type)
+		seasonID = body.Data.(map[string]interface{})["id"].(string)
+	})
+
+	t.Run("season names are unique", func(t *testing.T) {
+		req, rec := jsonRequest(http.MethodPost, "/seasons/", seasonDto, adminToken)
+		testApp.app.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusConflict, rec.Result().StatusCode)
+	})
+
+	t.Run("fixtures must fit in their season", func(t *testing.T) {
+		result := createFixture(fixtures.CreateFixtureRequest{
+			HomeTeam:  mutd.ID,
+			AwayTeam:  lvpl.ID,
+			MatchDate: start.AddDate(1, 0, 0),
+			Season:    seasonID,
+			Matchweek: 1,
+		})
+		assert.Equal(t, http.StatusUnprocessableEntity, result.StatusCode)
+	})
+
+	t.Run("users can list the fixtures in a matchweek", func(t *testing.T) {
+		for matchweek := 1; matchweek <= 2; matchweek++ {
+			result := createFixture(fixtures.CreateFixtureRequest{
+				HomeTeam:  lvpl.ID,
+				AwayTeam:  mct.ID,
+				MatchDate: start.AddDate(0, 0, 7*matchweek),
+				Season:    seasonID,
+				Matchweek: matchweek,
+			})
+			assert.Equal(t, http.StatusCreated, result.StatusCode)
+		}
+
+		req, rec := jsonRequest(http.MethodGet, "/seasons/"+seasonID+"/fixtures?matchweek=2", nil, userToken)
+		testApp.app.ServeHTTP(rec, req)
+		result := rec.Result()
+		assert.Equal(t, http.StatusOK, result.StatusCode)
+		body := web.DataDto{}
+		readJsonResponse(result.Body, &body)
+		data := body.Data.([]interface{})
+		assert.Len(t, data, 1)
+		assert.Equal(t, float64(2), data[0].(map[string]interface{})["matchweek"])
+	})
+
+	t.Run("admins can edit seasons", func(t *testing.T) {
+		update := map[string]interface{}{"teams": []string{lvpl.ID, mct.ID, mutd.ID}}
+		req, rec := jsonRequest(http.MethodPatch, "/seasons/"+seasonID, update, adminToken)
+		testApp.app.ServeHTTP(rec, req)
+		result := rec.Result()
+		assert.Equal(t, http.StatusOK, result.StatusCode)
+		body := web.DataDto{}
+		readJsonResponse(result.Body, &body)
+		assert.Len(t, body.Data.(map[string]interface{})["teams"], 3)
+		assert.Equal(t, seasonDto.Name, body.Data.(map[string]interface{})["name"])
+	})
+
+	t.Run("seasons must still fit their fixtures", func(t *testing.T) {
+		for _, update := range []map[string]interface{}{
+			{"teams": []string{lvpl.ID, mutd.ID}},
+			{"end_date": start.AddDate(0, 0, 10)},
+			{"start_date": start.AddDate(0, 0, 10)},
+		} {
+			req, rec := jsonRequest(http.MethodPatch, "/seasons/"+seasonID, update, adminToken)
+			testApp.app.ServeHTTP(rec, req)
+			assert.Equal(t, http.StatusUnprocessableEntity, rec.Result().StatusCode, "%v", update)
+		}
+
+		season, err := testApp.app.SeasonsDB.ByID(ctx, seasonID)
+		assert.NoError(t, err)
+		assert.Len(t, season.Teams, 3)
+	})
+
+	t.Run("seasons with fixtures can't be deleted", func(t *testing.T) {
+		req, rec := jsonRequest(http.MethodDelete, "/seasons/"+seasonID, nil, adminToken)
+		testApp.app.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusConflict, rec.Result().StatusCode)
+	})
+
+	t.Run("admins can delete seasons", func(t *testing.T) {
+		clearFixtures()
+		req, rec := jsonRequest(http.MethodDelete, "/seasons/"+seasonID, nil, adminToken)
+		testApp.app.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusOK, rec.Result().StatusCode)
+
+		req, rec = jsonRequest(http.MethodGet, "/seasons/"+seasonID, nil, adminToken)
+		testApp.app.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusNotFound, rec.Result().StatusCode)
+	})
+}
+
+func Test_scheduling_a_season(t *testing.T) {
+	clearTeamsDB()
+	clearFixtures()
+	clearSeasons()
+
+	ctx := context.Background()
+	teamIDs := []string{}
+	for _, team := range []web.CreateTeamRequest{liverpool, manCity, manUtd} {
+		created, _ := testApp.app.TeamsDB.Create(ctx, team.ToTeam(""))
+		teamIDs = append(teamIDs, created.ID)
+	}
+	start := time.Now().Truncate(24 * time.Hour)
+	season, err := testApp.app.SeasonsDB.Create(ctx, seasons.SeasonRequest{
+		Name:      "Mini league",
+		StartDate: start,
+		EndDate:   start.AddDate(0, 3, 0),
+		Teams:     teamIDs,
+	})
+	assert.NoError(t, err)
+
+	t.Run("admins can generate a season's fixtures", func(t *testing.T) {
+		req, rec := jsonRequest(http.MethodPost, "/seasons/"+season.ID+"/schedule", map[string]interface{}{"seed": 1}, adminToken)
+		testApp.app.ServeHTTP(rec, req)
+		result := rec.Result()
+		assert.Equal(t, http.StatusCreated, result.StatusCode)
+		body := web.DataDto{}
+		readJsonResponse(result.Body, &body)
+		assert.Len(t, body.Data.([]interface{}), 6)
+	})
+
+	t.Run("seasons can only be scheduled once", func(t *testing.T) {
+		req, rec := jsonRequest(http.MethodPost, "/seasons/"+season.ID+"/schedule", nil, adminToken)
+		testApp.app.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusConflict, rec.Result().StatusCode)
+	})
+}
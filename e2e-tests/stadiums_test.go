@@ -0,0 +1,185 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"gomoney-mock-epl/fixtures"
+	"gomoney-mock-epl/stadiums"
+	"gomoney-mock-epl/web"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func clearStadiums() {
+	testApp.app.StadiumsDB.DeleteMany(context.Background(), bson.D{})
+}
+
+func coordinate(f float64) *float64 {
+	return &f
+}
+
+func createStadium(dto stadiums.StadiumRequest) *http.Response {
+	req, rec := jsonRequest(http.MethodPost, "/stadiums/", dto, adminToken)
+	testApp.app.ServeHTTP(rec, req)
+	return rec.Result()
+}
+
+func Test_stadiums_and_fixtures_nearby(t *testing.T) {
+	clearTeamsDB()
+	clearFixtures()
+	clearStadiums()
+
+	ctx := context.Background()
+	anfield, _ := testApp.app.StadiumsDB.Create(ctx, stadiums.StadiumRequest{
+		Name: "Anfield", City: "Liverpool", Capacity: 61276,
+		Latitude: coordinate(53.4308), Longitude: coordinate(-2.96096),
+	})
+	etihad, _ := testApp.app.StadiumsDB.Create(ctx, stadiums.StadiumRequest{
+		Name: "Etihad Stadium", City: "Manchester", Capacity: 53400,
+		Latitude: coordinate(53.4831), Longitude: coordinate(-2.20041),
+	})
+	wembley, _ := testApp.app.StadiumsDB.Create(ctx, stadiums.StadiumRequest{
+		Name: "Wembley Stadium", City: "London", Capacity: 90000,
+		Latitude: coordinate(51.556), Longitude: coordinate(-0.2796),
+	})
+
+	t.Run("only admins can add stadiums", func(t *testing.T) {
+		req, rec := jsonRequest(http.MethodPost, "/stadiums/", stadiums.StadiumRequest{Name: "Goodison Park"}, userToken)
+		testApp.app.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+
+		result := createStadium(stadiums.StadiumRequest{Name: "Goodison Park", Latitude: coordinate(53.4388)})
+		assert.Equal(t, http.StatusUnprocessableEntity, result.StatusCode)
+	})
+
+	var lvplID, mctID string
+	t.Run("teams take their home stadium from the stadium they are linked to", func(t *testing.T) {
+		dto := liverpool
+		dto.Stadium = anfield.ID
+		dto.HomeStadium = "Somewhere else"
+		rec := createTeam(dto)
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		body := web.DataDto{}
+		readJsonResponse(rec.Result().Body, &body)
+		team := body.Data.(map[string]interface{})
+		lvplID = team["id"].(string)
+		assert.Equal(t, "Anfield", team["home_stadium"])
+		assert.Equal(t, anfield.ID, team["stadium"])
+
+		dto = manCity
+		dto.Stadium = etihad.ID
+		rec = createTeam(dto)
+		body = web.DataDto{}
+		readJsonResponse(rec.Result().Body, &body)
+		mctID = body.Data.(map[string]interface{})["id"].(string)
+
+		dto = manUtd
+		dto.Stadium = "unknown"
+		assert.Equal(t, http.StatusUnprocessableEntity, createTeam(dto).Code)
+	})
+
+	t.Run("teams follow changes to their stadium", func(t *testing.T) {
+		renamed := stadiums.StadiumRequest{}
+		renamed.FromStadium(*anfield)
+		renamed.Name = "Anfield Road"
+		_, err := testApp.app.StadiumsDB.Update(ctx, anfield.ID, renamed)
+		assert.NoError(t, err)
+		team, err := testApp.app.TeamsDB.ByID(ctx, lvplID)
+		assert.NoError(t, err)
+		assert.Equal(t, "Anfield Road", team.HomeStadium)
+		assert.Equal(t, "Liverpool", team.City)
+
+		renamed.Name = "Anfield"
+		testApp.app.StadiumsDB.Update(ctx, anfield.ID, renamed)
+	})
+
+	now := time.Now()
+	home, _ := testApp.app.FixturesDB.Create(ctx, fixtures.CreateFixtureRequest{
+		HomeTeam: lvplID, AwayTeam: mctID, MatchDate: now.Add(48 * time.Hour),
+	})
+	neutral, _ := testApp.app.FixturesDB.Create(ctx, fixtures.CreateFixtureRequest{
+		HomeTeam: mctID, AwayTeam: lvplID, MatchDate: now.Add(24 * time.Hour), Venue: &wembley.ID,
+	})
+
+	t.Run("fixtures can only be moved to known stadiums", func(t *testing.T) {
+		unknown := "unknown"
+		result := createFixture(fixtures.CreateFixtureRequest{
+			HomeTeam: mctID, AwayTeam: lvplID, MatchDate: now.Add(72 * time.Hour), Venue: &unknown,
+		})
+		assert.Equal(t, http.StatusUnprocessableEntity, result.StatusCode)
+	})
+
+	nearby := func(t *testing.T, query string) []interface{} {
+		req, rec := jsonRequest(http.MethodGet, "/fixtures/near?"+query, nil, userToken)
+		testApp.app.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		body := web.DataDto{}
+		readJsonResponse(rec.Result().Body, &body)
+		return body.Data.([]interface{})
+	}
+
+	t.Run("fixtures are found near their home team's stadium", func(t *testing.T) {
+		// Liverpool city centre.
+		found := nearby(t, "lat=53.4084&lng=-2.9916&radius=10")
+		assert.Len(t, found, 1)
+		fixture := found[0].(map[string]interface{})
+		assert.Equal(t, home.ID.Hex(), fixture["id"])
+		assert.Equal(t, "Anfield", fixture["stadium"].(map[string]interface{})["name"])
+		assert.InDelta(t, 3.5, fixture["distance_km"], 1)
+	})
+
+	t.Run("fixtures moved to a neutral ground are found near it", func(t *testing.T) {
+		// Manchester city centre: the Etihad fixture was moved to Wembley.
+		assert.Len(t, nearby(t, "lat=53.4808&lng=-2.2426&radius=10"), 0)
+
+		found := nearby(t, "lat=51.5072&lng=-0.1276&radius=20")
+		assert.Len(t, found, 1)
+		assert.Equal(t, neutral.ID.Hex(), found[0].(map[string]interface{})["id"])
+
+		found = nearby(t, fmt.Sprintf("lat=%f&lng=%f&radius=500", 52.5, -1.9))
+		assert.Len(t, found, 2)
+		assert.Equal(t, neutral.ID.Hex(), found[0].(map[string]interface{})["id"], "soonest first")
+	})
+
+	t.Run("fixtures moved to a neutral ground can be moved back", func(t *testing.T) {
+		moved, _ := testApp.app.FixturesDB.Create(ctx, fixtures.CreateFixtureRequest{
+			HomeTeam: lvplID, AwayTeam: mctID, MatchDate: now.Add(96 * time.Hour), Venue: &wembley.ID,
+		})
+		path := "/fixtures/" + moved.ID.Hex()
+		req, rec := jsonRequest(http.MethodPatch, path, map[string]interface{}{"match_date": now.Add(97 * time.Hour)}, adminToken)
+		testApp.app.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		fixture, _ := testApp.app.FixturesDB.ByID(ctx, moved.ID)
+		assert.Equal(t, wembley.ID, fixture.Venue)
+
+		req, rec = jsonRequest(http.MethodPatch, path, map[string]interface{}{"venue": nil}, adminToken)
+		testApp.app.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		fixture, _ = testApp.app.FixturesDB.ByID(ctx, moved.ID)
+		assert.Empty(t, fixture.Venue)
+		testApp.app.FixturesDB.Delete(ctx, moved.ID)
+	})
+
+	t.Run("nearby fixtures need a valid location", func(t *testing.T) {
+		for _, query := range []string{"", "lat=53.4", "lat=91&lng=0", "lat=53.4&lng=-2.9&radius=1000"} {
+			req, rec := jsonRequest(http.MethodGet, "/fixtures/near?"+query, nil, userToken)
+			testApp.app.ServeHTTP(rec, req)
+			assert.Equal(t, http.StatusBadRequest, rec.Code, query)
+		}
+	})
+
+	t.Run("stadiums in use can't be deleted", func(t *testing.T) {
+		req, rec := jsonRequest(http.MethodDelete, "/stadiums/"+wembley.ID, nil, adminToken)
+		testApp.app.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusConflict, rec.Code)
+
+		unused, _ := testApp.app.StadiumsDB.Create(ctx, stadiums.StadiumRequest{Name: "Maine Road"})
+		req, rec = jsonRequest(http.MethodDelete, "/stadiums/"+unused.ID, nil, adminToken)
+		testApp.app.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+}
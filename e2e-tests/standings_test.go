@@ -0,0 +1,60 @@
+package tests
+
+import (
+	"context"
+	"gomoney-mock-epl/fixtures"
+	"gomoney-mock-epl/web"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func finishFixture(t *testing.T, fixture *fixtures.Fixture, score fixtures.Score) {
+	ctx := context.Background()
+	_, err := testApp.app.FixturesDB.Transition(ctx, fixture.ID, fixtures.TransitionRequest{Status: string(fixtures.Live)})
+	assert.NoError(t, err)
+	_, err = testApp.app.FixturesDB.Transition(ctx, fixture.ID, fixtures.TransitionRequest{Status: string(fixtures.Finished)})
+	assert.NoError(t, err)
+	_, err = testApp.app.FixturesDB.RecordResult(ctx, fixture.ID, fixtures.RecordResultRequest{FullTime: score})
+	assert.NoError(t, err)
+}
+
+func Test_standings_are_computed_from_finished_fixtures(t *testing.T) {
+	clearTeamsDB()
+	clearFixtures()
+
+	ctx := context.Background()
+	lvpl, _ := testApp.app.TeamsDB.Create(ctx, liverpool.ToTeam(""))
+	mct, _ := testApp.app.TeamsDB.Create(ctx, manCity.ToTeam(""))
+	mutd, _ := testApp.app.TeamsDB.Create(ctx, manUtd.ToTeam(""))
+	played, _ := testApp.app.FixturesDB.Create(ctx, fixtures.CreateFixtureRequest{
+		HomeTeam:  lvpl.ID,
+		AwayTeam:  mct.ID,
+		MatchDate: time.Now().Add(-3 * time.Hour),
+	})
+	finishFixture(t, played, fixtures.Score{Home: 2, Away: 0})
+	abandoned, _ := testApp.app.FixturesDB.Create(ctx, fixtures.CreateFixtureRequest{
+		HomeTeam:  mutd.ID,
+		AwayTeam:  lvpl.ID,
+		MatchDate: time.Now().Add(-3 * time.Hour),
+	})
+	testApp.app.FixturesDB.Transition(ctx, abandoned.ID, fixtures.TransitionRequest{Status: string(fixtures.Live)})
+	testApp.app.FixturesDB.Transition(ctx, abandoned.ID, fixtures.TransitionRequest{Status: string(fixtures.Abandoned)})
+
+	req, rec := jsonRequest(http.MethodGet, "/standings", nil, userToken)
+	testApp.app.ServeHTTP(rec, req)
+	result := rec.Result()
+	assert.Equal(t, http.StatusOK, result.StatusCode)
+	body := web.DataDto{}
+	readJsonResponse(result.Body, &body)
+	assert.Equal(t, "Standings", body.Type)
+	table := body.Data.([]interface{})
+	assert.Len(t, table, 3)
+	leader := table[0].(map[string]interface{})
+	assert.Equal(t, lvpl.ID, leader["team"].(map[string]interface{})["id"])
+	assert.Equal(t, float64(1), leader["played"])
+	assert.Equal(t, float64(3), leader["points"])
+	assert.Equal(t, float64(2), leader["goal_difference"])
+}
@@ -0,0 +1,106 @@
+package tests
+
+import (
+	"context"
+	"gomoney-mock-epl/fixtures"
+	"gomoney-mock-epl/live"
+	"gomoney-mock-epl/web"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/websocket"
+)
+
+type subscriptionRequest struct {
+	Action string   `json:"action"`
+	Topics []string `json:"topics"`
+}
+
+func Test_subscribing_to_fixtures_over_websockets(t *testing.T) {
+	clearTeamsDB()
+	clearFixtures()
+
+	server := httptest.NewServer(testApp.app)
+	defer server.Close()
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/ws"
+
+	ctx := context.Background()
+	lvpl, _ := testApp.app.TeamsDB.Create(ctx, liverpool.ToTeam(""))
+	mct, _ := testApp.app.TeamsDB.Create(ctx, manCity.ToTeam(""))
+	mun, _ := testApp.app.TeamsDB.Create(ctx, manUtd.ToTeam(""))
+	today, _ := testApp.app.FixturesDB.Create(ctx, fixtures.CreateFixtureRequest{
+		HomeTeam:  lvpl.ID,
+		AwayTeam:  mct.ID,
+		MatchDate: time.Now(),
+	})
+	later, _ := testApp.app.FixturesDB.Create(ctx, fixtures.CreateFixtureRequest{
+		HomeTeam:  mun.ID,
+		AwayTeam:  lvpl.ID,
+		MatchDate: time.Now().Add(-72 * time.Hour),
+	})
+
+	t.Run("connections require authentication", func(t *testing.T) {
+		_, err := websocket.Dial(url, "", server.URL)
+		assert.Error(t, err)
+	})
+
+	ws, err := websocket.Dial(url+"?token="+userToken, "", server.URL)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer ws.Close()
+	receive := func() web.DataDto {
+		ws.SetReadDeadline(time.Now().Add(5 * time.Second))
+		message := web.DataDto{}
+		assert.NoError(t, websocket.JSON.Receive(ws, &message))
+		return message
+	}
+
+	t.Run("clients can subscribe to fixtures, teams and today's fixtures", func(t *testing.T) {
+		websocket.JSON.Send(ws, subscriptionRequest{
+			Action: "subscribe",
+			Topics: []string{live.TeamTopic(mun.ID), live.TodaysFixtures},
+		})
+		message := receive()
+		assert.Equal(t, "Subscription", message.Type)
+		assert.ElementsMatch(t, []interface{}{live.TeamTopic(mun.ID), live.TodaysFixtures},
+			message.Data.(map[string]interface{})["topics"])
+	})
+
+	t.Run("changes to the topics followed are sent in the API envelope", func(t *testing.T) {
+		transitionFixture(later.ID.Hex(), fixtures.Live)
+		message := receive()
+		assert.Equal(t, "FixtureChange", message.Type)
+		change := message.Data.(map[string]interface{})
+		assert.Equal(t, "status_changed", change["type"])
+		assert.Equal(t, later.ID.Hex(), change["fixture"].(map[string]interface{})["id"])
+
+		transitionFixture(today.ID.Hex(), fixtures.Live)
+		change = receive().Data.(map[string]interface{})
+		assert.Equal(t, today.ID.Hex(), change["fixture"].(map[string]interface{})["id"])
+	})
+
+	t.Run("clients can unsubscribe", func(t *testing.T) {
+		websocket.JSON.Send(ws, subscriptionRequest{
+			Action: "unsubscribe",
+			Topics: []string{live.TodaysFixtures},
+		})
+		message := receive()
+		assert.Equal(t, []interface{}{live.TeamTopic(mun.ID)}, message.Data.(map[string]interface{})["topics"])
+
+		transitionFixture(today.ID.Hex(), fixtures.HalfTime)
+		transitionFixture(later.ID.Hex(), fixtures.HalfTime)
+		change := receive().Data.(map[string]interface{})
+		assert.Equal(t, later.ID.Hex(), change["fixture"].(map[string]interface{})["id"])
+	})
+
+	t.Run("unknown topics are refused", func(t *testing.T) {
+		websocket.JSON.Send(ws, subscriptionRequest{Action: "subscribe", Topics: []string{"teams"}})
+		message := receive()
+		assert.Equal(t, "Error", message.Type)
+		assert.Equal(t, "subscriptions/invalid-topic", message.Data.(map[string]interface{})["code"])
+	})
+}
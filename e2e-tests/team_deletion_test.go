@@ -0,0 +1,117 @@
+package tests
+
+import (
+	"context"
+	"gomoney-mock-epl/fixtures"
+	"gomoney-mock-epl/seasons"
+	"gomoney-mock-epl/web"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func deleteTeam(teamID, query string) (*http.Response, map[string]interface{}) {
+	req, rec := jsonRequest(http.MethodDelete, "/teams/"+teamID+query, nil, adminToken)
+	testApp.app.ServeHTTP(rec, req)
+	body := map[string]interface{}{}
+	readJsonResponse(rec.Result().Body, &body)
+	return rec.Result(), body
+}
+
+func Test_deleting_teams_with_fixtures(t *testing.T) {
+	clearTeamsDB()
+	clearFixtures()
+	clearSeasons()
+
+	ctx := context.Background()
+	lvpl, _ := testApp.app.TeamsDB.Create(ctx, liverpool.ToTeam(""))
+	mct, _ := testApp.app.TeamsDB.Create(ctx, manCity.ToTeam(""))
+	mutd, _ := testApp.app.TeamsDB.Create(ctx, manUtd.ToTeam(""))
+	played, _ := testApp.app.FixturesDB.Create(ctx, fixtures.CreateFixtureRequest{
+		HomeTeam:  lvpl.ID,
+		AwayTeam:  mct.ID,
+		MatchDate: time.Date(2030, 8, 14, 15, 0, 0, 0, time.UTC),
+	})
+	testApp.app.FixturesDB.Create(ctx, fixtures.CreateFixtureRequest{
+		HomeTeam:  mutd.ID,
+		AwayTeam:  mct.ID,
+		MatchDate: time.Date(2030, 8, 21, 15, 0, 0, 0, time.UTC),
+	})
+
+	t.Run("teams in fixtures are not deleted by default", func(t *testing.T) {
+		result, body := deleteTeam(lvpl.ID, "")
+		assert.Equal(t, http.StatusConflict, result.StatusCode)
+		assert.Equal(t, "teams/in-use", body["code"])
+		assert.Equal(t, []interface{}{played.ID.Hex()}, body["fixtures"])
+		assert.Equal(t, float64(1), body["references"].(map[string]interface{})["fixtures"])
+		team, _ := testApp.app.TeamsDB.ByID(ctx, lvpl.ID)
+		assert.NotNil(t, team)
+
+		result, _ = deleteTeam(lvpl.ID, "?policy=sometimes")
+		assert.Equal(t, http.StatusBadRequest, result.StatusCode)
+	})
+
+	t.Run("teams in seasons are not deleted, even with their fixtures", func(t *testing.T) {
+		season, err := testApp.app.SeasonsDB.Create(ctx, seasons.SeasonRequest{
+			Name:      "2030/31",
+			StartDate: time.Date(2030, 8, 1, 0, 0, 0, 0, time.UTC),
+			EndDate:   time.Date(2031, 5, 31, 0, 0, 0, 0, time.UTC),
+			Teams:     []string{mutd.ID, mct.ID},
+		})
+		assert.NoError(t, err)
+		result, body := deleteTeam(mutd.ID, "?policy=cascade")
+		assert.Equal(t, http.StatusConflict, result.StatusCode)
+		assert.Equal(t, float64(1), body["references"].(map[string]interface{})["seasons"])
+		count, _ := testApp.app.FixturesDB.CountByTeam(ctx, mutd.ID)
+		assert.EqualValues(t, 1, count, "the fixtures are kept")
+		assert.NoError(t, testApp.app.SeasonsDB.Delete(ctx, season.ID))
+	})
+
+	t.Run("unknown teams are not found", func(t *testing.T) {
+		result, _ := deleteTeam("unknown", "")
+		assert.Equal(t, http.StatusNotFound, result.StatusCode)
+		result, _ = deleteTeam("unknown", "?policy=cascade")
+		assert.Equal(t, http.StatusNotFound, result.StatusCode)
+	})
+
+	t.Run("archived teams keep their fixtures", func(t *testing.T) {
+		result, body := deleteTeam(lvpl.ID, "?policy=archive")
+		assert.Equal(t, http.StatusOK, result.StatusCode)
+		assert.NotEmpty(t, body["data"].(map[string]interface{})["archived_at"])
+
+		fixture, err := testApp.app.FixturesDB.ByID(ctx, played.ID)
+		assert.NoError(t, err)
+		if assert.NotNil(t, fixture) {
+			assert.Equal(t, lvpl.ID, fixture.HomeTeam.ID)
+		}
+
+		req, rec := jsonRequest(http.MethodGet, "/teams/", nil, adminToken)
+		testApp.app.ServeHTTP(rec, req)
+		listed := web.DataDto{}
+		readJsonResponse(rec.Result().Body, &listed)
+		assert.Len(t, listed.Data, 2)
+		req, rec = jsonRequest(http.MethodGet, "/teams/?archived=true", nil, adminToken)
+		testApp.app.ServeHTTP(rec, req)
+		readJsonResponse(rec.Result().Body, &listed)
+		assert.Len(t, listed.Data, 1)
+
+		_, err = testApp.app.FixturesDB.Create(ctx, fixtures.CreateFixtureRequest{
+			HomeTeam:  mutd.ID,
+			AwayTeam:  lvpl.ID,
+			MatchDate: time.Date(2030, 9, 4, 15, 0, 0, 0, time.UTC),
+		})
+		assert.Error(t, err)
+	})
+
+	t.Run("cascades delete the team's fixtures", func(t *testing.T) {
+		result, _ := deleteTeam(mutd.ID, "?policy=cascade")
+		assert.Equal(t, http.StatusOK, result.StatusCode)
+		team, _ := testApp.app.TeamsDB.ByID(ctx, mutd.ID)
+		assert.Nil(t, team)
+		count, _ := testApp.app.FixturesDB.Collection.CountDocuments(ctx, bson.D{})
+		assert.EqualValues(t, 1, count)
+	})
+}
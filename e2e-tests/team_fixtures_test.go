@@ -0,0 +1,108 @@
+package tests
+
+import (
+	"context"
+	"gomoney-mock-epl/fixtures"
+	"gomoney-mock-epl/web"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func getTeamFixtures(path string) (*http.Response, web.DataDto) {
+	req, rec := jsonRequest(http.MethodGet, path, nil, userToken)
+	testApp.app.ServeHTTP(rec, req)
+	body := web.DataDto{}
+	readJsonResponse(rec.Result().Body, &body)
+	return rec.Result(), body
+}
+
+func fixtureIDs(data interface{}) []string {
+	ids := []string{}
+	for _, fixture := range data.([]interface{}) {
+		ids = append(ids, fixture.(map[string]interface{})["id"].(string))
+	}
+	return ids
+}
+
+func Test_team_fixtures(t *testing.T) {
+	clearTeamsDB()
+	clearFixtures()
+
+	ctx := context.Background()
+	lvpl, _ := testApp.app.TeamsDB.Create(ctx, liverpool.ToTeam(""))
+	mct, _ := testApp.app.TeamsDB.Create(ctx, manCity.ToTeam(""))
+	mutd, _ := testApp.app.TeamsDB.Create(ctx, manUtd.ToTeam(""))
+	won, _ := testApp.app.FixturesDB.Create(ctx, fixtures.CreateFixtureRequest{
+		HomeTeam:  lvpl.ID,
+		AwayTeam:  mct.ID,
+		MatchDate: time.Now().Add(-14 * 24 * time.Hour),
+	})
+	finishFixture(t, won, fixtures.Score{Home: 2, Away: 0})
+	lost, _ := testApp.app.FixturesDB.Create(ctx, fixtures.CreateFixtureRequest{
+		HomeTeam:  mutd.ID,
+		AwayTeam:  lvpl.ID,
+		MatchDate: time.Now().Add(-7 * 24 * time.Hour),
+	})
+	finishFixture(t, lost, fixtures.Score{Home: 1, Away: 0})
+	upcoming, _ := testApp.app.FixturesDB.Create(ctx, fixtures.CreateFixtureRequest{
+		HomeTeam:  lvpl.ID,
+		AwayTeam:  mutd.ID,
+		MatchDate: time.Now().Add(7 * 24 * time.Hour),
+	})
+	later, _ := testApp.app.FixturesDB.Create(ctx, fixtures.CreateFixtureRequest{
+		HomeTeam:  mct.ID,
+		AwayTeam:  lvpl.ID,
+		MatchDate: time.Now().Add(14 * 24 * time.Hour),
+	})
+	testApp.app.FixturesDB.Create(ctx, fixtures.CreateFixtureRequest{
+		HomeTeam:  mct.ID,
+		AwayTeam:  mutd.ID,
+		MatchDate: time.Now().Add(21 * 24 * time.Hour),
+	})
+
+	t.Run("all of a team's fixtures are listed", func(t *testing.T) {
+		result, body := getTeamFixtures("/teams/" + lvpl.ID + "/fixtures")
+		assert.Equal(t, http.StatusOK, result.StatusCode)
+		assert.Equal(t, "Fixtures", body.Type)
+		assert.Equal(t, []string{won.ID.Hex(), lost.ID.Hex(), upcoming.ID.Hex(), later.ID.Hex()},
+			fixtureIDs(body.Data))
+		assert.NotNil(t, body.Pagination)
+	})
+
+	t.Run("a team's fixtures can be filtered by venue", func(t *testing.T) {
+		_, body := getTeamFixtures("/teams/" + lvpl.ID + "/fixtures?venue=away")
+		assert.Equal(t, []string{lost.ID.Hex(), later.ID.Hex()}, fixtureIDs(body.Data))
+	})
+
+	t.Run("a team's results are listed latest first", func(t *testing.T) {
+		abandoned, _ := testApp.app.FixturesDB.Create(ctx, fixtures.CreateFixtureRequest{
+			HomeTeam:  mutd.ID,
+			AwayTeam:  lvpl.ID,
+			MatchDate: time.Now().Add(-time.Hour),
+		})
+		testApp.app.FixturesDB.Transition(ctx, abandoned.ID, fixtures.TransitionRequest{Status: string(fixtures.Live)})
+		testApp.app.FixturesDB.AddEvent(ctx, abandoned.ID, fixtures.EventRequest{Type: fixtures.Goal, Team: lvpl.ID, Minute: 10})
+		testApp.app.FixturesDB.Transition(ctx, abandoned.ID, fixtures.TransitionRequest{Status: string(fixtures.Abandoned)})
+
+		result, body := getTeamFixtures("/teams/" + lvpl.ID + "/results")
+		assert.Equal(t, http.StatusOK, result.StatusCode)
+		assert.Equal(t, []string{lost.ID.Hex(), won.ID.Hex()}, fixtureIDs(body.Data))
+	})
+
+	t.Run("a team's next fixture is the earliest upcoming one", func(t *testing.T) {
+		result, body := getTeamFixtures("/teams/" + lvpl.ID + "/next")
+		assert.Equal(t, http.StatusOK, result.StatusCode)
+		assert.Equal(t, "Fixture", body.Type)
+		assert.Equal(t, upcoming.ID.Hex(), body.Data.(map[string]interface{})["id"])
+	})
+
+	t.Run("unknown teams are not found", func(t *testing.T) {
+		for _, path := range []string{"fixtures", "results", "next"} {
+			result, _ := getTeamFixtures("/teams/nobody/" + path)
+			assert.Equal(t, http.StatusNotFound, result.StatusCode)
+		}
+	})
+}
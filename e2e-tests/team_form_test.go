@@ -0,0 +1,99 @@
+package tests
+
+import (
+	"context"
+	"gomoney-mock-epl/fixtures"
+	"gomoney-mock-epl/web"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func getTeamForm(teamID, query string) (*http.Response, map[string]interface{}) {
+	req, rec := jsonRequest(http.MethodGet, "/teams/"+teamID+"/form"+query, nil, userToken)
+	testApp.app.ServeHTTP(rec, req)
+	body := web.DataDto{}
+	readJsonResponse(rec.Result().Body, &body)
+	form, _ := body.Data.(map[string]interface{})
+	return rec.Result(), form
+}
+
+func formOutcomes(form map[string]interface{}) []string {
+	outcomes := []string{}
+	for _, result := range form["results"].([]interface{}) {
+		outcomes = append(outcomes, result.(map[string]interface{})["outcome"].(string))
+	}
+	return outcomes
+}
+
+func Test_team_form(t *testing.T) {
+	clearTeamsDB()
+	clearFixtures()
+
+	ctx := context.Background()
+	lvpl, _ := testApp.app.TeamsDB.Create(ctx, liverpool.ToTeam(""))
+	mct, _ := testApp.app.TeamsDB.Create(ctx, manCity.ToTeam(""))
+	play := func(home, away string, daysAgo int, score fixtures.Score) *fixtures.Fixture {
+		fixture, _ := testApp.app.FixturesDB.Create(ctx, fixtures.CreateFixtureRequest{
+			HomeTeam:  home,
+			AwayTeam:  away,
+			MatchDate: time.Now().Add(-time.Duration(daysAgo) * 24 * time.Hour),
+		})
+		finishFixture(t, fixture, score)
+		return fixture
+	}
+	play(lvpl.ID, mct.ID, 21, fixtures.Score{Home: 0, Away: 1})
+	play(mct.ID, lvpl.ID, 14, fixtures.Score{Home: 2, Away: 2})
+	play(lvpl.ID, mct.ID, 7, fixtures.Score{Home: 3, Away: 0})
+
+	t.Run("the team's latest results and streaks are given", func(t *testing.T) {
+		result, form := getTeamForm(lvpl.ID, "")
+		assert.Equal(t, http.StatusOK, result.StatusCode)
+		assert.Equal(t, []string{"W", "D", "L"}, formOutcomes(form))
+		streaks := form["streaks"].(map[string]interface{})
+		assert.Equal(t, float64(1), streaks["winning"])
+		assert.Equal(t, float64(2), streaks["unbeaten"])
+		home := form["home"].(map[string]interface{})
+		assert.Equal(t, float64(2), home["played"])
+		assert.Equal(t, float64(3), home["goals_for"])
+		away := form["away"].(map[string]interface{})
+		assert.Equal(t, float64(1), away["drawn"])
+	})
+
+	t.Run("the number of results can be chosen", func(t *testing.T) {
+		_, form := getTeamForm(lvpl.ID, "?last=1")
+		assert.Equal(t, []string{"W"}, formOutcomes(form))
+		result, _ := getTeamForm(lvpl.ID, "?last=0")
+		assert.Equal(t, http.StatusBadRequest, result.StatusCode)
+	})
+
+	var latest *fixtures.Fixture
+
+	t.Run("the form is updated when fixtures are played", func(t *testing.T) {
+		latest = play(mct.ID, lvpl.ID, 1, fixtures.Score{Home: 1, Away: 2})
+		_, form := getTeamForm(lvpl.ID, "")
+		assert.Equal(t, []string{"W", "W", "D", "L"}, formOutcomes(form))
+		_, form = getTeamForm(mct.ID, "")
+		assert.Equal(t, float64(2), form["streaks"].(map[string]interface{})["losing"])
+	})
+
+	t.Run("the form is updated when fixtures are deleted", func(t *testing.T) {
+		req, rec := jsonRequest(http.MethodDelete, "/fixtures/"+latest.ID.Hex(), nil, adminToken)
+		testApp.app.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		_, form := getTeamForm(lvpl.ID, "")
+		assert.Equal(t, []string{"W", "D", "L"}, formOutcomes(form))
+
+		_, err := testApp.app.FixturesDB.DeleteTeamFixtures(ctx, mct.ID)
+		assert.NoError(t, err)
+		_, form = getTeamForm(lvpl.ID, "")
+		assert.Empty(t, formOutcomes(form))
+	})
+
+	t.Run("unknown teams are not found", func(t *testing.T) {
+		result, _ := getTeamForm("nobody", "")
+		assert.Equal(t, http.StatusNotFound, result.StatusCode)
+	})
+}
@@ -0,0 +1,62 @@
+package tests
+
+import (
+	"context"
+	"gomoney-mock-epl/fixtures"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func Test_team_names_in_fixtures_stay_in_sync(t *testing.T) {
+	clearTeamsDB()
+	clearFixtures()
+
+	ctx := context.Background()
+	lvpl, _ := testApp.app.TeamsDB.Create(ctx, liverpool.ToTeam(""))
+	mct, _ := testApp.app.TeamsDB.Create(ctx, manCity.ToTeam(""))
+	home, _ := testApp.app.FixturesDB.Create(ctx, fixtures.CreateFixtureRequest{
+		HomeTeam:  lvpl.ID,
+		AwayTeam:  mct.ID,
+		MatchDate: time.Date(2030, 8, 14, 15, 0, 0, 0, time.UTC),
+	})
+	away, _ := testApp.app.FixturesDB.Create(ctx, fixtures.CreateFixtureRequest{
+		HomeTeam:  mct.ID,
+		AwayTeam:  lvpl.ID,
+		MatchDate: time.Date(2031, 1, 14, 15, 0, 0, 0, time.UTC),
+	})
+
+	t.Run("renaming a team renames it in its fixtures", func(t *testing.T) {
+		req, rec := jsonRequest(http.MethodPatch, "/teams/"+lvpl.ID,
+			map[string]string{"name": "Liverpool Reds"}, adminToken)
+		testApp.app.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		found, err := testApp.app.FixturesDB.Search(ctx, "Reds")
+		assert.NoError(t, err)
+		assert.Len(t, found, 2)
+		fixture, _ := testApp.app.FixturesDB.ByID(ctx, home.ID)
+		assert.Equal(t, home.Version+1, fixture.Version, "calendars see the fixture changed")
+	})
+
+	t.Run("resyncs repair drift and report it", func(t *testing.T) {
+		testApp.app.FixturesDB.Collection.UpdateOne(ctx, bson.D{{Key: "_id", Value: away.ID}},
+			bson.D{{Key: "$set", Value: bson.D{{Key: "away_team_name", Value: "Liverpool"}}}})
+
+		report, err := testApp.app.FixturesDB.Resync(ctx)
+		assert.NoError(t, err)
+		if assert.Len(t, report.Fixed, 1) {
+			assert.Equal(t, away.ID, report.Fixed[0].Fixture)
+			assert.Equal(t, "Liverpool", report.Fixed[0].Was)
+			assert.Equal(t, "Liverpool Reds", report.Fixed[0].Now)
+		}
+		assert.Empty(t, report.Orphaned)
+
+		report, err = testApp.app.FixturesDB.Resync(ctx)
+		assert.NoError(t, err)
+		assert.Empty(t, report.Fixed)
+	})
+}
@@ -0,0 +1,125 @@
+package tests
+
+import (
+	"context"
+	"gomoney-mock-epl/players"
+	"gomoney-mock-epl/transfers"
+	"gomoney-mock-epl/web"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func clearTransfers() {
+	testApp.app.Transfers.DeleteMany(context.Background(), bson.D{})
+}
+
+func recordTransfer(dto transfers.TransferRequest) (*http.Response, web.DataDto) {
+	req, rec := jsonRequest(http.MethodPost, "/transfers/", dto, adminToken)
+	testApp.app.ServeHTTP(rec, req)
+	body := web.DataDto{}
+	readJsonResponse(rec.Result().Body, &body)
+	return rec.Result(), body
+}
+
+func Test_transfers(t *testing.T) {
+	clearTeamsDB()
+	clearPlayers()
+	clearTransfers()
+
+	ctx := context.Background()
+	lvpl, _ := testApp.app.TeamsDB.Create(ctx, liverpool.ToTeam(""))
+	mct, _ := testApp.app.TeamsDB.Create(ctx, manCity.ToTeam(""))
+	player, _ := testApp.app.PlayersDB.Create(ctx, players.PlayerRequest{
+		Name:        "Harvey Elliott",
+		Position:    players.Midfielder,
+		DateOfBirth: time.Date(2003, time.April, 4, 0, 0, 0, 0, time.UTC),
+	})
+	signed := time.Date(2019, time.July, 28, 0, 0, 0, 0, time.UTC)
+	loaned := time.Date(2020, time.September, 1, 0, 0, 0, 0, time.UTC)
+	loanEnds := time.Date(2021, time.June, 30, 0, 0, 0, 0, time.UTC)
+	loanedAgain := time.Now().Add(-24 * time.Hour)
+	loanEndsAgain := time.Now().Add(30 * 24 * time.Hour)
+
+	t.Run("admins can record transfers", func(t *testing.T) {
+		result, body := recordTransfer(transfers.TransferRequest{
+			Player: player.ID, To: lvpl.ID, Date: signed, Fee: 1500000,
+		})
+		assert.Equal(t, http.StatusCreated, result.StatusCode)
+		assert.Equal(t, "2019-summer", body.Data.(map[string]interface{})["window"])
+		moved, _ := testApp.app.PlayersDB.ByID(ctx, player.ID)
+		assert.Equal(t, lvpl.ID, moved.Team)
+	})
+
+	t.Run("players can only leave the team they are at", func(t *testing.T) {
+		result, _ := recordTransfer(transfers.TransferRequest{
+			Player: player.ID, From: mct.ID, To: lvpl.ID, Date: loaned,
+		})
+		assert.Equal(t, http.StatusUnprocessableEntity, result.StatusCode)
+	})
+
+	t.Run("loaned players go back when their loans end", func(t *testing.T) {
+		result, _ := recordTransfer(transfers.TransferRequest{
+			Player: player.ID, From: lvpl.ID, To: mct.ID, Date: loaned, Loan: true, LoanEnd: &loanEnds,
+		})
+		assert.Equal(t, http.StatusCreated, result.StatusCode)
+		back, _ := testApp.app.PlayersDB.ByID(ctx, player.ID)
+		assert.Equal(t, lvpl.ID, back.Team)
+
+		result, _ = recordTransfer(transfers.TransferRequest{
+			Player: player.ID, From: lvpl.ID, To: mct.ID, Date: loanedAgain, Loan: true, LoanEnd: &loanEndsAgain,
+		})
+		assert.Equal(t, http.StatusCreated, result.StatusCode)
+		away, _ := testApp.app.PlayersDB.ByID(ctx, player.ID)
+		assert.Equal(t, mct.ID, away.Team)
+	})
+
+	t.Run("a player's history is derived from their transfers", func(t *testing.T) {
+		req, rec := jsonRequest(http.MethodGet, "/players/"+player.ID+"/transfers", nil, userToken)
+		testApp.app.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusOK, rec.Result().StatusCode)
+		body := web.DataDto{}
+		readJsonResponse(rec.Result().Body, &body)
+		history := body.Data.(map[string]interface{})
+		assert.Len(t, history["transfers"], 3)
+		spells := history["memberships"].([]interface{})
+		assert.Len(t, spells, 5)
+		assert.Equal(t, mct.ID, spells[3].(map[string]interface{})["team"])
+		assert.Equal(t, true, spells[3].(map[string]interface{})["loan"])
+	})
+
+	t.Run("a team's transfers are listed by window", func(t *testing.T) {
+		req, rec := jsonRequest(http.MethodGet, "/teams/"+mct.ID+"/transfers?window=2020-summer", nil, userToken)
+		testApp.app.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusOK, rec.Result().StatusCode)
+		body := web.DataDto{}
+		readJsonResponse(rec.Result().Body, &body)
+		windows := body.Data.([]interface{})
+		assert.Len(t, windows, 1)
+		assert.Len(t, windows[0].(map[string]interface{})["in"], 1)
+
+		req, rec = jsonRequest(http.MethodGet, "/teams/"+mct.ID+"/transfers?window=autumn", nil, userToken)
+		testApp.app.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusBadRequest, rec.Result().StatusCode)
+	})
+
+	t.Run("a team's players over a period are listed", func(t *testing.T) {
+		req, rec := jsonRequest(http.MethodGet, "/teams/"+mct.ID+"/members?from=2021-01-01&to=2022-01-01", nil, userToken)
+		testApp.app.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusOK, rec.Result().StatusCode)
+		body := web.DataDto{}
+		readJsonResponse(rec.Result().Body, &body)
+		members := body.Data.([]interface{})
+		assert.Len(t, members, 1)
+		assert.Equal(t, "Harvey Elliott", members[0].(map[string]interface{})["player"].(map[string]interface{})["name"])
+
+		req, rec = jsonRequest(http.MethodGet, "/teams/"+mct.ID+"/members?from=2022-01-01&to=2023-01-01", nil, userToken)
+		testApp.app.ServeHTTP(rec, req)
+		body = web.DataDto{}
+		readJsonResponse(rec.Result().Body, &body)
+		assert.Empty(t, body.Data)
+	})
+}
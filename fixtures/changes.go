@@ -0,0 +1,75 @@
+package fixtures
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ChangeType is the kind of change made to a fixture.
+type ChangeType string
+
+const (
+	FixtureCreated    = ChangeType("fixture_created")
+	FixtureUpdated    = ChangeType("fixture_updated")
+	FixtureDeleted    = ChangeType("fixture_deleted")
+	StatusChanged     = ChangeType("status_changed")
+	ScoreChanged      = ChangeType("score_changed")
+	EventAdded        = ChangeType("event_added")
+	EventRemoved      = ChangeType("event_removed")
+	LineupSubmitted   = ChangeType("lineup_submitted")
+	OfficialsAssigned = ChangeType("officials_assigned")
+)
+
+// Change describes a write made to a fixture through DB. The fixture is
+// the state after the write, or before it for deletes. The event is set
+// for changes to the timeline.
+type Change struct {
+	Type    ChangeType `json:"type"`
+	Fixture Fixture    `json:"fixture"`
+	Event   *Event     `json:"event,omitempty"`
+	At      time.Time  `json:"at"`
+}
+
+// Publisher is told about the changes made to fixtures, so that they can
+// be pushed to clients following them. Publish must not block.
+type Publisher interface {
+	Publish(Change)
+}
+
+// Publishers tells each of its publishers about every change.
+type Publishers []Publisher
+
+func (ps Publishers) Publish(change Change) {
+	for _, p := range ps {
+		p.Publish(change)
+	}
+}
+
+// publish tells the publisher, if there is one, about a change.
+func (db DB) publish(changeType ChangeType, fixture *Fixture, event *Event) {
+	if db.Publisher == nil || fixture == nil {
+		return
+	}
+	db.Publisher.Publish(Change{
+		Type:    changeType,
+		Fixture: *fixture,
+		Event:   event,
+		At:      db.now(),
+	})
+}
+
+// publishLatest reads a fixture after a write that does not return it,
+// and publishes the change. Failing to read the fixture is not an error
+// for the write, so the change is just not published.
+func (db DB) publishLatest(ctx context.Context, changeType ChangeType, id primitive.ObjectID, event *Event) {
+	if db.Publisher == nil {
+		return
+	}
+	fixture, err := db.ByID(ctx, id)
+	if err != nil {
+		return
+	}
+	db.publish(changeType, fixture, event)
+}
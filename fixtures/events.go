@@ -0,0 +1,337 @@
+package fixtures
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	customErrors "gomoney-mock-epl/errors"
+	"sort"
+	"time"
+
+	v "github.com/go-ozzo/ozzo-validation"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// EventType is the kind of thing that happened in a match.
+type EventType string
+
+const (
+	Goal         = EventType("goal")
+	OwnGoal      = EventType("own_goal")
+	PenaltyGoal  = EventType("penalty")
+	YellowCard   = EventType("yellow_card")
+	RedCard      = EventType("red_card")
+	Substitution = EventType("substitution")
+	VARDecision  = EventType("var_decision")
+)
+
+const (
+	firstHalfMinutes = 45
+	fullTimeMinutes  = 90
+)
+
+// Event is something that happened in a match. The team is the team of
+// the player involved, so an own goal counts for the other team. For
+// substitutions, the player goes off and the related player comes on. For
+// goals, the related player provided the assist. A VAR decision can
+// overturn an earlier event, like a goal that is then ruled out.
+type Event struct {
+	ID            primitive.ObjectID  `json:"id" bson:"_id"`
+	Type          EventType           `json:"type" bson:"type"`
+	Team          string              `json:"team" bson:"team"`
+	Player        string              `json:"player,omitempty" bson:"player,omitempty"`
+	RelatedPlayer string              `json:"related_player,omitempty" bson:"related_player,omitempty"`
+	Minute        int                 `json:"minute" bson:"minute"`
+	StoppageTime  int                 `json:"stoppage_time,omitempty" bson:"stoppage_time,omitempty"`
+	Overturns     *primitive.ObjectID `json:"overturns,omitempty" bson:"overturns,omitempty"`
+	Description   string              `json:"description,omitempty" bson:"description,omitempty"`
+	CreatedAt     time.Time           `json:"created_at" bson:"created_at"`
+}
+
+// isGoal reports whether the event changes the score.
+func (e Event) isGoal() bool {
+	return e.Type == Goal || e.Type == OwnGoal || e.Type == PenaltyGoal
+}
+
+// before orders events by the time they happened in the match.
+func (e Event) before(other Event) bool {
+	if e.Minute != other.Minute {
+		return e.Minute < other.Minute
+	}
+	return e.StoppageTime < other.StoppageTime
+}
+
+// EventRequest is the DTO we receive from the
+// clients when adding events to fixtures.
+type EventRequest struct {
+	Type          EventType `json:"type"`
+	Team          string    `json:"team"`
+	Player        string    `json:"player"`
+	RelatedPlayer string    `json:"related_player"`
+	Minute        int       `json:"minute"`
+	StoppageTime  int       `json:"stoppage_time"`
+	Overturns     string    `json:"overturns"`
+	Description   string    `json:"description"`
+}
+
+func stoppageTimeRule(minute int) v.RuleFunc {
+	return func(value interface{}) error {
+		if value.(int) > 0 && minute != firstHalfMinutes && minute != fullTimeMinutes {
+			return errors.New("Stoppage time can only be added to the 45th or 90th minute")
+		}
+		return nil
+	}
+}
+
+func (r EventRequest) Validate() (*customErrors.ValidationError, error) {
+	playerRules := []v.Rule{}
+	if r.Type != VARDecision {
+		playerRules = append(playerRules, v.Required.Error("The player involved is required"))
+	}
+	relatedPlayerRules := []v.Rule{}
+	if r.Type == Substitution {
+		relatedPlayerRules = append(relatedPlayerRules, v.Required.Error("The player coming on is required"))
+	}
+	overturnsRules := []v.Rule{}
+	if r.Type == VARDecision {
+		overturnsRules = append(overturnsRules, v.Required.Error("The event overturned is required"))
+	}
+	err := v.ValidateStruct(&r,
+		v.Field(&r.Type, v.Required.Error("Event type is required"),
+			v.In(Goal, OwnGoal, PenaltyGoal, YellowCard, RedCard, Substitution, VARDecision).
+				Error("Unknown event type")),
+		v.Field(&r.Team, v.Required.Error("The team involved is required")),
+		v.Field(&r.Player, playerRules...),
+		v.Field(&r.RelatedPlayer, relatedPlayerRules...),
+		v.Field(&r.Minute, v.Required.Error("The minute of the event is required"),
+			v.Min(1), v.Max(fullTimeMinutes)),
+		v.Field(&r.StoppageTime, v.Min(0), v.Max(30), v.By(stoppageTimeRule(r.Minute))),
+		v.Field(&r.Overturns, overturnsRules...),
+		v.Field(&r.Description, v.Length(0, 280)),
+	)
+
+	return customErrors.ToValidationError(err,
+		"Parts of the event supplied are invalid.",
+		"fixtures/invalid-event")
+}
+
+var ErrNotInPlay = errors.New("fixture is not in play")
+var ErrConcurrentUpdate = errors.New("fixture changed during the update, please try again")
+var ErrEventNotFound = errors.New("event does not exist")
+
+// inPlay reports whether events can be recorded for a fixture. Events
+// can be corrected after the match has finished.
+func (f Fixture) inPlay() bool {
+	return f.Status == Live || f.Status == HalfTime || f.Status == Finished
+}
+
+// halfTimeReached reports whether the first half of the fixture is over
+// once it's in the status. That's the case from half-time on, including
+// when the second half kicks off and the fixture goes back to live, which
+// the half-time score recorded at the break shows.
+func (f Fixture) halfTimeReached(status Status) bool {
+	switch {
+	case status == HalfTime || status == Finished:
+		return true
+	case f.Status == HalfTime:
+		return true
+	}
+	return f.Result != nil && f.Result.HalfTime != nil
+}
+
+// deriveResult computes the score from the goals in a timeline. Goals
+// overturned by VAR decisions don't count. The half-time score is only
+// known once the first half is over.
+func deriveResult(events []Event, homeTeam string, halfTimeReached bool) *Result {
+	overturned := map[primitive.ObjectID]bool{}
+	secondHalfStarted := halfTimeReached
+	for _, event := range events {
+		if event.Type == VARDecision && event.Overturns != nil {
+			overturned[*event.Overturns] = true
+		}
+		if event.Minute > firstHalfMinutes {
+			secondHalfStarted = true
+		}
+	}
+	fullTime, halfTime := Score{}, Score{}
+	for _, event := range events {
+		if !event.isGoal() || overturned[event.ID] {
+			continue
+		}
+		homeScored := event.Team == homeTeam
+		if event.Type == OwnGoal {
+			homeScored = !homeScored
+		}
+		score := &fullTime.Away
+		if homeScored {
+			score = &fullTime.Home
+		}
+		*score++
+		if event.Minute <= firstHalfMinutes {
+			if homeScored {
+				halfTime.Home++
+			} else {
+				halfTime.Away++
+			}
+		}
+	}
+	result := &Result{FullTime: fullTime}
+	if secondHalfStarted {
+		result.HalfTime = &halfTime
+	}
+	return result
+}
+
+// saveEvents replaces the timeline of a fixture, and the result derived
+// from it, as long as the fixture has not changed since it was read.
+func (db DB) saveEvents(ctx context.Context, fixture *Fixture, events []Event) error {
+	changes := bson.D{
+		{Key: "events", Value: events},
+		{Key: "result", Value: deriveResult(events, fixture.HomeTeam.ID, fixture.halfTimeReached(fixture.Status))},
+		{Key: "updated_at", Value: db.now()},
+	}
+	update := bson.D{{Key: "$set", Value: changes}, nextVersion}
+	if len(events) == 0 {
+		update = bson.D{
+			{Key: "$set", Value: changes[2:]},
+			{Key: "$unset", Value: bson.D{
+				{Key: "events", Value: ""},
+				{Key: "result", Value: ""},
+			}},
+			nextVersion,
+		}
+	}
+	result, err := db.Collection.UpdateOne(ctx, atVersion(fixture), update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrConcurrentUpdate
+	}
+	return nil
+}
+
+// Events lists the timeline of a fixture in match order. It returns
+// (nil, nil) if the fixture does not exist.
+func (db DB) Events(ctx context.Context, id primitive.ObjectID) ([]Event, error) {
+	fixture, err := db.ByID(ctx, id)
+	if err != nil || fixture == nil {
+		return nil, err
+	}
+	if fixture.Events == nil {
+		return []Event{}, nil
+	}
+	return fixture.Events, nil
+}
+
+// AddEvent adds an event to the timeline of a fixture that is in play, and
+// updates the fixture's result to match the goals in the timeline. It
+// returns (nil, nil) if the fixture does not exist.
+func (db DB) AddEvent(ctx context.Context, id primitive.ObjectID, dto EventRequest) (*Event, error) {
+	validationErr, err := dto.Validate()
+	if err != nil {
+		return nil, err
+	}
+	if validationErr != nil {
+		return nil, *validationErr
+	}
+	fixture, err := db.ByID(ctx, id)
+	if err != nil || fixture == nil {
+		return nil, err
+	}
+	if !fixture.inPlay() {
+		return nil, fmt.Errorf("%w: events can't be added to a %s fixture", ErrNotInPlay, fixture.Status)
+	}
+	validationErrs := customErrors.ValidationError{
+		Code:    "fixtures/invalid-event",
+		Message: "Parts of the event supplied are invalid.",
+		Details: []customErrors.ValidationErrorDetails{},
+	}
+	if dto.Team != fixture.HomeTeam.ID && dto.Team != fixture.AwayTeam.ID {
+		validationErrs.Details = append(validationErrs.Details, customErrors.ValidationErrorDetails{
+			Field:   "team",
+			Message: "The team is not playing in this fixture",
+		})
+	}
+	event := Event{
+		ID:            primitive.NewObjectID(),
+		Type:          dto.Type,
+		Team:          dto.Team,
+		Player:        dto.Player,
+		RelatedPlayer: dto.RelatedPlayer,
+		Minute:        dto.Minute,
+		StoppageTime:  dto.StoppageTime,
+		Description:   dto.Description,
+		CreatedAt:     db.now(),
+	}
+	if dto.Type == VARDecision {
+		overturns, err := primitive.ObjectIDFromHex(dto.Overturns)
+		if err != nil || !fixture.canOverturn(overturns) {
+			validationErrs.Details = append(validationErrs.Details, customErrors.ValidationErrorDetails{
+				Field:   "overturns",
+				Message: "The event overturned must be an earlier event in this fixture that stands",
+			})
+		}
+		event.Overturns = &overturns
+	}
+	if len(validationErrs.Details) > 0 {
+		return nil, validationErrs
+	}
+
+	events := append(fixture.Events, event)
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].before(events[j])
+	})
+	if err := db.saveEvents(ctx, fixture, events); err != nil {
+		return nil, err
+	}
+	db.publishLatest(ctx, EventAdded, id, &event)
+	return &event, nil
+}
+
+// canOverturn reports whether an event in the fixture's
+// timeline can be overturned by a VAR decision.
+func (f Fixture) canOverturn(eventID primitive.ObjectID) bool {
+	found := false
+	for _, event := range f.Events {
+		if event.Type == VARDecision && event.Overturns != nil && *event.Overturns == eventID {
+			return false
+		}
+		if event.ID == eventID && event.Type != VARDecision {
+			found = true
+		}
+	}
+	return found
+}
+
+// RemoveEvent removes an event from the timeline of a fixture, along with
+// any VAR decision that overturned it, and updates the fixture's result to
+// match. It fails with ErrEventNotFound if the fixture or the event does
+// not exist.
+func (db DB) RemoveEvent(ctx context.Context, id, eventID primitive.ObjectID) error {
+	fixture, err := db.ByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if fixture == nil {
+		return ErrEventNotFound
+	}
+	var removed *Event
+	events := make([]Event, 0, len(fixture.Events))
+	for i, event := range fixture.Events {
+		if event.ID == eventID {
+			removed = &fixture.Events[i]
+		} else if event.Overturns == nil || *event.Overturns != eventID {
+			events = append(events, event)
+		}
+	}
+	if removed == nil {
+		return ErrEventNotFound
+	}
+	if err := db.saveEvents(ctx, fixture, events); err != nil {
+		return err
+	}
+	db.publishLatest(ctx, EventRemoved, id, removed)
+	return nil
+}
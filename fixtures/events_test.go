@@ -0,0 +1,127 @@
+package fixtures
+
+import (
+	"testing"
+
+	"gomoney-mock-epl/errors"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestEventRequest_Validate(t *testing.T) {
+	t.Run("Requires the type, team, player and minute", func(t *testing.T) {
+		validationError, internalError := EventRequest{}.Validate()
+		assert.Nil(t, internalError)
+		assert.Equal(t, "fixtures/invalid-event", validationError.Code)
+		for _, field := range []string{"type", "team", "player", "minute"} {
+			found := false
+			for _, detail := range validationError.Details {
+				found = found || detail.Field == field
+			}
+			assert.True(t, found, "%s is not required", field)
+		}
+	})
+
+	t.Run("Requires the player coming on for substitutions", func(t *testing.T) {
+		validationError, _ := EventRequest{
+			Type: Substitution, Team: "ars", Player: "Saka", Minute: 70,
+		}.Validate()
+		assert.Contains(t, validationError.Details, errors.ValidationErrorDetails{
+			Field:   "related_player",
+			Message: "The player coming on is required",
+		})
+	})
+
+	t.Run("Only allows stoppage time at the end of each half", func(t *testing.T) {
+		validationError, _ := EventRequest{
+			Type: Goal, Team: "ars", Player: "Saka", Minute: 60, StoppageTime: 2,
+		}.Validate()
+		assert.Contains(t, validationError.Details, errors.ValidationErrorDetails{
+			Field:   "stoppage_time",
+			Message: "Stoppage time can only be added to the 45th or 90th minute",
+		})
+
+		validationError, _ = EventRequest{
+			Type: Goal, Team: "ars", Player: "Saka", Minute: 90, StoppageTime: 4,
+		}.Validate()
+		assert.Nil(t, validationError)
+	})
+
+	t.Run("Requires VAR decisions to overturn an event", func(t *testing.T) {
+		validationError, _ := EventRequest{Type: VARDecision, Team: "ars", Minute: 30}.Validate()
+		assert.Contains(t, validationError.Details, errors.ValidationErrorDetails{
+			Field:   "overturns",
+			Message: "The event overturned is required",
+		})
+	})
+}
+
+func goal(eventType EventType, team string, minute int) Event {
+	return Event{ID: primitive.NewObjectID(), Type: eventType, Team: team, Minute: minute}
+}
+
+func TestDeriveResult(t *testing.T) {
+	t.Run("Counts goals, penalties and own goals", func(t *testing.T) {
+		result := deriveResult([]Event{
+			goal(Goal, "home", 10),
+			goal(YellowCard, "home", 20),
+			goal(OwnGoal, "home", 30),
+			goal(PenaltyGoal, "away", 50),
+			goal(Goal, "home", 88),
+		}, "home", true)
+		assert.Equal(t, Score{Home: 2, Away: 2}, result.FullTime)
+		assert.Equal(t, &Score{Home: 1, Away: 1}, result.HalfTime)
+	})
+
+	t.Run("Ignores goals overturned by VAR", func(t *testing.T) {
+		disallowed := goal(Goal, "away", 40)
+		result := deriveResult([]Event{
+			disallowed,
+			{ID: primitive.NewObjectID(), Type: VARDecision, Team: "away", Minute: 41, Overturns: &disallowed.ID},
+		}, "home", false)
+		assert.Equal(t, Score{}, result.FullTime)
+	})
+
+	t.Run("Only gives the half-time score once the first half is over", func(t *testing.T) {
+		result := deriveResult([]Event{goal(Goal, "home", 10)}, "home", false)
+		assert.Nil(t, result.HalfTime)
+
+		result = deriveResult([]Event{goal(Goal, "home", 10)}, "home", true)
+		assert.Equal(t, &Score{Home: 1}, result.HalfTime)
+	})
+}
+
+func TestFixture_halfTimeReached(t *testing.T) {
+	t.Run("Not during the first half", func(t *testing.T) {
+		assert.False(t, Fixture{Status: Scheduled}.halfTimeReached(Live))
+		assert.False(t, Fixture{Status: Live, Result: &Result{}}.halfTimeReached(Live))
+	})
+
+	t.Run("From half-time on", func(t *testing.T) {
+		assert.True(t, Fixture{Status: Live}.halfTimeReached(HalfTime))
+		assert.True(t, Fixture{Status: Live}.halfTimeReached(Finished))
+	})
+
+	t.Run("Keeps the half-time score when the second half kicks off", func(t *testing.T) {
+		atBreak := Fixture{Status: HalfTime, Result: &Result{HalfTime: &Score{Home: 1}}}
+		assert.True(t, atBreak.halfTimeReached(Live))
+		result := deriveResult([]Event{goal(Goal, "home", 10)}, "home", atBreak.halfTimeReached(Live))
+		assert.Equal(t, &Score{Home: 1}, result.HalfTime)
+
+		secondHalf := Fixture{Status: Live, Result: result}
+		assert.True(t, secondHalf.halfTimeReached(Live))
+	})
+}
+
+func TestAtVersion(t *testing.T) {
+	fixture := &Fixture{ID: primitive.NewObjectID(), Version: 3}
+	assert.Equal(t, bson.D{{Key: "_id", Value: fixture.ID}, {Key: "version", Value: 3}}, atVersion(fixture))
+
+	fixture.Version = 0
+	assert.Equal(t, bson.D{
+		{Key: "_id", Value: fixture.ID},
+		{Key: "version", Value: bson.D{{Key: "$in", Value: bson.A{0, nil}}}},
+	}, atVersion(fixture), "fixtures stored without a version are at version 0")
+}
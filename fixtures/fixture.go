@@ -3,8 +3,13 @@ package fixtures
 import (
 	"context"
 	"errors"
+	"gomoney-mock-epl/clock"
 	"gomoney-mock-epl/database"
 	customErrors "gomoney-mock-epl/errors"
+	"gomoney-mock-epl/officials"
+	"gomoney-mock-epl/players"
+	"gomoney-mock-epl/seasons"
+	"gomoney-mock-epl/stadiums"
 	"gomoney-mock-epl/teams"
 	"time"
 
@@ -13,22 +18,35 @@ import (
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
-// Fixture is a match between two teams.
+// Fixture is a match between two teams. It's played at the home team's
+// stadium unless it has a venue, like a neutral ground. Its version goes
+// up with every write, so writers can tell it changed since they read it.
 type Fixture struct {
-	ID        primitive.ObjectID `json:"id" bson:"_id"`
-	HomeTeam  *teams.Team        `json:"home_team" bson:"home_team"`
-	AwayTeam  *teams.Team        `json:"away_team" bson:"away_team"`
-	MatchDate time.Time          `json:"match_date" bson:"match_date"`
-	CreatedAt time.Time          `json:"created_at" bson:"created_at"`
-	UpdatedAt time.Time          `json:"updated_at" bson:"updated_at"`
+	ID           primitive.ObjectID `json:"id" bson:"_id"`
+	HomeTeam     *teams.Team        `json:"home_team" bson:"home_team"`
+	AwayTeam     *teams.Team        `json:"away_team" bson:"away_team"`
+	MatchDate    time.Time          `json:"match_date" bson:"match_date"`
+	Season       string             `json:"season,omitempty" bson:"season,omitempty"`
+	Matchweek    int                `json:"matchweek,omitempty" bson:"matchweek,omitempty"`
+	Venue        string             `json:"venue,omitempty" bson:"venue,omitempty"`
+	Status       Status             `json:"status" bson:"status"`
+	Result       *Result            `json:"result" bson:"result,omitempty"`
+	Events       []Event            `json:"-" bson:"events,omitempty"`
+	Lineups      []Lineup           `json:"-" bson:"lineups,omitempty"`
+	Performances []Performance      `json:"-" bson:"performances,omitempty"`
+	Officials    []Assignment       `json:"-" bson:"officials,omitempty"`
+	Version      int                `json:"version" bson:"version"`
+	CreatedAt    time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt    time.Time          `json:"updated_at" bson:"updated_at"`
 }
 
 // fixtureWriteModel defines the shape of the data we save to MongoDB.
 // We store the home team name and the away teamn name in addition
 // to their IDs. The names are stored to be used in text search only.
-// Because the actual teams referenced can be updated, this information
-// can get out of sync. It's an optimisation for the search because the
-// text match stage has to be the first stage of the pipeline.
+// It's an optimisation for the search because the text match stage has
+// to be the first stage of the pipeline. The teams referenced can be
+// updated, so SyncTeam rewrites the names when they are, and Resync
+// repairs any that got out of sync anyway.
 type fixtureWriteModel struct {
 	ID           primitive.ObjectID `bson:"_id"`
 	HomeTeam     string             `bson:"home_team"`
@@ -36,29 +54,69 @@ type fixtureWriteModel struct {
 	AwayTeam     string             `bson:"away_team"`
 	AwayTeamName string             `bson:"away_team_name"`
 	MatchDate    time.Time          `bson:"match_date"`
+	Season       string             `bson:"season,omitempty"`
+	Matchweek    int                `bson:"matchweek,omitempty"`
+	Venue        string             `bson:"venue,omitempty"`
+	Status       Status             `bson:"status"`
+	Result       *Result            `bson:"result,omitempty"`
+	Events       []Event            `bson:"events,omitempty"`
+	Version      int                `bson:"version"`
 	CreatedAt    time.Time          `bson:"created_at"`
 	UpdatedAt    time.Time          `bson:"updated_at"`
 }
 
-// CreateFixtureRequest is the DTO we receive from the
-// clients when creating or updating fixtures.
+// CreateFixtureRequest is the DTO we receive from the clients when
+// creating or updating fixtures. The venue is the ID of the stadium the
+// fixture is played at, if it's not the home team's stadium. Updates
+// keep the venue if it's nil, and clear it if it's empty.
 type CreateFixtureRequest struct {
 	HomeTeam  string    `json:"home_team"`
 	AwayTeam  string    `json:"away_team"`
 	MatchDate time.Time `json:"match_date"`
+	Season    string    `json:"season"`
+	Matchweek int       `json:"matchweek"`
+	Venue     *string   `json:"venue"`
 }
 
-// DB provides methods for storing and accessing fixtures
-// in the database. It uses the teams database for lookups.
+// venue is the ID of the stadium the request moves the fixture to, if any.
+func (r CreateFixtureRequest) venue() string {
+	if r.Venue == nil {
+		return ""
+	}
+	return *r.Venue
+}
+
+// DB provides methods for storing and accessing fixtures in the
+// database. It uses the teams, seasons, players, stadiums and officials
+// databases for lookups, and tells the publisher, if set, about the
+// changes it makes. The time is taken from the clock, or the real time
+// if it's not set.
 type DB struct {
 	*mongo.Collection
 	teams.TeamsDB
+	seasons.SeasonsDB
+	Players     players.PlayersDB
+	Stadiums    stadiums.StadiumsDB
+	OfficialsDB officials.OfficialsDB
+	Publisher   Publisher
+	Clock       clock.Clock
 }
 
-// Create adds a new fixture to the system. The basic validations done
-// is to ensure that the teams referenced actually exist, and that the
-// same team is not paired with itself.
-func (db DB) Create(ctx context.Context, dto CreateFixtureRequest) (*Fixture, error) {
+func (db DB) now() time.Time {
+	return clock.Now(db.Clock)
+}
+
+// archivedTeam is the error for fixtures put between archived teams.
+// Archived teams have left the league, so they only keep the fixtures
+// they already had.
+func archivedTeam(field string) customErrors.ValidationErrorDetails {
+	return customErrors.ValidationErrorDetails{Field: field, Message: "The team is archived"}
+}
+
+// checkCreate runs the validations done by Create, and returns the teams
+// the request references. The validation error is nil if the request
+// is valid.
+func (db DB) checkCreate(ctx context.Context, dto CreateFixtureRequest) (*teams.Team, *teams.Team, *customErrors.ValidationError, error) {
 	validationErrs := customErrors.ValidationError{
 		Code:    "fixtures/cannot-create-fixture",
 		Message: "Your request to create a fixture failed",
@@ -66,29 +124,86 @@ func (db DB) Create(ctx context.Context, dto CreateFixtureRequest) (*Fixture, er
 	}
 	if dto.HomeTeam == dto.AwayTeam {
 		validationErrs.Message = "home team and away team must be different"
-		return nil, validationErrs
+		return nil, nil, &validationErrs, nil
 	}
 	homeTeam, err := db.TeamsDB.ByID(ctx, dto.HomeTeam)
 	if err != nil {
-		return nil, err
+		return nil, nil, nil, err
 	}
 	if homeTeam == nil {
 		validationErrs.Details = append(validationErrs.Details, customErrors.ValidationErrorDetails{
 			Field:   "home_team",
 			Message: "Unknown home team",
 		})
+	} else if homeTeam.ArchivedAt != nil {
+		validationErrs.Details = append(validationErrs.Details, archivedTeam("home_team"))
 	}
 	awayTeam, err := db.TeamsDB.ByID(ctx, dto.AwayTeam)
+	if err != nil {
+		return nil, nil, nil, err
+	}
 	if awayTeam == nil {
 		validationErrs.Details = append(validationErrs.Details, customErrors.ValidationErrorDetails{
 			Field:   "away_team",
 			Message: "Unknown away team",
 		})
+	} else if awayTeam.ArchivedAt != nil {
+		validationErrs.Details = append(validationErrs.Details, archivedTeam("away_team"))
+	}
+	seasonErrs, err := db.checkSeason(ctx, dto.Season, dto.HomeTeam, dto.AwayTeam, dto.MatchDate, dto.Matchweek)
+	if err != nil {
+		return nil, nil, nil, err
 	}
+	validationErrs.Details = append(validationErrs.Details, seasonErrs...)
+	venueErrs, err := db.checkVenue(ctx, dto.venue())
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	validationErrs.Details = append(validationErrs.Details, venueErrs...)
 	if len(validationErrs.Details) > 0 {
-		return nil, validationErrs
+		return nil, nil, &validationErrs, nil
+	}
+	return homeTeam, awayTeam, nil, nil
+}
+
+// Create adds a new fixture to the system. The basic validations done
+// is to ensure that the teams referenced actually exist, and that the
+// same team is not paired with itself. Fixtures in a season must also
+// fit in that season.
+func (db DB) Create(ctx context.Context, dto CreateFixtureRequest) (*Fixture, error) {
+	homeTeam, awayTeam, validationErr, err := db.checkCreate(ctx, dto)
+	if err != nil {
+		return nil, err
 	}
-	now := time.Now()
+	if validationErr != nil {
+		return nil, *validationErr
+	}
+	fixture, created := newFixture(dto, homeTeam, awayTeam, db.now())
+	if _, err := db.InsertOne(ctx, fixture); err != nil {
+		return nil, err
+	}
+	db.publish(FixtureCreated, created, nil)
+	return created, nil
+}
+
+// nextVersion is the part of every update to a fixture that counts
+// the write.
+var nextVersion = bson.E{Key: "$inc", Value: bson.D{{Key: "version", Value: 1}}}
+
+// atVersion matches a fixture as long as it has not been written to
+// since it was read. Fixtures stored before they had versions are at
+// version 0.
+func atVersion(fixture *Fixture) bson.D {
+	var version interface{} = fixture.Version
+	if fixture.Version == 0 {
+		version = bson.D{{Key: "$in", Value: bson.A{0, nil}}}
+	}
+	return bson.D{{Key: "_id", Value: fixture.ID}, {Key: "version", Value: version}}
+}
+
+// newFixture makes a scheduled fixture between the teams, and the
+// document it's stored as.
+func newFixture(dto CreateFixtureRequest, homeTeam, awayTeam *teams.Team, now time.Time) (fixtureWriteModel, *Fixture) {
 	fixture := fixtureWriteModel{
 		ID:           primitive.NewObjectID(),
 		HomeTeam:     homeTeam.ID,
@@ -96,18 +211,27 @@ func (db DB) Create(ctx context.Context, dto CreateFixtureRequest) (*Fixture, er
 		AwayTeam:     awayTeam.ID,
 		AwayTeamName: awayTeam.Name,
 		MatchDate:    dto.MatchDate,
+		Season:       dto.Season,
+		Matchweek:    dto.Matchweek,
+		Venue:        dto.venue(),
+		Status:       Scheduled,
+		Version:      1,
 		CreatedAt:    now,
 		UpdatedAt:    now,
 	}
-	_, err = db.InsertOne(ctx, fixture)
-	return &Fixture{
+	return fixture, &Fixture{
 		ID:        fixture.ID,
 		HomeTeam:  homeTeam,
 		AwayTeam:  awayTeam,
 		MatchDate: fixture.MatchDate,
+		Season:    fixture.Season,
+		Matchweek: fixture.Matchweek,
+		Venue:     fixture.Venue,
+		Status:    fixture.Status,
+		Version:   fixture.Version,
 		CreatedAt: fixture.CreatedAt,
 		UpdatedAt: fixture.UpdatedAt,
-	}, err
+	}
 }
 
 func restFindStages() mongo.Pipeline {
@@ -127,6 +251,9 @@ func restFindStages() mongo.Pipeline {
 		}}},
 		bson.D{{Key: "$unwind", Value: bson.D{{Key: "path", Value: "$home_team"}}}},
 		bson.D{{Key: "$unwind", Value: bson.D{{Key: "path", Value: "$away_team"}}}},
+		bson.D{{Key: "$addFields", Value: bson.D{
+			{Key: "status", Value: bson.D{{Key: "$ifNull", Value: bson.A{"$status", Scheduled}}}},
+		}}},
 	}
 }
 
@@ -158,40 +285,18 @@ func listFixturesQuery() mongo.Pipeline {
 	return append(inIDMatch, restFindStages()...)
 }
 
-func listFixturesByStatusQuery(status fixtureStatus) mongo.Pipeline {
-	comparison := "$gt"
-	if status == Completed {
-		comparison = "$lt"
-	}
-	now := time.Now().UTC()
+func listFixturesByStatusQuery(status Status) mongo.Pipeline {
 	match := mongo.Pipeline{
 		bson.D{
 			{Key: "$match", Value: bson.D{
-				{Key: "match_date", Value: bson.D{{Key: comparison, Value: now}}},
+				{Key: "status", Value: statusFilter(status)},
 			}},
 		},
 	}
 	return append(match, restFindStages()...)
 }
 
-type fixtureStatus string
-
-const (
-	Completed = fixtureStatus("completed")
-	Pending   = fixtureStatus("pending")
-)
-
-func NewFixtureStatus(s string) fixtureStatus {
-	if s == "completed" {
-		return Completed
-	}
-	if s == "pending" {
-		return Pending
-	}
-	return ""
-}
-
-func (db DB) List(ctx context.Context, status fixtureStatus) ([]Fixture, error) {
+func (db DB) List(ctx context.Context, status Status) ([]Fixture, error) {
 	query := listFixturesQuery()
 	if status != "" {
 		query = listFixturesByStatusQuery(status)
@@ -207,6 +312,35 @@ func (db DB) List(ctx context.Context, status fixtureStatus) ([]Fixture, error)
 	return fixtures, nil
 }
 
+func kickingOffQuery(from, to time.Time) mongo.Pipeline {
+	match := mongo.Pipeline{
+		bson.D{
+			{Key: "$match", Value: bson.D{
+				{Key: "status", Value: statusFilter(Scheduled)},
+				{Key: "match_date", Value: bson.D{
+					{Key: "$gte", Value: from},
+					{Key: "$lte", Value: to},
+				}},
+			}},
+		},
+	}
+	return append(match, restFindStages()...)
+}
+
+// KickingOff lists the scheduled fixtures whose match date is between
+// from and to, inclusive.
+func (db DB) KickingOff(ctx context.Context, from, to time.Time) ([]Fixture, error) {
+	cursor, err := db.Collection.Aggregate(ctx, kickingOffQuery(from, to))
+	if err != nil {
+		return nil, err
+	}
+	fixtures := []Fixture{}
+	if err := cursor.All(ctx, &fixtures); err != nil {
+		return nil, err
+	}
+	return fixtures, nil
+}
+
 func textSearchQuery(q string) mongo.Pipeline {
 	textMatch := mongo.Pipeline{
 		bson.D{
@@ -249,12 +383,28 @@ func (db DB) ByID(ctx context.Context, id primitive.ObjectID) (*Fixture, error)
 	if err := cursor.All(ctx, &fixture); err != nil {
 		return nil, err
 	}
+	if len(fixture) == 0 {
+		return nil, nil
+	}
 	return &fixture[0], nil
 }
 
-func (db DB) Delete(ctx context.Context, id string) error {
-	_, err := db.Collection.DeleteOne(ctx, bson.D{{Key: "_id", Value: id}})
-	return err
+// Delete removes a fixture, and publishes it as it was. It reports
+// whether there was one.
+func (db DB) Delete(ctx context.Context, id primitive.ObjectID) (bool, error) {
+	fixture, err := db.ByID(ctx, id)
+	if err != nil {
+		return false, err
+	}
+	result, err := db.Collection.DeleteOne(ctx, bson.D{{Key: "_id", Value: id}})
+	if err != nil {
+		return false, err
+	}
+	if result.DeletedCount == 0 {
+		return false, nil
+	}
+	db.publish(FixtureDeleted, fixture, nil)
+	return true, nil
 }
 
 func (db DB) Update(ctx context.Context, id primitive.ObjectID, dto CreateFixtureRequest) (*Fixture, error) {
@@ -281,8 +431,10 @@ func (db DB) Update(ctx context.Context, id primitive.ObjectID, dto CreateFixtur
 		AwayTeam:     fixture.AwayTeam.ID,
 		AwayTeamName: fixture.AwayTeam.Name,
 		MatchDate:    fixture.MatchDate,
+		Season:       fixture.Season,
+		Matchweek:    fixture.Matchweek,
 		CreatedAt:    fixture.CreatedAt,
-		UpdatedAt:    time.Now(),
+		UpdatedAt:    db.now(),
 	}
 	if dto.HomeTeam != "" {
 		homeTeam, err := db.TeamsDB.ByID(ctx, dto.HomeTeam)
@@ -294,6 +446,8 @@ func (db DB) Update(ctx context.Context, id primitive.ObjectID, dto CreateFixtur
 				Field:   "home_team",
 				Message: "Unknown home team",
 			})
+		} else if homeTeam.ArchivedAt != nil && dto.HomeTeam != fixture.HomeTeam.ID {
+			validationErrs.Details = append(validationErrs.Details, archivedTeam("home_team"))
 		} else {
 			writeModel.HomeTeam = dto.HomeTeam
 			writeModel.HomeTeamName = homeTeam.Name
@@ -309,18 +463,75 @@ func (db DB) Update(ctx context.Context, id primitive.ObjectID, dto CreateFixtur
 				Field:   "away_team",
 				Message: "Unknown away team",
 			})
+		} else if awayTeam.ArchivedAt != nil && dto.AwayTeam != fixture.AwayTeam.ID {
+			validationErrs.Details = append(validationErrs.Details, archivedTeam("away_team"))
 		} else {
 			writeModel.AwayTeam = dto.AwayTeam
 			writeModel.AwayTeamName = awayTeam.Name
 		}
 	}
+	if !dto.MatchDate.IsZero() {
+		writeModel.MatchDate = dto.MatchDate
+	}
+	if dto.Season != "" {
+		writeModel.Season = dto.Season
+	}
+	if dto.Matchweek != 0 {
+		writeModel.Matchweek = dto.Matchweek
+	}
+	seasonErrs, err := db.checkSeason(ctx, writeModel.Season, writeModel.HomeTeam,
+		writeModel.AwayTeam, writeModel.MatchDate, writeModel.Matchweek)
+	if err != nil {
+		return nil, err
+	}
+	validationErrs.Details = append(validationErrs.Details, seasonErrs...)
+	venueErrs, err := db.checkVenue(ctx, dto.venue())
+	if err != nil {
+		return nil, err
+	}
+	validationErrs.Details = append(validationErrs.Details, venueErrs...)
 	if len(validationErrs.Details) > 0 {
 		return nil, validationErrs
 	}
-	if !dto.MatchDate.IsZero() {
-		writeModel.MatchDate = dto.MatchDate
+	if !writeModel.MatchDate.Equal(fixture.MatchDate) {
+		err := db.checkClashes(ctx, id, fixture.officialIDs(), writeModel.MatchDate)
+		if err != nil {
+			return nil, err
+		}
+	}
+	// Only the fields a client can edit are set, so that data recorded
+	// through other paths (like results) is left untouched.
+	changes := bson.D{
+		{Key: "home_team", Value: writeModel.HomeTeam},
+		{Key: "home_team_name", Value: writeModel.HomeTeamName},
+		{Key: "away_team", Value: writeModel.AwayTeam},
+		{Key: "away_team_name", Value: writeModel.AwayTeamName},
+		{Key: "match_date", Value: writeModel.MatchDate},
+		{Key: "updated_at", Value: writeModel.UpdatedAt},
+	}
+	if writeModel.Season != "" {
+		changes = append(changes,
+			bson.E{Key: "season", Value: writeModel.Season},
+			bson.E{Key: "matchweek", Value: writeModel.Matchweek})
+	}
+	update := bson.D{}
+	switch {
+	case dto.Venue == nil:
+	case *dto.Venue == "":
+		update = append(update, bson.E{Key: "$unset", Value: bson.D{{Key: "venue", Value: ""}}})
+	default:
+		changes = append(changes, bson.E{Key: "venue", Value: *dto.Venue})
+	}
+	update = append(update, bson.E{Key: "$set", Value: changes}, nextVersion)
+	_, err = db.Collection.UpdateOne(ctx, bson.D{{Key: "_id", Value: id}}, update)
+	if err != nil {
+		return nil, err
 	}
-	_, err = db.Collection.ReplaceOne(ctx, bson.D{{Key: "_id", Value: id}}, writeModel)
 
-	return db.ByID(ctx, id)
+	updated, err := db.ByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	db.publish(FixtureUpdated, updated, nil)
+	return updated, nil
 }
@@ -0,0 +1,359 @@
+package fixtures
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	customErrors "gomoney-mock-epl/errors"
+	"gomoney-mock-epl/teams"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ImportRow is a fixture to import, as written in the file. Teams are
+// referenced by ID, name, short name or abbreviation. The kickoff is
+// an RFC 3339 time, or a date and time like 2021-08-14 15:00 in UTC.
+type ImportRow struct {
+	Line      int    `json:"line"`
+	HomeTeam  string `json:"home_team"`
+	AwayTeam  string `json:"away_team"`
+	Kickoff   string `json:"kickoff"`
+	Season    string `json:"season,omitempty"`
+	Matchweek string `json:"matchweek,omitempty"`
+	Venue     string `json:"venue,omitempty"`
+}
+
+// ImportedRow is what became of a row: the fixture it describes, with
+// the teams resolved, and its ID once it's created. Rows with errors
+// describe no fixture.
+type ImportedRow struct {
+	Line    int                                   `json:"line"`
+	Fixture *CreateFixtureRequest                 `json:"fixture,omitempty"`
+	ID      string                                `json:"id,omitempty"`
+	Errors  []customErrors.ValidationErrorDetails `json:"errors,omitempty"`
+}
+
+// ImportResult reports on an import. Nothing is created unless every
+// row is valid, and nothing at all on dry runs.
+type ImportResult struct {
+	DryRun  bool          `json:"dry_run"`
+	Valid   bool          `json:"valid"`
+	Created int           `json:"created"`
+	Rows    []ImportedRow `json:"rows"`
+}
+
+// importColumns are the columns of import files. The first
+// three are required.
+var importColumns = []string{"home_team", "away_team", "kickoff", "season", "matchweek", "venue"}
+
+// invalidImport is the error for files that can't be read as a whole.
+func invalidImport(line int, message string) error {
+	return customErrors.ValidationError{
+		Code:    "fixtures/invalid-import",
+		Message: "The fixtures to import could not be read.",
+		Details: []customErrors.ValidationErrorDetails{{
+			Field:   fmt.Sprintf("line %d", line),
+			Message: message,
+		}},
+	}
+}
+
+// eachLine calls fn with each line that isn't blank, numbered from 1.
+func eachLine(r io.Reader, fn func(line int, text string) error) error {
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		if err := fn(line, text); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		if errors.Is(err, bufio.ErrTooLong) {
+			return invalidImport(line+1, "The line is too long")
+		}
+		return err
+	}
+	return nil
+}
+
+// ParseCSV reads fixtures to import from CSV with a header row naming
+// the columns. Each fixture is on a line of its own, so fields can't
+// span lines.
+func ParseCSV(r io.Reader) ([]ImportRow, error) {
+	var columns map[string]int
+	var width int
+	rows := []ImportRow{}
+	err := eachLine(r, func(line int, text string) error {
+		reader := csv.NewReader(strings.NewReader(text))
+		reader.TrimLeadingSpace = true
+		reader.FieldsPerRecord = -1
+		record, err := reader.Read()
+		if err != nil {
+			return invalidImport(line, err.Error())
+		}
+		if columns == nil {
+			columns, width = map[string]int{}, len(record)
+			for i, name := range record {
+				columns[strings.ToLower(strings.TrimSpace(name))] = i
+			}
+			for _, required := range importColumns[:3] {
+				if _, ok := columns[required]; !ok {
+					return invalidImport(line, fmt.Sprintf("The %s column is missing", required))
+				}
+			}
+			return nil
+		}
+		if len(record) != width {
+			return invalidImport(line, fmt.Sprintf("Expected %d fields, found %d", width, len(record)))
+		}
+		value := func(column string) string {
+			if i, ok := columns[column]; ok {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		rows = append(rows, ImportRow{
+			Line:      line,
+			HomeTeam:  value("home_team"),
+			AwayTeam:  value("away_team"),
+			Kickoff:   value("kickoff"),
+			Season:    value("season"),
+			Matchweek: value("matchweek"),
+			Venue:     value("venue"),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if columns == nil {
+		return nil, invalidImport(1, "The header row is missing")
+	}
+	return rows, nil
+}
+
+// ParseJSONLines reads fixtures to import written as a JSON object per
+// line, with the same fields as the CSV columns.
+func ParseJSONLines(r io.Reader) ([]ImportRow, error) {
+	rows := []ImportRow{}
+	err := eachLine(r, func(line int, text string) error {
+		object := struct {
+			HomeTeam  string      `json:"home_team"`
+			AwayTeam  string      `json:"away_team"`
+			Kickoff   string      `json:"kickoff"`
+			Season    string      `json:"season"`
+			Matchweek json.Number `json:"matchweek"`
+			Venue     string      `json:"venue"`
+		}{}
+		if err := json.Unmarshal([]byte(text), &object); err != nil {
+			return invalidImport(line, err.Error())
+		}
+		rows = append(rows, ImportRow{
+			Line:      line,
+			HomeTeam:  object.HomeTeam,
+			AwayTeam:  object.AwayTeam,
+			Kickoff:   object.Kickoff,
+			Season:    object.Season,
+			Matchweek: object.Matchweek.String(),
+			Venue:     object.Venue,
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+func parseKickoff(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	return time.Parse("2006-01-02 15:04", s)
+}
+
+// teamIndex resolves team references. Names, short names and
+// abbreviations are matched whatever their case.
+type teamIndex struct {
+	byID  map[string]bool
+	byRef map[string][]string
+}
+
+func newTeamIndex(ts []teams.Team) teamIndex {
+	index := teamIndex{byID: map[string]bool{}, byRef: map[string][]string{}}
+	for _, team := range ts {
+		index.byID[team.ID] = true
+		seen := map[string]bool{}
+		for _, ref := range []string{team.Name, team.ShortName, team.NameAbbr} {
+			ref = strings.ToLower(strings.TrimSpace(ref))
+			if ref == "" || seen[ref] {
+				continue
+			}
+			seen[ref] = true
+			index.byRef[ref] = append(index.byRef[ref], team.ID)
+		}
+	}
+	return index
+}
+
+func (index teamIndex) resolve(ref string) (string, error) {
+	if ref == "" {
+		return "", errors.New("The team is required")
+	}
+	if index.byID[ref] {
+		return ref, nil
+	}
+	switch ids := index.byRef[strings.ToLower(ref)]; len(ids) {
+	case 0:
+		return "", fmt.Errorf("Unknown team %q", ref)
+	case 1:
+		return ids[0], nil
+	}
+	return "", fmt.Errorf("%q matches more than one team", ref)
+}
+
+// request turns a row into a request to create the fixture.
+func (row ImportRow) request(index teamIndex) (*CreateFixtureRequest, []customErrors.ValidationErrorDetails) {
+	dto := CreateFixtureRequest{Season: row.Season}
+	if row.Venue != "" {
+		dto.Venue = &row.Venue
+	}
+	details := []customErrors.ValidationErrorDetails{}
+	invalid := func(field string, err error) {
+		details = append(details, customErrors.ValidationErrorDetails{Field: field, Message: err.Error()})
+	}
+	var err error
+	if dto.HomeTeam, err = index.resolve(row.HomeTeam); err != nil {
+		invalid("home_team", err)
+	}
+	if dto.AwayTeam, err = index.resolve(row.AwayTeam); err != nil {
+		invalid("away_team", err)
+	}
+	if dto.MatchDate, err = parseKickoff(row.Kickoff); err != nil {
+		invalid("kickoff", errors.New("Kickoffs must look like 2021-08-14T15:00:00Z or 2021-08-14 15:00"))
+	}
+	if row.Matchweek != "" {
+		if dto.Matchweek, err = strconv.Atoi(row.Matchweek); err != nil || dto.Matchweek < 1 {
+			invalid("matchweek", errors.New("The matchweek must be a positive number"))
+		}
+	}
+	return &dto, details
+}
+
+// existingFixture finds the ID of a fixture between the teams at the
+// same kickoff already in the database. It returns nil if there's none.
+func (db DB) existingFixture(ctx context.Context, dto CreateFixtureRequest) (*primitive.ObjectID, error) {
+	existing := struct {
+		ID primitive.ObjectID `bson:"_id"`
+	}{}
+	err := db.Collection.FindOne(ctx,
+		bson.D{
+			{Key: "home_team", Value: dto.HomeTeam},
+			{Key: "away_team", Value: dto.AwayTeam},
+			{Key: "match_date", Value: dto.MatchDate},
+		},
+		options.FindOne().SetProjection(bson.D{{Key: "_id", Value: 1}})).Decode(&existing)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &existing.ID, nil
+}
+
+// Import creates fixtures from the rows, in order. Every row is checked
+// with the same rules as Create, against the other rows, and against the
+// fixtures already in the database. If any row is invalid, or on a dry
+// run, nothing is created and the result says what would become of each
+// row. Otherwise the fixtures are inserted in a single batch.
+func (db DB) Import(ctx context.Context, rows []ImportRow, dryRun bool) (*ImportResult, error) {
+	allTeams, err := db.TeamsDB.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	index := newTeamIndex(allTeams)
+	result := &ImportResult{DryRun: dryRun, Valid: true, Rows: make([]ImportedRow, 0, len(rows))}
+	seen := map[string]int{}
+	homeTeams := make([]*teams.Team, len(rows))
+	awayTeams := make([]*teams.Team, len(rows))
+	for i, row := range rows {
+		imported := ImportedRow{Line: row.Line}
+		dto, details := row.request(index)
+		if len(details) == 0 {
+			var validationErr *customErrors.ValidationError
+			homeTeams[i], awayTeams[i], validationErr, err = db.checkCreate(ctx, *dto)
+			if err != nil {
+				return nil, err
+			}
+			if validationErr != nil {
+				details = validationErr.Details
+				if len(details) == 0 {
+					details = []customErrors.ValidationErrorDetails{{Field: "away_team", Message: validationErr.Message}}
+				}
+			}
+		}
+		if len(details) == 0 {
+			key := dto.HomeTeam + "|" + dto.AwayTeam + "|" + dto.MatchDate.UTC().String()
+			if line, ok := seen[key]; ok {
+				details = append(details, customErrors.ValidationErrorDetails{
+					Field:   "kickoff",
+					Message: fmt.Sprintf("The fixture is already on line %d", line),
+				})
+			}
+			seen[key] = row.Line
+			existing, err := db.existingFixture(ctx, *dto)
+			if err != nil {
+				return nil, err
+			}
+			if existing != nil {
+				details = append(details, customErrors.ValidationErrorDetails{
+					Field:   "kickoff",
+					Message: fmt.Sprintf("The fixture already exists as %s", existing.Hex()),
+				})
+			}
+		}
+		if len(details) > 0 {
+			imported.Errors = details
+			result.Valid = false
+		} else {
+			imported.Fixture = dto
+		}
+		result.Rows = append(result.Rows, imported)
+	}
+	if dryRun || !result.Valid || len(rows) == 0 {
+		return result, nil
+	}
+	now := db.now()
+	docs := make([]interface{}, 0, len(rows))
+	created := make([]*Fixture, 0, len(rows))
+	for i := range result.Rows {
+		doc, fixture := newFixture(*result.Rows[i].Fixture, homeTeams[i], awayTeams[i], now)
+		docs = append(docs, doc)
+		created = append(created, fixture)
+	}
+	if _, err := db.Collection.InsertMany(ctx, docs); err != nil {
+		return result, err
+	}
+	for i, fixture := range created {
+		result.Rows[i].ID = fixture.ID.Hex()
+		db.publish(FixtureCreated, fixture, nil)
+	}
+	result.Created = len(created)
+	return result, nil
+}
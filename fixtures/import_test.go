@@ -0,0 +1,93 @@
+package fixtures
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"gomoney-mock-epl/errors"
+	"gomoney-mock-epl/teams"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCSV(t *testing.T) {
+	t.Run("Reads the columns by name", func(t *testing.T) {
+		rows, err := ParseCSV(strings.NewReader(
+			"Kickoff,Home_Team,Away_Team,Matchweek\n" +
+				"2021-08-14 15:00, Liverpool, \"Manchester City\",1\n" +
+				"\n" +
+				"2021-08-21T12:30:00Z,MUN,CHE,2\n"))
+		assert.Nil(t, err)
+		assert.Equal(t, []ImportRow{
+			{Line: 2, HomeTeam: "Liverpool", AwayTeam: "Manchester City", Kickoff: "2021-08-14 15:00", Matchweek: "1"},
+			{Line: 4, HomeTeam: "MUN", AwayTeam: "CHE", Kickoff: "2021-08-21T12:30:00Z", Matchweek: "2"},
+		}, rows)
+	})
+
+	t.Run("Needs the required columns", func(t *testing.T) {
+		_, err := ParseCSV(strings.NewReader("home_team,kickoff\nLiverpool,2021-08-14 15:00\n"))
+		assert.Equal(t, errors.ValidationErrorDetails{
+			Field: "line 1", Message: "The away_team column is missing",
+		}, err.(errors.ValidationError).Details[0])
+
+		_, err = ParseCSV(strings.NewReader(""))
+		assert.Equal(t, "fixtures/invalid-import", err.(errors.ValidationError).Code)
+	})
+
+	t.Run("Reports the line of malformed rows", func(t *testing.T) {
+		_, err := ParseCSV(strings.NewReader("home_team,away_team,kickoff\nLiverpool,Everton\n"))
+		assert.Equal(t, "line 2", err.(errors.ValidationError).Details[0].Field)
+	})
+}
+
+func TestParseJSONLines(t *testing.T) {
+	rows, err := ParseJSONLines(strings.NewReader(
+		`{"home_team": "LIV", "away_team": "MCI", "kickoff": "2021-08-14 15:00", "matchweek": 1}` + "\n\n" +
+			`{"home_team": "MUN", "away_team": "CHE", "kickoff": "2021-08-21 12:30"}` + "\n"))
+	assert.Nil(t, err)
+	assert.Equal(t, []ImportRow{
+		{Line: 1, HomeTeam: "LIV", AwayTeam: "MCI", Kickoff: "2021-08-14 15:00", Matchweek: "1"},
+		{Line: 3, HomeTeam: "MUN", AwayTeam: "CHE", Kickoff: "2021-08-21 12:30"},
+	}, rows)
+
+	_, err = ParseJSONLines(strings.NewReader(`{"home_team": "LIV"}` + "\n" + `{"home_team": `))
+	assert.Equal(t, "line 2", err.(errors.ValidationError).Details[0].Field)
+}
+
+func TestTeamIndex_resolve(t *testing.T) {
+	index := newTeamIndex([]teams.Team{
+		{ID: "liv", Name: "Liverpool", ShortName: "Liverpool", NameAbbr: "LIV"},
+		{ID: "mci", Name: "Manchester City", ShortName: "Man City", NameAbbr: "MCI"},
+		{ID: "mun", Name: "Manchester United", ShortName: "Man Utd", NameAbbr: "MUN"},
+		{ID: "xyz", Name: "Man Utd"},
+	})
+	for ref, id := range map[string]string{"liv": "liv", "LIVERPOOL": "liv", "man city": "mci", "MUN": "mun"} {
+		resolved, err := index.resolve(ref)
+		assert.Nil(t, err, ref)
+		assert.Equal(t, id, resolved, ref)
+	}
+	_, err := index.resolve("Man Utd")
+	assert.EqualError(t, err, `"Man Utd" matches more than one team`)
+	_, err = index.resolve("Everton")
+	assert.EqualError(t, err, `Unknown team "Everton"`)
+}
+
+func TestImportRow_request(t *testing.T) {
+	index := newTeamIndex([]teams.Team{{ID: "liv", Name: "Liverpool"}, {ID: "mci", Name: "Manchester City"}})
+	dto, details := ImportRow{HomeTeam: "Liverpool", AwayTeam: "mci", Kickoff: "2021-08-14 15:00", Matchweek: "3"}.request(index)
+	assert.Empty(t, details)
+	assert.Equal(t, CreateFixtureRequest{
+		HomeTeam:  "liv",
+		AwayTeam:  "mci",
+		MatchDate: time.Date(2021, 8, 14, 15, 0, 0, 0, time.UTC),
+		Matchweek: 3,
+	}, *dto)
+
+	_, details = ImportRow{HomeTeam: "Liverpool", Kickoff: "Saturday", Matchweek: "first"}.request(index)
+	fields := []string{}
+	for _, detail := range details {
+		fields = append(fields, detail.Field)
+	}
+	assert.Equal(t, []string{"away_team", "kickoff", "matchweek"}, fields)
+}
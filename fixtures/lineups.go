@@ -0,0 +1,235 @@
+package fixtures
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	customErrors "gomoney-mock-epl/errors"
+	"gomoney-mock-epl/players"
+	"strconv"
+	"strings"
+	"time"
+
+	v "github.com/go-ozzo/ozzo-validation"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	startersPerLineup = 11
+	// maxSubstitutes is the number of substitutes a team can name.
+	maxSubstitutes = 9
+)
+
+// LineupPlayer is a player named in a lineup.
+type LineupPlayer struct {
+	Name        string           `json:"name" bson:"name"`
+	ShirtNumber int              `json:"shirt_number" bson:"shirt_number"`
+	Position    players.Position `json:"position" bson:"position"`
+}
+
+// Lineup is the team a club puts out for a fixture: the starting XI in a
+// formation, like 4-3-3, and the substitutes on the bench.
+type Lineup struct {
+	Team        string         `json:"team" bson:"team"`
+	Formation   string         `json:"formation" bson:"formation"`
+	Starters    []LineupPlayer `json:"starters" bson:"starters"`
+	Bench       []LineupPlayer `json:"bench" bson:"bench"`
+	SubmittedAt time.Time      `json:"submitted_at" bson:"submitted_at"`
+}
+
+// FixtureLineups are the lineups of both teams in a fixture. A lineup is
+// nil until its team submits it. Lineups are locked once the match date
+// has passed.
+type FixtureLineups struct {
+	Home   *Lineup `json:"home"`
+	Away   *Lineup `json:"away"`
+	Locked bool    `json:"locked"`
+}
+
+// LineupRequest is the DTO we receive from the
+// clients when submitting lineups.
+type LineupRequest struct {
+	Team      string         `json:"team"`
+	Formation string         `json:"formation"`
+	Starters  []LineupPlayer `json:"starters"`
+	Bench     []LineupPlayer `json:"bench"`
+}
+
+// formationRule checks that a formation lists the outfield
+// players in each line, from defence to attack.
+func formationRule(value interface{}) error {
+	formation := value.(string)
+	if formation == "" {
+		return nil
+	}
+	invalid := errors.New("Formations list the players in each line, like 4-3-3")
+	lines := strings.Split(formation, "-")
+	if len(lines) < 2 || len(lines) > 5 {
+		return invalid
+	}
+	outfield := 0
+	for _, line := range lines {
+		n, err := strconv.Atoi(line)
+		if err != nil || n < 1 || n > 6 {
+			return invalid
+		}
+		outfield += n
+	}
+	if outfield != startersPerLineup-1 {
+		return fmt.Errorf("Formations have %d outfield players", startersPerLineup-1)
+	}
+	return nil
+}
+
+// lineupPlayersRule checks the players named in a part of a lineup.
+// shirts counts the players wearing each shirt number in the lineup.
+func lineupPlayersRule(label string, shirts map[int]int) v.RuleFunc {
+	return func(value interface{}) error {
+		for i, player := range value.([]LineupPlayer) {
+			switch {
+			case strings.TrimSpace(player.Name) == "":
+				return fmt.Errorf("%s %d has no name", label, i+1)
+			case player.ShirtNumber < 1 || player.ShirtNumber > 99:
+				return fmt.Errorf("%s %d needs a shirt number between 1 and 99", label, i+1)
+			case shirts[player.ShirtNumber] > 1:
+				return fmt.Errorf("Shirt number %d is worn by more than one player", player.ShirtNumber)
+			}
+			if err := v.Validate(player.Position, v.Required,
+				v.In(players.Goalkeeper, players.Defender, players.Midfielder, players.Forward)); err != nil {
+				return fmt.Errorf("%s %d needs a goalkeeper, defender, midfielder or forward position", label, i+1)
+			}
+		}
+		return nil
+	}
+}
+
+// oneGoalkeeperRule checks that exactly one goalkeeper starts.
+func oneGoalkeeperRule(value interface{}) error {
+	goalkeepers := 0
+	for _, player := range value.([]LineupPlayer) {
+		if player.Position == players.Goalkeeper {
+			goalkeepers++
+		}
+	}
+	if goalkeepers != 1 {
+		return errors.New("Exactly one goalkeeper must start")
+	}
+	return nil
+}
+
+func (r LineupRequest) Validate() (*customErrors.ValidationError, error) {
+	shirts := map[int]int{}
+	for _, player := range append(append([]LineupPlayer{}, r.Starters...), r.Bench...) {
+		shirts[player.ShirtNumber]++
+	}
+	err := v.ValidateStruct(&r,
+		v.Field(&r.Team, v.Required.Error("The team is required")),
+		v.Field(&r.Formation, v.Required.Error("The formation is required"), v.By(formationRule)),
+		v.Field(&r.Starters, v.Required.Error("The starting players are required"),
+			v.Length(startersPerLineup, startersPerLineup).
+				Error(fmt.Sprintf("A lineup has %d starters", startersPerLineup)),
+			v.By(lineupPlayersRule("Starter", shirts)),
+			v.By(oneGoalkeeperRule)),
+		v.Field(&r.Bench, v.Length(0, maxSubstitutes).
+			Error(fmt.Sprintf("At most %d substitutes can be named", maxSubstitutes)),
+			v.By(lineupPlayersRule("Substitute", shirts))),
+	)
+
+	return customErrors.ToValidationError(err,
+		"Parts of the lineup supplied are invalid.",
+		"fixtures/invalid-lineup")
+}
+
+var ErrLineupLocked = errors.New("lineups are locked once the match date has passed")
+
+// lineupsLocked reports whether lineups can no longer be changed.
+func (f Fixture) lineupsLocked(now time.Time) bool {
+	return !now.Before(f.MatchDate) || f.inPlay()
+}
+
+// lineup finds the lineup submitted by a team, if any.
+func (f Fixture) lineup(team string) *Lineup {
+	for i := range f.Lineups {
+		if f.Lineups[i].Team == team {
+			return &f.Lineups[i]
+		}
+	}
+	return nil
+}
+
+// Lineups returns the lineups of a fixture. It returns (nil, nil) if the
+// fixture does not exist.
+func (db DB) Lineups(ctx context.Context, id primitive.ObjectID) (*FixtureLineups, error) {
+	fixture, err := db.ByID(ctx, id)
+	if err != nil || fixture == nil {
+		return nil, err
+	}
+	return &FixtureLineups{
+		Home:   fixture.lineup(fixture.HomeTeam.ID),
+		Away:   fixture.lineup(fixture.AwayTeam.ID),
+		Locked: fixture.lineupsLocked(db.now()),
+	}, nil
+}
+
+// SubmitLineup saves a team's lineup for a fixture, replacing the lineup
+// it submitted before. It fails with ErrLineupLocked once the match date
+// has passed, and returns (nil, nil) if the fixture does not exist.
+func (db DB) SubmitLineup(ctx context.Context, id primitive.ObjectID, dto LineupRequest) (*Lineup, error) {
+	validationErr, err := dto.Validate()
+	if err != nil {
+		return nil, err
+	}
+	if validationErr != nil {
+		return nil, *validationErr
+	}
+	fixture, err := db.ByID(ctx, id)
+	if err != nil || fixture == nil {
+		return nil, err
+	}
+	now := db.now()
+	if fixture.lineupsLocked(now) {
+		return nil, ErrLineupLocked
+	}
+	if dto.Team != fixture.HomeTeam.ID && dto.Team != fixture.AwayTeam.ID {
+		return nil, customErrors.ValidationError{
+			Code:    "fixtures/invalid-lineup",
+			Message: "Parts of the lineup supplied are invalid.",
+			Details: []customErrors.ValidationErrorDetails{{
+				Field:   "team",
+				Message: "The team is not playing in this fixture",
+			}},
+		}
+	}
+	lineup := Lineup{
+		Team:        dto.Team,
+		Formation:   dto.Formation,
+		Starters:    dto.Starters,
+		Bench:       dto.Bench,
+		SubmittedAt: now,
+	}
+	if lineup.Bench == nil {
+		lineup.Bench = []LineupPlayer{}
+	}
+	lineups := []Lineup{lineup}
+	for _, other := range fixture.Lineups {
+		if other.Team != dto.Team {
+			lineups = append(lineups, other)
+		}
+	}
+	// Matching on the version keeps lineups from being saved against
+	// a fixture that was rescheduled or kicked off since it was read.
+	result, err := db.Collection.UpdateOne(ctx, atVersion(fixture),
+		bson.D{{Key: "$set", Value: bson.D{
+			{Key: "lineups", Value: lineups},
+			{Key: "updated_at", Value: now},
+		}}, nextVersion})
+	if err != nil {
+		return nil, err
+	}
+	if result.MatchedCount == 0 {
+		return nil, ErrConcurrentUpdate
+	}
+	db.publishLatest(ctx, LineupSubmitted, id, nil)
+	return &lineup, nil
+}
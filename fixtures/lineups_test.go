@@ -0,0 +1,94 @@
+package fixtures
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"gomoney-mock-epl/errors"
+	"gomoney-mock-epl/players"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func startingXI() []LineupPlayer {
+	lineup := []LineupPlayer{{Name: "Alisson", ShirtNumber: 1, Position: players.Goalkeeper}}
+	for i := 2; i <= 11; i++ {
+		lineup = append(lineup, LineupPlayer{
+			Name:        fmt.Sprintf("Player %d", i),
+			ShirtNumber: i,
+			Position:    players.Midfielder,
+		})
+	}
+	return lineup
+}
+
+func TestLineupRequest_Validate(t *testing.T) {
+	t.Run("Accepts a full lineup", func(t *testing.T) {
+		validationErr, err := LineupRequest{
+			Team:      "liv",
+			Formation: "4-2-3-1",
+			Starters:  startingXI(),
+			Bench:     []LineupPlayer{{Name: "Kelleher", ShirtNumber: 62, Position: players.Goalkeeper}},
+		}.Validate()
+		assert.Nil(t, err)
+		assert.Nil(t, validationErr)
+	})
+
+	t.Run("Refuses formations without ten outfield players", func(t *testing.T) {
+		for _, formation := range []string{"4-4-3", "433", "4-3-3-0", "4-x-3"} {
+			validationErr, _ := LineupRequest{Team: "liv", Formation: formation, Starters: startingXI()}.Validate()
+			assert.NotNil(t, validationErr, formation)
+			assert.Equal(t, "formation", validationErr.Details[0].Field, formation)
+		}
+	})
+
+	t.Run("Needs eleven starters with one goalkeeper", func(t *testing.T) {
+		validationErr, _ := LineupRequest{Team: "liv", Formation: "4-3-3", Starters: startingXI()[:10]}.Validate()
+		assert.Contains(t, validationErr.Details, errors.ValidationErrorDetails{
+			Field: "starters", Message: "A lineup has 11 starters",
+		})
+
+		starters := startingXI()
+		starters[5].Position = players.Goalkeeper
+		validationErr, _ = LineupRequest{Team: "liv", Formation: "4-3-3", Starters: starters}.Validate()
+		assert.Contains(t, validationErr.Details, errors.ValidationErrorDetails{
+			Field: "starters", Message: "Exactly one goalkeeper must start",
+		})
+	})
+
+	t.Run("Limits the bench", func(t *testing.T) {
+		bench := []LineupPlayer{}
+		for i := 12; i <= 22; i++ {
+			bench = append(bench, LineupPlayer{Name: "Sub", ShirtNumber: i, Position: players.Defender})
+		}
+		validationErr, _ := LineupRequest{
+			Team: "liv", Formation: "4-3-3", Starters: startingXI(), Bench: bench,
+		}.Validate()
+		assert.Contains(t, validationErr.Details, errors.ValidationErrorDetails{
+			Field: "bench", Message: "At most 9 substitutes can be named",
+		})
+	})
+
+	t.Run("Refuses shirt numbers worn twice", func(t *testing.T) {
+		validationErr, _ := LineupRequest{
+			Team:      "liv",
+			Formation: "4-3-3",
+			Starters:  startingXI(),
+			Bench:     []LineupPlayer{{Name: "Jones", ShirtNumber: 11, Position: players.Midfielder}},
+		}.Validate()
+		assert.Contains(t, validationErr.Details, errors.ValidationErrorDetails{
+			Field: "bench", Message: "Shirt number 11 is worn by more than one player",
+		})
+	})
+}
+
+func TestFixture_lineupsLocked(t *testing.T) {
+	kickOff := time.Date(2021, time.August, 14, 15, 0, 0, 0, time.UTC)
+	fixture := Fixture{MatchDate: kickOff, Status: Scheduled}
+	assert.False(t, fixture.lineupsLocked(kickOff.Add(-time.Minute)))
+	assert.True(t, fixture.lineupsLocked(kickOff))
+
+	fixture.Status = Live
+	assert.True(t, fixture.lineupsLocked(kickOff.Add(-time.Minute)))
+}
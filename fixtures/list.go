@@ -0,0 +1,288 @@
+package fixtures
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	customErrors "gomoney-mock-epl/errors"
+	"strconv"
+	"strings"
+	"time"
+
+	v "github.com/go-ozzo/ozzo-validation"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+// Venue is where a team plays a fixture.
+type Venue string
+
+const (
+	Home = Venue("home")
+	Away = Venue("away")
+)
+
+// sortFields are the fields fixtures can be sorted by. A sort order is
+// one of them, optionally prefixed with "-" for descending order.
+var sortFields = []interface{}{"match_date", "-match_date", "created_at", "-created_at"}
+
+// ListRequest is the query we receive from clients when listing
+// fixtures. Dates are either RFC 3339 timestamps or YYYY-MM-DD dates,
+// and the range is from the start of From to before To. The venue
+// needs a team, and filters for its home or away fixtures.
+type ListRequest struct {
+	Status string `json:"status" query:"status"`
+	Team   string `json:"team" query:"team"`
+	Venue  string `json:"venue" query:"venue"`
+	From   string `json:"from" query:"from"`
+	To     string `json:"to" query:"to"`
+	Sort   string `json:"sort" query:"sort"`
+	Limit  string `json:"limit" query:"limit"`
+	Cursor string `json:"cursor" query:"cursor"`
+}
+
+// Page is a page of fixtures. The cursors are empty when there
+// are no more fixtures in that direction.
+type Page struct {
+	Fixtures []Fixture
+	Limit    int
+	Next     string
+	Prev     string
+}
+
+// cursor marks a position in a sorted list of fixtures. Cursors are
+// opaque to clients; they are only decoded by the server.
+type cursor struct {
+	Sort      string             `json:"s"`
+	Value     time.Time          `json:"v"`
+	ID        primitive.ObjectID `json:"id"`
+	Backwards bool               `json:"b,omitempty"`
+}
+
+func (c cursor) encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(s string) (*cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	c := &cursor{}
+	if err := json.Unmarshal(data, c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func parseDate(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	return time.Parse("2006-01-02", s)
+}
+
+func dateRule(value interface{}) error {
+	if s := value.(string); s != "" {
+		if _, err := parseDate(s); err != nil {
+			return errors.New("Dates must look like 2021-08-14 or 2021-08-14T15:00:00Z")
+		}
+	}
+	return nil
+}
+
+func limitRule(value interface{}) error {
+	if s := value.(string); s != "" {
+		if limit, err := strconv.Atoi(s); err != nil || limit < 1 || limit > MaxPageSize {
+			return fmt.Errorf("The limit must be a number from 1 to %d", MaxPageSize)
+		}
+	}
+	return nil
+}
+
+func (r ListRequest) Validate() (*customErrors.ValidationError, error) {
+	venueRules := []v.Rule{v.In(string(Home), string(Away)).Error("The venue must be home or away")}
+	if r.Team == "" {
+		venueRules = append(venueRules, v.By(func(value interface{}) error {
+			if value.(string) != "" {
+				return errors.New("The venue can only be given with a team")
+			}
+			return nil
+		}))
+	}
+	err := v.ValidateStruct(&r,
+		v.Field(&r.Status, v.By(func(value interface{}) error {
+			if s := value.(string); s != "" && NewFixtureStatus(s) == "" {
+				return fmt.Errorf("Unknown fixture status %q", s)
+			}
+			return nil
+		})),
+		v.Field(&r.Venue, venueRules...),
+		v.Field(&r.From, v.By(dateRule)),
+		v.Field(&r.To, v.By(dateRule)),
+		v.Field(&r.Sort, v.In(sortFields...).Error("Fixtures can be sorted by match_date or created_at, prefixed with - for descending order")),
+		v.Field(&r.Limit, v.By(limitRule)),
+		v.Field(&r.Cursor, v.By(func(value interface{}) error {
+			if s := value.(string); s != "" {
+				c, err := decodeCursor(s)
+				if err != nil || c.Sort != r.sort() {
+					return errors.New("The cursor is invalid, or was made for another sort order")
+				}
+			}
+			return nil
+		})),
+	)
+	return customErrors.ToValidationError(err,
+		"Parts of the query supplied are invalid.",
+		"fixtures/invalid-query")
+}
+
+func (r ListRequest) sort() string {
+	if r.Sort == "" {
+		return "match_date"
+	}
+	return r.Sort
+}
+
+func (r ListRequest) limit() int {
+	limit, err := strconv.Atoi(r.Limit)
+	if err != nil {
+		return DefaultPageSize
+	}
+	return limit
+}
+
+// filter matches the fixtures the request is for, ignoring the cursor.
+func (r ListRequest) filter() bson.D {
+	filter := bson.D{}
+	if r.Status != "" {
+		filter = append(filter, bson.E{Key: "status", Value: statusFilter(NewFixtureStatus(r.Status))})
+	}
+	switch Venue(r.Venue) {
+	case Home:
+		filter = append(filter, bson.E{Key: "home_team", Value: r.Team})
+	case Away:
+		filter = append(filter, bson.E{Key: "away_team", Value: r.Team})
+	default:
+		if r.Team != "" {
+			filter = append(filter, playedBy(r.Team))
+		}
+	}
+	dates := bson.D{}
+	if from, err := parseDate(r.From); err == nil {
+		dates = append(dates, bson.E{Key: "$gte", Value: from})
+	}
+	if to, err := parseDate(r.To); err == nil {
+		dates = append(dates, bson.E{Key: "$lt", Value: to})
+	}
+	if len(dates) > 0 {
+		filter = append(filter, bson.E{Key: "match_date", Value: dates})
+	}
+	return filter
+}
+
+// listPageQuery finds a page of fixtures after (or before) the cursor.
+// Fixtures are sorted by the sort field then by ID, so that fixtures
+// with the same value are paged through in a stable order. One more
+// fixture than the limit is fetched, to tell if there are more. The
+// limit comes after the teams are looked up, as fixtures whose teams
+// are gone drop out there and would leave the page short.
+func listPageQuery(filter bson.D, sort string, after *cursor, limit int) mongo.Pipeline {
+	field := strings.TrimPrefix(sort, "-")
+	direction := 1
+	if strings.HasPrefix(sort, "-") {
+		direction = -1
+	}
+	if after != nil && after.Backwards {
+		direction = -direction
+	}
+	if after != nil {
+		comparison := "$gt"
+		if direction < 0 {
+			comparison = "$lt"
+		}
+		// The filter may have an $or of its own, so the two are combined.
+		filter = bson.D{{Key: "$and", Value: bson.A{filter, bson.D{{Key: "$or", Value: bson.A{
+			bson.D{{Key: field, Value: bson.D{{Key: comparison, Value: after.Value}}}},
+			bson.D{
+				{Key: field, Value: after.Value},
+				{Key: "_id", Value: bson.D{{Key: comparison, Value: after.ID}}},
+			},
+		}}}}}}
+	}
+	page := mongo.Pipeline{
+		bson.D{{Key: "$match", Value: filter}},
+		bson.D{{Key: "$sort", Value: bson.D{
+			{Key: field, Value: direction},
+			{Key: "_id", Value: direction},
+		}}},
+	}
+	page = append(page, restFindStages()...)
+	return append(page, bson.D{{Key: "$limit", Value: limit + 1}})
+}
+
+func cursorAt(sort string, fixture Fixture, backwards bool) string {
+	value := fixture.MatchDate
+	if strings.TrimPrefix(sort, "-") == "created_at" {
+		value = fixture.CreatedAt
+	}
+	return cursor{Sort: sort, Value: value, ID: fixture.ID, Backwards: backwards}.encode()
+}
+
+// Page lists a page of the fixtures matching the request. The cursors of
+// the page can be passed back to get the pages after and before it.
+func (db DB) Page(ctx context.Context, r ListRequest) (*Page, error) {
+	validationErr, err := r.Validate()
+	if err != nil {
+		return nil, err
+	}
+	if validationErr != nil {
+		return nil, *validationErr
+	}
+	var after *cursor
+	if r.Cursor != "" {
+		after, _ = decodeCursor(r.Cursor)
+	}
+	sort, limit := r.sort(), r.limit()
+	aggregation, err := db.Collection.Aggregate(ctx, listPageQuery(r.filter(), sort, after, limit))
+	if err != nil {
+		return nil, err
+	}
+	fixtures := []Fixture{}
+	if err := aggregation.All(ctx, &fixtures); err != nil {
+		return nil, err
+	}
+
+	more := len(fixtures) > limit
+	if more {
+		fixtures = fixtures[:limit]
+	}
+	backwards := after != nil && after.Backwards
+	if backwards {
+		for i, j := 0, len(fixtures)-1; i < j; i, j = i+1, j-1 {
+			fixtures[i], fixtures[j] = fixtures[j], fixtures[i]
+		}
+	}
+	page := &Page{Fixtures: fixtures, Limit: limit}
+	if len(fixtures) == 0 {
+		return page, nil
+	}
+	// Going forwards, there are fixtures before this page if we got here
+	// with a cursor. Going backwards, there are fixtures after it.
+	if (!backwards && more) || backwards {
+		page.Next = cursorAt(sort, fixtures[len(fixtures)-1], false)
+	}
+	if (backwards && more) || (!backwards && after != nil) {
+		page.Prev = cursorAt(sort, fixtures[0], true)
+	}
+	return page, nil
+}
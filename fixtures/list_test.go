@@ -0,0 +1,120 @@
+package fixtures
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestListRequest_Validate(t *testing.T) {
+	t.Run("Accepts an empty query", func(t *testing.T) {
+		validationErr, err := ListRequest{}.Validate()
+		assert.Nil(t, err)
+		assert.Nil(t, validationErr)
+	})
+
+	t.Run("Accepts every filter", func(t *testing.T) {
+		validationErr, _ := ListRequest{
+			Status: "pending",
+			Team:   "ars",
+			Venue:  "away",
+			From:   "2021-08-14",
+			To:     "2021-09-01T00:00:00Z",
+			Sort:   "-created_at",
+			Limit:  "50",
+		}.Validate()
+		assert.Nil(t, validationErr)
+	})
+
+	t.Run("Refuses invalid filters", func(t *testing.T) {
+		validationErr, _ := ListRequest{
+			Status: "over",
+			Venue:  "home",
+			From:   "14/08/2021",
+			Sort:   "name",
+			Limit:  "1000",
+			Cursor: "not a cursor",
+		}.Validate()
+		assert.Equal(t, "fixtures/invalid-query", validationErr.Code)
+		fields := []string{}
+		for _, detail := range validationErr.Details {
+			fields = append(fields, detail.Field)
+		}
+		assert.ElementsMatch(t, []string{"status", "venue", "from", "sort", "limit", "cursor"}, fields)
+	})
+
+	t.Run("Refuses cursors made for another sort order", func(t *testing.T) {
+		c := cursor{Sort: "match_date", ID: primitive.NewObjectID()}.encode()
+		validationErr, _ := ListRequest{Cursor: c}.Validate()
+		assert.Nil(t, validationErr)
+		validationErr, _ = ListRequest{Cursor: c, Sort: "-match_date"}.Validate()
+		assert.NotNil(t, validationErr)
+	})
+}
+
+func TestListRequest_filter(t *testing.T) {
+	t.Run("Filters by status, venue and dates", func(t *testing.T) {
+		from := time.Date(2021, time.August, 14, 0, 0, 0, 0, time.UTC)
+		assert.Equal(t, bson.D{
+			{Key: "status", Value: Finished},
+			{Key: "home_team", Value: "ars"},
+			{Key: "match_date", Value: bson.D{{Key: "$gte", Value: from}}},
+		}, ListRequest{Status: "completed", Team: "ars", Venue: "home", From: "2021-08-14"}.filter())
+	})
+
+	t.Run("Matches both venues when only the team is given", func(t *testing.T) {
+		assert.Equal(t, bson.D{{Key: "$or", Value: bson.A{
+			bson.D{{Key: "home_team", Value: "ars"}},
+			bson.D{{Key: "away_team", Value: "ars"}},
+		}}}, ListRequest{Team: "ars"}.filter())
+	})
+}
+
+func TestCursor(t *testing.T) {
+	c := cursor{
+		Sort:      "-created_at",
+		Value:     time.Date(2021, time.August, 14, 15, 0, 0, 0, time.UTC),
+		ID:        primitive.NewObjectID(),
+		Backwards: true,
+	}
+	decoded, err := decodeCursor(c.encode())
+	assert.NoError(t, err)
+	assert.Equal(t, c, *decoded)
+}
+
+func TestListPageQuery(t *testing.T) {
+	after := &cursor{Sort: "-match_date", Value: time.Unix(0, 0), ID: primitive.NewObjectID()}
+
+	t.Run("Continues after the cursor in the sort order", func(t *testing.T) {
+		filter := bson.D{playedBy("team")}
+		query := listPageQuery(filter, "-match_date", after, 10)
+		assert.Equal(t, bson.D{{Key: "$match", Value: bson.D{{Key: "$and", Value: bson.A{
+			filter,
+			bson.D{{Key: "$or", Value: bson.A{
+				bson.D{{Key: "match_date", Value: bson.D{{Key: "$lt", Value: after.Value}}}},
+				bson.D{
+					{Key: "match_date", Value: after.Value},
+					{Key: "_id", Value: bson.D{{Key: "$lt", Value: after.ID}}},
+				},
+			}}},
+		}}}}}, query[0])
+		assert.Equal(t, bson.D{{Key: "$sort", Value: bson.D{
+			{Key: "match_date", Value: -1},
+			{Key: "_id", Value: -1},
+		}}}, query[1])
+		assert.Equal(t, bson.D{{Key: "$limit", Value: 11}}, query[len(query)-1])
+	})
+
+	t.Run("Goes the other way for backward cursors", func(t *testing.T) {
+		backwards := *after
+		backwards.Backwards = true
+		query := listPageQuery(bson.D{}, "-match_date", &backwards, 10)
+		assert.Equal(t, bson.D{{Key: "$sort", Value: bson.D{
+			{Key: "match_date", Value: 1},
+			{Key: "_id", Value: 1},
+		}}}, query[1])
+	})
+}
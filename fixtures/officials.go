@@ -0,0 +1,300 @@
+package fixtures
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	customErrors "gomoney-mock-epl/errors"
+	"gomoney-mock-epl/officials"
+	"time"
+
+	v "github.com/go-ozzo/ozzo-validation"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	// officialSlot is how long a fixture takes up its officials for from
+	// kick-off: the match, the break, stoppage time and getting away.
+	officialSlot = 3 * time.Hour
+	// maxAssistants is the number of assistant referees in a fixture.
+	maxAssistants = 2
+)
+
+// Assignment is an official assigned to a fixture, in the
+// role they were assigned with.
+type Assignment struct {
+	Official string         `json:"official" bson:"official"`
+	Role     officials.Role `json:"role" bson:"role"`
+}
+
+// FixtureOfficials are the officials assigned to a fixture. The referee
+// and the VAR are nil until they are assigned.
+type FixtureOfficials struct {
+	Referee    *officials.Official  `json:"referee"`
+	Assistants []officials.Official `json:"assistants"`
+	VAR        *officials.Official  `json:"var"`
+}
+
+// OfficialsRequest is the DTO we receive from the clients when
+// assigning officials to a fixture. It lists the IDs of the officials.
+type OfficialsRequest struct {
+	Referee    string   `json:"referee"`
+	Assistants []string `json:"assistants"`
+	VAR        string   `json:"var"`
+}
+
+// assignments lists the officials in the request with their roles.
+func (r OfficialsRequest) assignments() []Assignment {
+	assignments := []Assignment{{Official: r.Referee, Role: officials.Referee}}
+	for _, assistant := range r.Assistants {
+		assignments = append(assignments, Assignment{Official: assistant, Role: officials.Assistant})
+	}
+	if r.VAR != "" {
+		assignments = append(assignments, Assignment{Official: r.VAR, Role: officials.VAR})
+	}
+	return assignments
+}
+
+// assistantsRule checks that the assistants are named and that
+// no official is assigned twice.
+func (r OfficialsRequest) assistantsRule(value interface{}) error {
+	seen := map[string]bool{r.Referee: true, r.VAR: r.VAR != ""}
+	for _, assistant := range value.([]string) {
+		if assistant == "" {
+			return errors.New("Assistants are listed by ID")
+		}
+		if seen[assistant] {
+			return errors.New("An official can only be assigned once")
+		}
+		seen[assistant] = true
+	}
+	return nil
+}
+
+func (r OfficialsRequest) Validate() (*customErrors.ValidationError, error) {
+	err := v.ValidateStruct(&r,
+		v.Field(&r.Referee, v.Required.Error("The referee is required")),
+		v.Field(&r.Assistants, v.Length(0, maxAssistants).
+			Error(fmt.Sprintf("At most %d assistants can be assigned", maxAssistants)),
+			v.By(r.assistantsRule)),
+		v.Field(&r.VAR, v.NotIn(r.Referee).Error("An official can only be assigned once")),
+	)
+
+	return customErrors.ToValidationError(err,
+		"Parts of the officials supplied are invalid.",
+		"fixtures/invalid-officials")
+}
+
+var ErrOfficialsLocked = errors.New("officials can't be changed once a fixture has kicked off")
+var ErrOfficialUnavailable = errors.New("official is working another fixture at the time")
+
+// officialsLocked reports whether the officials can no longer be changed.
+func (f Fixture) officialsLocked() bool {
+	switch f.Status {
+	case Live, HalfTime, Finished, Cancelled:
+		return true
+	}
+	return false
+}
+
+// officialIDs lists the IDs of the officials assigned to the fixture.
+func (f Fixture) officialIDs() []string {
+	ids := make([]string, 0, len(f.Officials))
+	for _, assignment := range f.Officials {
+		ids = append(ids, assignment.Official)
+	}
+	return ids
+}
+
+// clashFilter matches the other fixtures any of the officials work
+// too close to the match date. Postponed and cancelled fixtures
+// don't take up their officials.
+func clashFilter(id primitive.ObjectID, officialIDs []string, matchDate time.Time) bson.D {
+	return bson.D{
+		{Key: "_id", Value: bson.D{{Key: "$ne", Value: id}}},
+		{Key: "officials.official", Value: bson.D{{Key: "$in", Value: officialIDs}}},
+		{Key: "status", Value: bson.D{{Key: "$nin", Value: bson.A{Postponed, Cancelled}}}},
+		{Key: "match_date", Value: bson.D{
+			{Key: "$gt", Value: matchDate.Add(-officialSlot)},
+			{Key: "$lt", Value: matchDate.Add(officialSlot)},
+		}},
+	}
+}
+
+// checkClashes fails with ErrOfficialUnavailable if any of the
+// officials works another fixture too close to the match date.
+func (db DB) checkClashes(ctx context.Context, id primitive.ObjectID, officialIDs []string, matchDate time.Time) error {
+	if len(officialIDs) == 0 {
+		return nil
+	}
+	clashes, err := db.latestFirst(ctx, clashFilter(id, officialIDs, matchDate))
+	if err != nil || len(clashes) == 0 {
+		return err
+	}
+	clash := clashes[0]
+	wanted := map[string]bool{}
+	for _, official := range officialIDs {
+		wanted[official] = true
+	}
+	for _, official := range clash.officialIDs() {
+		if wanted[official] {
+			return fmt.Errorf("%w: official %s works %s v %s at %s", ErrOfficialUnavailable, official,
+				clash.HomeTeam.Name, clash.AwayTeam.Name, clash.MatchDate.Format(time.RFC3339))
+		}
+	}
+	return fmt.Errorf("%w: fixture %s", ErrOfficialUnavailable, clash.ID.Hex())
+}
+
+// fixtureOfficials fills in the assigned officials. Officials that can't
+// be found are left out.
+func fixtureOfficials(assignments []Assignment, found []officials.Official) *FixtureOfficials {
+	byID := map[string]officials.Official{}
+	for _, official := range found {
+		byID[official.ID] = official
+	}
+	fixtureOfficials := &FixtureOfficials{Assistants: []officials.Official{}}
+	for _, assignment := range assignments {
+		official, ok := byID[assignment.Official]
+		if !ok {
+			continue
+		}
+		switch assignment.Role {
+		case officials.Referee:
+			fixtureOfficials.Referee = &official
+		case officials.Assistant:
+			fixtureOfficials.Assistants = append(fixtureOfficials.Assistants, official)
+		case officials.VAR:
+			fixtureOfficials.VAR = &official
+		}
+	}
+	return fixtureOfficials
+}
+
+// Officials returns the officials assigned to a fixture. It returns
+// (nil, nil) if the fixture does not exist.
+func (db DB) Officials(ctx context.Context, id primitive.ObjectID) (*FixtureOfficials, error) {
+	fixture, err := db.ByID(ctx, id)
+	if err != nil || fixture == nil {
+		return nil, err
+	}
+	found, err := db.OfficialsDB.ByIDs(ctx, fixture.officialIDs())
+	if err != nil {
+		return nil, err
+	}
+	return fixtureOfficials(fixture.Officials, found), nil
+}
+
+// AssignOfficials replaces the officials assigned to a fixture. Each
+// official must be known in the role they are assigned to, and not work
+// another fixture within officialSlot of this one's match date, or it
+// fails with ErrOfficialUnavailable. It fails with ErrOfficialsLocked
+// once the fixture has kicked off, and returns (nil, nil) if the fixture
+// does not exist.
+func (db DB) AssignOfficials(ctx context.Context, id primitive.ObjectID, dto OfficialsRequest) (*FixtureOfficials, error) {
+	validationErr, err := dto.Validate()
+	if err != nil {
+		return nil, err
+	}
+	if validationErr != nil {
+		return nil, *validationErr
+	}
+	fixture, err := db.ByID(ctx, id)
+	if err != nil || fixture == nil {
+		return nil, err
+	}
+	if fixture.officialsLocked() {
+		return nil, ErrOfficialsLocked
+	}
+	assignments := dto.assignments()
+	officialIDs := make([]string, 0, len(assignments))
+	for _, assignment := range assignments {
+		officialIDs = append(officialIDs, assignment.Official)
+	}
+	found, err := db.OfficialsDB.ByIDs(ctx, officialIDs)
+	if err != nil {
+		return nil, err
+	}
+	roles := map[string]officials.Role{}
+	for _, official := range found {
+		roles[official.ID] = official.Role
+	}
+	validationErrs := customErrors.ValidationError{
+		Code:    "fixtures/invalid-officials",
+		Message: "Parts of the officials supplied are invalid.",
+		Details: []customErrors.ValidationErrorDetails{},
+	}
+	fields := map[officials.Role]string{
+		officials.Referee:   "referee",
+		officials.Assistant: "assistants",
+		officials.VAR:       "var",
+	}
+	for _, assignment := range assignments {
+		role, ok := roles[assignment.Official]
+		switch {
+		case !ok:
+			validationErrs.Details = append(validationErrs.Details, customErrors.ValidationErrorDetails{
+				Field:   fields[assignment.Role],
+				Message: "Unknown official " + assignment.Official,
+			})
+		case role != assignment.Role:
+			validationErrs.Details = append(validationErrs.Details, customErrors.ValidationErrorDetails{
+				Field:   fields[assignment.Role],
+				Message: fmt.Sprintf("Official %s is not a %s", assignment.Official, assignment.Role),
+			})
+		}
+	}
+	if len(validationErrs.Details) > 0 {
+		return nil, validationErrs
+	}
+	if err := db.checkClashes(ctx, id, officialIDs, fixture.MatchDate); err != nil {
+		return nil, err
+	}
+	// Matching on the version keeps officials from being assigned to
+	// a fixture that was rescheduled or kicked off since it was read.
+	result, err := db.Collection.UpdateOne(ctx, atVersion(fixture),
+		bson.D{{Key: "$set", Value: bson.D{
+			{Key: "officials", Value: assignments},
+			{Key: "updated_at", Value: db.now()},
+		}}, nextVersion})
+	if err != nil {
+		return nil, err
+	}
+	if result.MatchedCount == 0 {
+		return nil, ErrConcurrentUpdate
+	}
+	db.publishLatest(ctx, OfficialsAssigned, id, nil)
+	return fixtureOfficials(assignments, found), nil
+}
+
+// OfficiatedFixture is a fixture an official is assigned to,
+// and the role they work it in.
+type OfficiatedFixture struct {
+	Fixture
+	Role officials.Role `json:"role"`
+}
+
+// OfficialFixtures lists the fixtures an official is assigned to,
+// latest first.
+func (db DB) OfficialFixtures(ctx context.Context, officialID string) ([]OfficiatedFixture, error) {
+	fixtures, err := db.latestFirst(ctx, bson.D{{Key: "officials.official", Value: officialID}})
+	if err != nil {
+		return nil, err
+	}
+	officiated := make([]OfficiatedFixture, 0, len(fixtures))
+	for _, fixture := range fixtures {
+		for _, assignment := range fixture.Officials {
+			if assignment.Official == officialID {
+				officiated = append(officiated, OfficiatedFixture{Fixture: fixture, Role: assignment.Role})
+				break
+			}
+		}
+	}
+	return officiated, nil
+}
+
+// OfficialInUse reports whether an official is assigned to any fixture.
+func (db DB) OfficialInUse(ctx context.Context, officialID string) (bool, error) {
+	count, err := db.Collection.CountDocuments(ctx, bson.D{{Key: "officials.official", Value: officialID}})
+	return count > 0, err
+}
@@ -0,0 +1,86 @@
+package fixtures
+
+import (
+	"testing"
+	"time"
+
+	"gomoney-mock-epl/errors"
+	"gomoney-mock-epl/officials"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestOfficialsRequest_Validate(t *testing.T) {
+	t.Run("Accepts a full team of officials", func(t *testing.T) {
+		validationErr, err := OfficialsRequest{Referee: "ref", Assistants: []string{"ar1", "ar2"}, VAR: "var"}.Validate()
+		assert.Nil(t, err)
+		assert.Nil(t, validationErr)
+	})
+
+	t.Run("Requires the referee", func(t *testing.T) {
+		validationErr, _ := OfficialsRequest{Assistants: []string{"ar1"}}.Validate()
+		assert.Equal(t, "fixtures/invalid-officials", validationErr.Code)
+		assert.Equal(t, "referee", validationErr.Details[0].Field)
+	})
+
+	t.Run("Limits the assistants", func(t *testing.T) {
+		validationErr, _ := OfficialsRequest{Referee: "ref", Assistants: []string{"ar1", "ar2", "ar3"}}.Validate()
+		assert.Contains(t, validationErr.Details, errors.ValidationErrorDetails{
+			Field: "assistants", Message: "At most 2 assistants can be assigned",
+		})
+	})
+
+	t.Run("Assigns each official once", func(t *testing.T) {
+		validationErr, _ := OfficialsRequest{Referee: "ref", Assistants: []string{"ar1", "ar1"}}.Validate()
+		assert.Equal(t, "assistants", validationErr.Details[0].Field)
+		validationErr, _ = OfficialsRequest{Referee: "ref", Assistants: []string{"ref"}}.Validate()
+		assert.Equal(t, "assistants", validationErr.Details[0].Field)
+		validationErr, _ = OfficialsRequest{Referee: "ref", VAR: "ref"}.Validate()
+		assert.Equal(t, "var", validationErr.Details[0].Field)
+	})
+}
+
+func TestOfficialsRequest_assignments(t *testing.T) {
+	assert.Equal(t, []Assignment{
+		{Official: "ref", Role: officials.Referee},
+		{Official: "ar1", Role: officials.Assistant},
+		{Official: "var", Role: officials.VAR},
+	}, OfficialsRequest{Referee: "ref", Assistants: []string{"ar1"}, VAR: "var"}.assignments())
+}
+
+func TestFixture_officialsLocked(t *testing.T) {
+	for status, locked := range map[Status]bool{
+		"": false, Scheduled: false, Postponed: false, Abandoned: false,
+		Live: true, HalfTime: true, Finished: true, Cancelled: true,
+	} {
+		assert.Equal(t, locked, Fixture{Status: status}.officialsLocked(), status)
+	}
+}
+
+func TestClashFilter(t *testing.T) {
+	id := primitive.NewObjectID()
+	kickOff := time.Date(2021, 3, 13, 15, 0, 0, 0, time.UTC)
+	filter := clashFilter(id, []string{"ref"}, kickOff)
+	assert.Equal(t, bson.E{Key: "match_date", Value: bson.D{
+		{Key: "$gt", Value: time.Date(2021, 3, 13, 12, 0, 0, 0, time.UTC)},
+		{Key: "$lt", Value: time.Date(2021, 3, 13, 18, 0, 0, 0, time.UTC)},
+	}}, filter[3])
+	assert.Equal(t, bson.E{Key: "_id", Value: bson.D{{Key: "$ne", Value: id}}}, filter[0])
+}
+
+func TestFixtureOfficials(t *testing.T) {
+	found := []officials.Official{
+		{ID: "ref", Role: officials.Referee},
+		{ID: "ar1", Role: officials.Assistant},
+	}
+	assigned := fixtureOfficials([]Assignment{
+		{Official: "ref", Role: officials.Referee},
+		{Official: "ar1", Role: officials.Assistant},
+		{Official: "gone", Role: officials.VAR},
+	}, found)
+	assert.Equal(t, "ref", assigned.Referee.ID)
+	assert.Equal(t, []officials.Official{found[1]}, assigned.Assistants)
+	assert.Nil(t, assigned.VAR)
+}
@@ -0,0 +1,275 @@
+package fixtures
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gomoney-mock-epl/database"
+	customErrors "gomoney-mock-epl/errors"
+	"gomoney-mock-epl/players"
+	"time"
+
+	v "github.com/go-ozzo/ozzo-validation"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// maxMinutes is the most minutes a player can play in
+// a match, stoppage time included.
+const maxMinutes = 120
+
+// Performance is what a player did in a fixture. Players who came off
+// the bench play fewer minutes, and unused substitutes play none.
+type Performance struct {
+	Player      string    `json:"player" bson:"player"`
+	Team        string    `json:"team" bson:"team"`
+	Minutes     int       `json:"minutes" bson:"minutes"`
+	Goals       int       `json:"goals" bson:"goals"`
+	Assists     int       `json:"assists" bson:"assists"`
+	YellowCards int       `json:"yellow_cards" bson:"yellow_cards"`
+	RedCards    int       `json:"red_cards" bson:"red_cards"`
+	Saves       int       `json:"saves" bson:"saves"`
+	UpdatedAt   time.Time `json:"updated_at" bson:"updated_at"`
+}
+
+// PerformanceRequest is the DTO we receive from the
+// clients when submitting player performances.
+type PerformanceRequest struct {
+	Player      string `json:"player"`
+	Team        string `json:"team"`
+	Minutes     int    `json:"minutes"`
+	Goals       int    `json:"goals"`
+	Assists     int    `json:"assists"`
+	YellowCards int    `json:"yellow_cards"`
+	RedCards    int    `json:"red_cards"`
+	Saves       int    `json:"saves"`
+}
+
+// playedRule is the rule for the numbers only
+// players who took the field can have.
+func (r PerformanceRequest) playedRule(message string) v.Rule {
+	return v.By(func(value interface{}) error {
+		if r.Minutes == 0 && !v.IsEmpty(value) {
+			return errors.New(message)
+		}
+		return nil
+	})
+}
+
+func (r PerformanceRequest) Validate() (*customErrors.ValidationError, error) {
+	err := v.ValidateStruct(&r,
+		v.Field(&r.Player, v.Required.Error("The player is required")),
+		v.Field(&r.Team, v.Required.Error("The player's team is required")),
+		v.Field(&r.Minutes, v.Min(0), v.Max(maxMinutes)),
+		v.Field(&r.Goals, v.Min(0), r.playedRule("Players who did not play can't score")),
+		v.Field(&r.Assists, v.Min(0), r.playedRule("Players who did not play can't assist")),
+		v.Field(&r.YellowCards, v.Min(0), v.Max(2)),
+		v.Field(&r.RedCards, v.Min(0), v.Max(1)),
+		v.Field(&r.Saves, v.Min(0), r.playedRule("Players who did not play can't make saves")),
+	)
+
+	return customErrors.ToValidationError(err,
+		"Parts of the performance supplied are invalid.",
+		"fixtures/invalid-performance")
+}
+
+// Performances lists the player performances recorded for a fixture. It
+// returns (nil, nil) if the fixture does not exist.
+func (db DB) Performances(ctx context.Context, id primitive.ObjectID) ([]Performance, error) {
+	fixture, err := db.ByID(ctx, id)
+	if err != nil || fixture == nil {
+		return nil, err
+	}
+	if fixture.Performances == nil {
+		return []Performance{}, nil
+	}
+	return fixture.Performances, nil
+}
+
+// SubmitPerformance records a player's performance in a fixture that is
+// in play or finished, so it can be completed after the final whistle,
+// replacing the one recorded for them before. It returns (nil, nil) if
+// the fixture does not exist.
+func (db DB) SubmitPerformance(ctx context.Context, id primitive.ObjectID, dto PerformanceRequest) (*Performance, error) {
+	validationErr, err := dto.Validate()
+	if err != nil {
+		return nil, err
+	}
+	if validationErr != nil {
+		return nil, *validationErr
+	}
+	fixture, err := db.ByID(ctx, id)
+	if err != nil || fixture == nil {
+		return nil, err
+	}
+	if !fixture.inPlay() {
+		return nil, fmt.Errorf("%w: performances can't be recorded for a %s fixture", ErrNotInPlay, fixture.Status)
+	}
+	validationErrs := customErrors.ValidationError{
+		Code:    "fixtures/invalid-performance",
+		Message: "Parts of the performance supplied are invalid.",
+		Details: []customErrors.ValidationErrorDetails{},
+	}
+	if dto.Team != fixture.HomeTeam.ID && dto.Team != fixture.AwayTeam.ID {
+		validationErrs.Details = append(validationErrs.Details, customErrors.ValidationErrorDetails{
+			Field:   "team",
+			Message: "The team is not playing in this fixture",
+		})
+	}
+	player, err := db.Players.ByID(ctx, dto.Player)
+	if err != nil {
+		return nil, err
+	}
+	if player == nil {
+		validationErrs.Details = append(validationErrs.Details, customErrors.ValidationErrorDetails{
+			Field:   "player",
+			Message: "Unknown player " + dto.Player,
+		})
+	}
+	if len(validationErrs.Details) > 0 {
+		return nil, validationErrs
+	}
+
+	now := db.now()
+	performance := Performance{
+		Player:      dto.Player,
+		Team:        dto.Team,
+		Minutes:     dto.Minutes,
+		Goals:       dto.Goals,
+		Assists:     dto.Assists,
+		YellowCards: dto.YellowCards,
+		RedCards:    dto.RedCards,
+		Saves:       dto.Saves,
+		UpdatedAt:   now,
+	}
+	performances := []Performance{}
+	for _, other := range fixture.Performances {
+		if other.Player != dto.Player {
+			performances = append(performances, other)
+		}
+	}
+	performances = append(performances, performance)
+	result, err := db.Collection.UpdateOne(ctx, atVersion(fixture),
+		bson.D{{Key: "$set", Value: bson.D{
+			{Key: "performances", Value: performances},
+			{Key: "updated_at", Value: now},
+		}}, nextVersion})
+	if err != nil {
+		return nil, err
+	}
+	if result.MatchedCount == 0 {
+		return nil, ErrConcurrentUpdate
+	}
+	return &performance, nil
+}
+
+// PlayerTotals adds up the performances of a player.
+type PlayerTotals struct {
+	Player      *players.Player `json:"player" bson:"player"`
+	Appearances int             `json:"appearances" bson:"appearances"`
+	Minutes     int             `json:"minutes" bson:"minutes"`
+	Goals       int             `json:"goals" bson:"goals"`
+	Assists     int             `json:"assists" bson:"assists"`
+	YellowCards int             `json:"yellow_cards" bson:"yellow_cards"`
+	RedCards    int             `json:"red_cards" bson:"red_cards"`
+	Saves       int             `json:"saves" bson:"saves"`
+}
+
+// TotalsQuery selects the performances added up by Totals. Only
+// finished fixtures count. Zero dates leave the period open on that side.
+type TotalsQuery struct {
+	Player string
+	From   time.Time
+	To     time.Time
+	// Leaders, if set, lists the players with some of these stats,
+	// ranked by them in order, highest first, then by fewest minutes.
+	Leaders []string
+	Limit   int
+}
+
+// filter matches the finished fixtures with performances in the period.
+func (q TotalsQuery) filter() bson.D {
+	filter := bson.D{{Key: "performances", Value: bson.D{{Key: "$exists", Value: true}}}}
+	if q.Player != "" {
+		filter = bson.D{{Key: "performances.player", Value: q.Player}}
+	}
+	filter = append(filter, bson.E{Key: "status", Value: Finished})
+	dates := bson.D{}
+	if !q.From.IsZero() {
+		dates = append(dates, bson.E{Key: "$gte", Value: q.From})
+	}
+	if !q.To.IsZero() {
+		dates = append(dates, bson.E{Key: "$lt", Value: q.To})
+	}
+	if len(dates) > 0 {
+		filter = append(filter, bson.E{Key: "match_date", Value: dates})
+	}
+	return filter
+}
+
+func totalsQuery(q TotalsQuery) mongo.Pipeline {
+	sum := func(field string) bson.D {
+		return bson.D{{Key: "$sum", Value: "$performances." + field}}
+	}
+	query := mongo.Pipeline{
+		bson.D{{Key: "$match", Value: q.filter()}},
+		bson.D{{Key: "$unwind", Value: "$performances"}},
+	}
+	if q.Player != "" {
+		query = append(query, bson.D{{Key: "$match", Value: bson.D{
+			{Key: "performances.player", Value: q.Player},
+		}}})
+	}
+	query = append(query, bson.D{{Key: "$group", Value: bson.D{
+		{Key: "_id", Value: "$performances.player"},
+		{Key: "appearances", Value: bson.D{{Key: "$sum", Value: bson.D{{Key: "$cond", Value: bson.A{
+			bson.D{{Key: "$gt", Value: bson.A{"$performances.minutes", 0}}}, 1, 0,
+		}}}}}},
+		{Key: "minutes", Value: sum("minutes")},
+		{Key: "goals", Value: sum("goals")},
+		{Key: "assists", Value: sum("assists")},
+		{Key: "yellow_cards", Value: sum("yellow_cards")},
+		{Key: "red_cards", Value: sum("red_cards")},
+		{Key: "saves", Value: sum("saves")},
+	}}})
+	if len(q.Leaders) > 0 {
+		some := bson.A{}
+		sort := bson.D{}
+		for _, stat := range q.Leaders {
+			some = append(some, bson.D{{Key: stat, Value: bson.D{{Key: "$gt", Value: 0}}}})
+			sort = append(sort, bson.E{Key: stat, Value: -1})
+		}
+		sort = append(sort, bson.E{Key: "minutes", Value: 1}, bson.E{Key: "_id", Value: 1})
+		query = append(query,
+			bson.D{{Key: "$match", Value: bson.D{{Key: "$or", Value: some}}}},
+			bson.D{{Key: "$sort", Value: sort}})
+	}
+	// Players who have since been deleted are left out, before the
+	// limit so they don't leave the leaderboard short.
+	query = append(query,
+		bson.D{{Key: "$lookup", Value: bson.D{
+			{Key: "from", Value: database.PlayersCollection},
+			{Key: "localField", Value: "_id"},
+			{Key: "foreignField", Value: "_id"},
+			{Key: "as", Value: "player"},
+		}}},
+		bson.D{{Key: "$unwind", Value: "$player"}})
+	if q.Limit > 0 {
+		query = append(query, bson.D{{Key: "$limit", Value: q.Limit}})
+	}
+	return query
+}
+
+// Totals adds up the performances of players in a period.
+func (db DB) Totals(ctx context.Context, q TotalsQuery) ([]PlayerTotals, error) {
+	cursor, err := db.Collection.Aggregate(ctx, totalsQuery(q))
+	if err != nil {
+		return nil, err
+	}
+	totals := []PlayerTotals{}
+	if err := cursor.All(ctx, &totals); err != nil {
+		return nil, err
+	}
+	return totals, nil
+}
@@ -0,0 +1,76 @@
+package fixtures
+
+import (
+	"testing"
+	"time"
+
+	"gomoney-mock-epl/errors"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestPerformanceRequest_Validate(t *testing.T) {
+	t.Run("Requires the player and team", func(t *testing.T) {
+		validationErr, err := PerformanceRequest{}.Validate()
+		assert.Nil(t, err)
+		assert.Equal(t, "fixtures/invalid-performance", validationErr.Code)
+		assert.Len(t, validationErr.Details, 2)
+	})
+
+	t.Run("Only counts goals for players who played", func(t *testing.T) {
+		validationErr, _ := PerformanceRequest{Player: "salah", Team: "liv", Goals: 1}.Validate()
+		assert.Contains(t, validationErr.Details, errors.ValidationErrorDetails{
+			Field: "goals", Message: "Players who did not play can't score",
+		})
+
+		validationErr, _ = PerformanceRequest{Player: "salah", Team: "liv", Minutes: 90, Goals: 1}.Validate()
+		assert.Nil(t, validationErr)
+	})
+
+	t.Run("Allows cards for unused substitutes", func(t *testing.T) {
+		validationErr, _ := PerformanceRequest{Player: "jones", Team: "liv", YellowCards: 1}.Validate()
+		assert.Nil(t, validationErr)
+	})
+
+	t.Run("Limits the cards and minutes", func(t *testing.T) {
+		validationErr, _ := PerformanceRequest{
+			Player: "salah", Team: "liv", Minutes: 150, YellowCards: 3, RedCards: 2,
+		}.Validate()
+		fields := []string{}
+		for _, detail := range validationErr.Details {
+			fields = append(fields, detail.Field)
+		}
+		assert.ElementsMatch(t, []string{"minutes", "yellow_cards", "red_cards"}, fields)
+	})
+}
+
+func TestTotalsQuery(t *testing.T) {
+	t.Run("Filters one player's fixtures in the period", func(t *testing.T) {
+		from := time.Date(2021, time.August, 14, 0, 0, 0, 0, time.UTC)
+		query := totalsQuery(TotalsQuery{Player: "salah", From: from})
+		assert.Equal(t, bson.D{{Key: "$match", Value: bson.D{
+			{Key: "performances.player", Value: "salah"},
+			{Key: "status", Value: Finished},
+			{Key: "match_date", Value: bson.D{{Key: "$gte", Value: from}}},
+		}}}, query[0])
+		assert.Equal(t, bson.D{{Key: "$match", Value: bson.D{
+			{Key: "performances.player", Value: "salah"},
+		}}}, query[2])
+	})
+
+	t.Run("Ranks leaders by their stats then fewest minutes", func(t *testing.T) {
+		query := totalsQuery(TotalsQuery{Leaders: []string{"red_cards", "yellow_cards"}, Limit: 5})
+		assert.Equal(t, bson.D{{Key: "$match", Value: bson.D{{Key: "$or", Value: bson.A{
+			bson.D{{Key: "red_cards", Value: bson.D{{Key: "$gt", Value: 0}}}},
+			bson.D{{Key: "yellow_cards", Value: bson.D{{Key: "$gt", Value: 0}}}},
+		}}}}}, query[3])
+		assert.Equal(t, bson.D{{Key: "$sort", Value: bson.D{
+			{Key: "red_cards", Value: -1},
+			{Key: "yellow_cards", Value: -1},
+			{Key: "minutes", Value: 1},
+			{Key: "_id", Value: 1},
+		}}}, query[4])
+		assert.Equal(t, bson.D{{Key: "$limit", Value: 5}}, query[len(query)-1])
+	})
+}
@@ -0,0 +1,149 @@
+package fixtures
+
+import (
+	"context"
+	"errors"
+	customErrors "gomoney-mock-epl/errors"
+
+	v "github.com/go-ozzo/ozzo-validation"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Score is the number of goals scored by each side of a fixture.
+type Score struct {
+	Home int `json:"home" bson:"home"`
+	Away int `json:"away" bson:"away"`
+}
+
+func (s Score) Validate() error {
+	return v.ValidateStruct(&s,
+		v.Field(&s.Home, v.Min(0).Error("Goals scored cannot be negative")),
+		v.Field(&s.Away, v.Min(0).Error("Goals scored cannot be negative")),
+	)
+}
+
+// Result is the outcome of a fixture. The half-time score
+// is optional because it is not always known.
+type Result struct {
+	FullTime Score  `json:"full_time" bson:"full_time"`
+	HalfTime *Score `json:"half_time,omitempty" bson:"half_time,omitempty"`
+}
+
+// RecordResultRequest is the DTO we receive from the
+// clients when recording the result of a fixture.
+type RecordResultRequest struct {
+	FullTime Score  `json:"full_time"`
+	HalfTime *Score `json:"half_time"`
+}
+
+func (r RecordResultRequest) Validate() (*customErrors.ValidationError, error) {
+	err := v.ValidateStruct(&r,
+		v.Field(&r.FullTime),
+		v.Field(&r.HalfTime),
+	)
+	if err == nil && r.HalfTime != nil &&
+		(r.HalfTime.Home > r.FullTime.Home || r.HalfTime.Away > r.FullTime.Away) {
+		err = v.Errors{
+			"half_time": errors.New("The half-time score cannot be greater than the full-time score"),
+		}
+	}
+
+	return customErrors.ToValidationError(err,
+		"Parts of the result supplied are invalid.",
+		"fixtures/invalid-result")
+}
+
+func (r RecordResultRequest) toResult() *Result {
+	return &Result{FullTime: r.FullTime, HalfTime: r.HalfTime}
+}
+
+var ErrResultFromEvents = errors.New("the result is derived from the fixture's events")
+
+// withoutEvents matches a fixture as long as it has no events, so that
+// results are not set on fixtures that gain events in the meantime.
+func withoutEvents(id primitive.ObjectID) bson.D {
+	return bson.D{
+		{Key: "_id", Value: id},
+		{Key: "events", Value: bson.D{{Key: "$exists", Value: false}}},
+	}
+}
+
+// RecordResult sets the result of a fixture. Results can only be recorded
+// for fixtures whose match date has passed, and that have no events; the
+// result of a fixture with events is derived from them. It returns
+// (nil, nil) if the fixture does not exist.
+func (db DB) RecordResult(ctx context.Context, id primitive.ObjectID, dto RecordResultRequest) (*Fixture, error) {
+	validationErr, err := dto.Validate()
+	if err != nil {
+		return nil, err
+	}
+	if validationErr != nil {
+		return nil, *validationErr
+	}
+	fixture, err := db.ByID(ctx, id)
+	if err != nil || fixture == nil {
+		return nil, err
+	}
+	if len(fixture.Events) > 0 {
+		return nil, ErrResultFromEvents
+	}
+	now := db.now()
+	if fixture.MatchDate.After(now) {
+		return nil, customErrors.ValidationError{
+			Code:    "fixtures/cannot-record-result",
+			Message: "Results can only be recorded for fixtures that have been played",
+			Details: []customErrors.ValidationErrorDetails{{
+				Field:   "match_date",
+				Message: "The match date of this fixture has not passed",
+			}},
+		}
+	}
+	result, err := db.Collection.UpdateOne(ctx, withoutEvents(id),
+		bson.D{{Key: "$set", Value: bson.D{
+			{Key: "result", Value: dto.toResult()},
+			{Key: "updated_at", Value: now},
+		}}, nextVersion})
+	if err != nil {
+		return nil, err
+	}
+	if result.MatchedCount == 0 {
+		return nil, ErrResultFromEvents
+	}
+	fixture, err = db.ByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	db.publish(ScoreChanged, fixture, nil)
+	return fixture, nil
+}
+
+// ClearResult removes the recorded result of a fixture that has no events.
+// It returns (nil, nil) if the fixture does not exist.
+func (db DB) ClearResult(ctx context.Context, id primitive.ObjectID) (*Fixture, error) {
+	fixture, err := db.ByID(ctx, id)
+	if err != nil || fixture == nil {
+		return nil, err
+	}
+	if len(fixture.Events) > 0 {
+		return nil, ErrResultFromEvents
+	}
+	result, err := db.Collection.UpdateOne(ctx, withoutEvents(id),
+		bson.D{
+			{Key: "$unset", Value: bson.D{{Key: "result", Value: ""}}},
+			{Key: "$set", Value: bson.D{{Key: "updated_at", Value: db.now()}}},
+			nextVersion,
+		})
+	if err != nil {
+		return nil, err
+	}
+	if result.MatchedCount == 0 {
+		return nil, ErrResultFromEvents
+	}
+	fixture, err = db.ByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	db.publish(ScoreChanged, fixture, nil)
+	return fixture, nil
+}
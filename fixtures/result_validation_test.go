@@ -0,0 +1,42 @@
+package fixtures
+
+import (
+	"testing"
+
+	"gomoney-mock-epl/errors"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecordResultRequest_Validate(t *testing.T) {
+	t.Run("Rejects negative scores", func(t *testing.T) {
+		dto := RecordResultRequest{FullTime: Score{Home: -1, Away: 0}}
+		validationError, internalError := dto.Validate()
+		assert.Nil(t, internalError)
+		assert.Equal(t, "fixtures/invalid-result", validationError.Code)
+		assert.NotEmpty(t, validationError.Details)
+	})
+
+	t.Run("Rejects half-time scores greater than full-time scores", func(t *testing.T) {
+		dto := RecordResultRequest{
+			FullTime: Score{Home: 1, Away: 0},
+			HalfTime: &Score{Home: 2, Away: 0},
+		}
+		validationError, internalError := dto.Validate()
+		assert.Nil(t, internalError)
+		assert.Contains(t, validationError.Details, errors.ValidationErrorDetails{
+			Field:   "half_time",
+			Message: "The half-time score cannot be greater than the full-time score",
+		})
+	})
+
+	t.Run("Accepts a valid result", func(t *testing.T) {
+		dto := RecordResultRequest{
+			FullTime: Score{Home: 3, Away: 2},
+			HalfTime: &Score{Home: 1, Away: 2},
+		}
+		validationError, internalError := dto.Validate()
+		assert.Nil(t, internalError)
+		assert.Nil(t, validationError)
+	})
+}
@@ -0,0 +1,177 @@
+package fixtures
+
+import (
+	"context"
+	"fmt"
+	customErrors "gomoney-mock-epl/errors"
+	"gomoney-mock-epl/seasons"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// checkSeason validates that a fixture fits in the season it is placed in:
+// both teams must take part in the season, the match date must fall within
+// it, and the matchweek must be one of its matchweeks. Fixtures without a
+// season can't have a matchweek.
+func (db DB) checkSeason(ctx context.Context, seasonID, homeTeam, awayTeam string, matchDate time.Time, matchweek int) ([]customErrors.ValidationErrorDetails, error) {
+	details := []customErrors.ValidationErrorDetails{}
+	if seasonID == "" {
+		if matchweek != 0 {
+			details = append(details, customErrors.ValidationErrorDetails{
+				Field:   "matchweek",
+				Message: "Only fixtures in a season can have a matchweek",
+			})
+		}
+		return details, nil
+	}
+	season, err := db.SeasonsDB.ByID(ctx, seasonID)
+	if err != nil {
+		return nil, err
+	}
+	if season == nil {
+		return append(details, customErrors.ValidationErrorDetails{
+			Field:   "season",
+			Message: "Unknown season",
+		}), nil
+	}
+	if !season.Includes(homeTeam) {
+		details = append(details, customErrors.ValidationErrorDetails{
+			Field:   "home_team",
+			Message: fmt.Sprintf("The home team does not play in the %s season", season.Name),
+		})
+	}
+	if !season.Includes(awayTeam) {
+		details = append(details, customErrors.ValidationErrorDetails{
+			Field:   "away_team",
+			Message: fmt.Sprintf("The away team does not play in the %s season", season.Name),
+		})
+	}
+	if !season.Covers(matchDate) {
+		details = append(details, customErrors.ValidationErrorDetails{
+			Field:   "match_date",
+			Message: fmt.Sprintf("The match date is outside the %s season", season.Name),
+		})
+	}
+	if matchweek < 0 || matchweek > season.Matchweeks() {
+		details = append(details, customErrors.ValidationErrorDetails{
+			Field:   "matchweek",
+			Message: fmt.Sprintf("The %s season has matchweeks 1 to %d", season.Name, season.Matchweeks()),
+		})
+	}
+	return details, nil
+}
+
+func listSeasonFixturesQuery(seasonID string, matchweek int) mongo.Pipeline {
+	filter := bson.D{{Key: "season", Value: seasonID}}
+	if matchweek > 0 {
+		filter = append(filter, bson.E{Key: "matchweek", Value: matchweek})
+	}
+	match := mongo.Pipeline{
+		bson.D{{Key: "$match", Value: filter}},
+		bson.D{{Key: "$sort", Value: bson.D{
+			{Key: "matchweek", Value: 1},
+			{Key: "match_date", Value: 1},
+		}}},
+	}
+	return append(match, restFindStages()...)
+}
+
+// BySeason lists the fixtures in a season in matchweek order.
+// If matchweek is not zero, only fixtures in that matchweek are listed.
+func (db DB) BySeason(ctx context.Context, seasonID string, matchweek int) ([]Fixture, error) {
+	cursor, err := db.Collection.Aggregate(ctx, listSeasonFixturesQuery(seasonID, matchweek))
+	if err != nil {
+		return nil, err
+	}
+	fixtures := []Fixture{}
+	if err := cursor.All(ctx, &fixtures); err != nil {
+		return nil, err
+	}
+	return fixtures, nil
+}
+
+// seasonSpan is what the fixtures in a season take up of it.
+type seasonSpan struct {
+	First     time.Time `bson:"first"`
+	Last      time.Time `bson:"last"`
+	Matchweek int       `bson:"matchweek"`
+	HomeTeams []string  `bson:"home_teams"`
+	AwayTeams []string  `bson:"away_teams"`
+}
+
+// CheckSeason lists what keeps the fixtures in the season from fitting
+// in it as it would be: teams taken out of it that still play fixtures
+// in it, dates that leave fixtures out, and matchweeks lost with the
+// teams taken out.
+func (db DB) CheckSeason(ctx context.Context, season seasons.Season) ([]customErrors.ValidationErrorDetails, error) {
+	cursor, err := db.Collection.Aggregate(ctx, mongo.Pipeline{
+		bson.D{{Key: "$match", Value: bson.D{{Key: "season", Value: season.ID}}}},
+		bson.D{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: nil},
+			{Key: "first", Value: bson.D{{Key: "$min", Value: "$match_date"}}},
+			{Key: "last", Value: bson.D{{Key: "$max", Value: "$match_date"}}},
+			{Key: "matchweek", Value: bson.D{{Key: "$max", Value: "$matchweek"}}},
+			{Key: "home_teams", Value: bson.D{{Key: "$addToSet", Value: "$home_team"}}},
+			{Key: "away_teams", Value: bson.D{{Key: "$addToSet", Value: "$away_team"}}},
+		}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	spans := []seasonSpan{}
+	if err := cursor.All(ctx, &spans); err != nil {
+		return nil, err
+	}
+	details := []customErrors.ValidationErrorDetails{}
+	if len(spans) == 0 {
+		return details, nil
+	}
+	span := spans[0]
+	playing := map[string]bool{}
+	for _, team := range append(span.HomeTeams, span.AwayTeams...) {
+		if !season.Includes(team) {
+			playing[team] = true
+		}
+	}
+	removed := make([]string, 0, len(playing))
+	for team := range playing {
+		removed = append(removed, team)
+	}
+	sort.Strings(removed)
+	for _, team := range removed {
+		details = append(details, customErrors.ValidationErrorDetails{
+			Field:   "teams",
+			Message: fmt.Sprintf("Team %s still plays fixtures in the season", team),
+		})
+	}
+	if span.First.Before(season.StartDate) {
+		details = append(details, customErrors.ValidationErrorDetails{
+			Field:   "start_date",
+			Message: fmt.Sprintf("The season's first fixture is on %s", span.First.UTC().Format(time.RFC3339)),
+		})
+	}
+	if span.Last.After(season.EndDate) {
+		details = append(details, customErrors.ValidationErrorDetails{
+			Field:   "end_date",
+			Message: fmt.Sprintf("The season's last fixture is on %s", span.Last.UTC().Format(time.RFC3339)),
+		})
+	}
+	if span.Matchweek > season.Matchweeks() {
+		details = append(details, customErrors.ValidationErrorDetails{
+			Field:   "teams",
+			Message: fmt.Sprintf("Fixtures are placed in matchweek %d, but the season would have %d", span.Matchweek, season.Matchweeks()),
+		})
+	}
+	return details, nil
+}
+
+// SeasonInUse reports whether any fixture is placed in the season.
+func (db DB) SeasonInUse(ctx context.Context, seasonID string) (bool, error) {
+	count, err := db.Collection.CountDocuments(ctx, bson.D{{Key: "season", Value: seasonID}},
+		options.Count().SetLimit(1))
+	return count > 0, err
+}
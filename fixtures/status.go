@@ -0,0 +1,140 @@
+package fixtures
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	customErrors "gomoney-mock-epl/errors"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Status is the stage of its lifecycle that a fixture is in.
+type Status string
+
+const (
+	Scheduled = Status("scheduled")
+	Live      = Status("live")
+	HalfTime  = Status("half_time")
+	Finished  = Status("finished")
+	Postponed = Status("postponed")
+	Abandoned = Status("abandoned")
+	Cancelled = Status("cancelled")
+)
+
+// transitions lists the statuses a fixture can move to from each status.
+// Finished and cancelled fixtures are final. Postponed and abandoned
+// fixtures can be rescheduled.
+var transitions = map[Status][]Status{
+	Scheduled: {Live, Postponed, Cancelled},
+	Live:      {HalfTime, Finished, Abandoned},
+	HalfTime:  {Live, Abandoned},
+	Postponed: {Scheduled, Cancelled},
+	Abandoned: {Scheduled, Cancelled},
+}
+
+// NewFixtureStatus parses a fixture status. "pending" and "completed"
+// are accepted for compatibility and stand for scheduled and finished
+// fixtures respectively. It returns "" for unknown statuses.
+func NewFixtureStatus(s string) Status {
+	switch status := Status(s); status {
+	case "pending":
+		return Scheduled
+	case "completed":
+		return Finished
+	case Scheduled, Live, HalfTime, Finished, Postponed, Abandoned, Cancelled:
+		return status
+	}
+	return ""
+}
+
+// CanTransitionTo reports whether a fixture in status s can move to next.
+func (s Status) CanTransitionTo(next Status) bool {
+	for _, allowed := range transitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// statusFilter matches fixtures in the given status. Fixtures saved
+// before statuses were persisted have none, and are treated as scheduled.
+func statusFilter(status Status) interface{} {
+	if status == Scheduled {
+		return bson.D{{Key: "$in", Value: bson.A{Scheduled, nil}}}
+	}
+	return status
+}
+
+var ErrIllegalTransition = errors.New("illegal fixture status transition")
+
+// TransitionRequest is the DTO we receive from the
+// clients when changing the status of a fixture.
+type TransitionRequest struct {
+	Status string `json:"status"`
+}
+
+// Transition moves a fixture to another status. It fails with
+// ErrIllegalTransition if the fixture cannot move to that status from its
+// current one, and with ErrOfficialUnavailable if it's rescheduled while
+// its officials work another fixture at the time. It returns (nil, nil)
+// if the fixture does not exist.
+func (db DB) Transition(ctx context.Context, id primitive.ObjectID, dto TransitionRequest) (*Fixture, error) {
+	next := NewFixtureStatus(dto.Status)
+	if next == "" || string(next) != dto.Status {
+		return nil, customErrors.ValidationError{
+			Code:    "fixtures/invalid-status",
+			Message: "Your request to change the fixture status failed",
+			Details: []customErrors.ValidationErrorDetails{{
+				Field:   "status",
+				Message: fmt.Sprintf("Unknown fixture status %q", dto.Status),
+			}},
+		}
+	}
+	fixture, err := db.ByID(ctx, id)
+	if err != nil || fixture == nil {
+		return nil, err
+	}
+	if !fixture.Status.CanTransitionTo(next) {
+		return nil, fmt.Errorf("%w: a %s fixture cannot become %s",
+			ErrIllegalTransition, fixture.Status, next)
+	}
+	if next == Scheduled {
+		// Postponed fixtures let their officials go, so they may have
+		// been assigned to another fixture at the time since.
+		if err := db.checkClashes(ctx, id, fixture.officialIDs(), fixture.MatchDate); err != nil {
+			return nil, err
+		}
+	}
+	changes := bson.D{
+		{Key: "status", Value: next},
+		{Key: "updated_at", Value: db.now()},
+	}
+	if len(fixture.Events) > 0 {
+		// The half-time score becomes known at half-time.
+		changes = append(changes, bson.E{
+			Key: "result", Value: deriveResult(fixture.Events, fixture.HomeTeam.ID, fixture.halfTimeReached(next)),
+		})
+	}
+	result, err := db.Collection.UpdateOne(ctx,
+		bson.D{
+			{Key: "_id", Value: id},
+			{Key: "status", Value: statusFilter(fixture.Status)},
+		},
+		bson.D{{Key: "$set", Value: changes}, nextVersion})
+	if err != nil {
+		return nil, err
+	}
+	if result.MatchedCount == 0 {
+		return nil, fmt.Errorf("%w: the fixture status changed during the update",
+			ErrIllegalTransition)
+	}
+	fixture, err = db.ByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	db.publish(StatusChanged, fixture, nil)
+	return fixture, nil
+}
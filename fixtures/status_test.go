@@ -0,0 +1,40 @@
+package fixtures
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatus_CanTransitionTo(t *testing.T) {
+	t.Run("Allows a match to be played out", func(t *testing.T) {
+		assert.True(t, Scheduled.CanTransitionTo(Live))
+		assert.True(t, Live.CanTransitionTo(HalfTime))
+		assert.True(t, HalfTime.CanTransitionTo(Live))
+		assert.True(t, Live.CanTransitionTo(Finished))
+	})
+
+	t.Run("Allows postponed and abandoned matches to be rescheduled", func(t *testing.T) {
+		assert.True(t, Postponed.CanTransitionTo(Scheduled))
+		assert.True(t, Abandoned.CanTransitionTo(Scheduled))
+	})
+
+	t.Run("Treats finished and cancelled matches as final", func(t *testing.T) {
+		for _, next := range []Status{Scheduled, Live, HalfTime, Postponed, Abandoned, Cancelled} {
+			assert.False(t, Finished.CanTransitionTo(next))
+			assert.False(t, Cancelled.CanTransitionTo(next))
+		}
+	})
+
+	t.Run("Refuses skipping kick-off", func(t *testing.T) {
+		assert.False(t, Scheduled.CanTransitionTo(Finished))
+		assert.False(t, Scheduled.CanTransitionTo(HalfTime))
+	})
+}
+
+func TestNewFixtureStatus(t *testing.T) {
+	assert.Equal(t, Scheduled, NewFixtureStatus("pending"))
+	assert.Equal(t, Finished, NewFixtureStatus("completed"))
+	assert.Equal(t, HalfTime, NewFixtureStatus("half_time"))
+	assert.Equal(t, Status(""), NewFixtureStatus("unknown"))
+}
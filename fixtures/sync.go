@@ -0,0 +1,125 @@
+package fixtures
+
+import (
+	"context"
+	"gomoney-mock-epl/teams"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// teamCopies are the fields fixtures copy from their teams, by the
+// field holding the team's ID.
+var teamCopies = map[string]string{
+	"home_team": "home_team_name",
+	"away_team": "away_team_name",
+}
+
+// NameFix is a team name a resync rewrote in a fixture.
+type NameFix struct {
+	Fixture primitive.ObjectID `json:"fixture"`
+	Field   string             `json:"field"`
+	Was     string             `json:"was"`
+	Now     string             `json:"now"`
+}
+
+// ResyncReport is what a resync changed. Fixtures between teams that no
+// longer exist can't be repaired, so they're only listed.
+type ResyncReport struct {
+	Fixed    []NameFix            `json:"fixed"`
+	Orphaned []primitive.ObjectID `json:"orphaned"`
+}
+
+// driftFilter matches the fixtures the team plays on the side, where
+// the copy of its name is not its name.
+func driftFilter(side string, team teams.Team) bson.D {
+	return bson.D{
+		{Key: side, Value: team.ID},
+		{Key: teamCopies[side], Value: bson.D{{Key: "$ne", Value: team.Name}}},
+	}
+}
+
+// syncTeam rewrites the copies of the team's name in its fixtures, and
+// returns what it rewrote. The fixtures are marked as updated, as they
+// show under the new name, so calendars pick up the change.
+func (db DB) syncTeam(ctx context.Context, team teams.Team) ([]NameFix, error) {
+	fixes := []NameFix{}
+	for _, side := range []string{"home_team", "away_team"} {
+		field := teamCopies[side]
+		filter := driftFilter(side, team)
+		cursor, err := db.Collection.Find(ctx, filter,
+			options.Find().SetProjection(bson.D{{Key: field, Value: 1}}))
+		if err != nil {
+			return nil, err
+		}
+		drifted := []bson.M{}
+		if err := cursor.All(ctx, &drifted); err != nil {
+			return nil, err
+		}
+		if len(drifted) == 0 {
+			continue
+		}
+		_, err = db.Collection.UpdateMany(ctx, filter, bson.D{
+			{Key: "$set", Value: bson.D{
+				{Key: field, Value: team.Name},
+				{Key: "updated_at", Value: db.now()},
+			}},
+			nextVersion,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, fixture := range drifted {
+			id, _ := fixture["_id"].(primitive.ObjectID)
+			was, _ := fixture[field].(string)
+			fixes = append(fixes, NameFix{Fixture: id, Field: field, Was: was, Now: team.Name})
+		}
+	}
+	return fixes, nil
+}
+
+// SyncTeam rewrites the copies of the team's name in its fixtures. It
+// keeps fixtures in sync as teams are updated.
+func (db DB) SyncTeam(ctx context.Context, team teams.Team) error {
+	_, err := db.syncTeam(ctx, team)
+	return err
+}
+
+// Resync repairs the copies of team names in every fixture, and
+// reports what it changed.
+func (db DB) Resync(ctx context.Context) (*ResyncReport, error) {
+	allTeams, err := db.TeamsDB.All(ctx)
+	if err != nil {
+		return nil, err
+	}
+	report := &ResyncReport{Fixed: []NameFix{}, Orphaned: []primitive.ObjectID{}}
+	teamIDs := make([]string, 0, len(allTeams))
+	for _, team := range allTeams {
+		fixes, err := db.syncTeam(ctx, team)
+		if err != nil {
+			return nil, err
+		}
+		report.Fixed = append(report.Fixed, fixes...)
+		teamIDs = append(teamIDs, team.ID)
+	}
+	cursor, err := db.Collection.Find(ctx,
+		bson.D{{Key: "$or", Value: bson.A{
+			bson.D{{Key: "home_team", Value: bson.D{{Key: "$nin", Value: teamIDs}}}},
+			bson.D{{Key: "away_team", Value: bson.D{{Key: "$nin", Value: teamIDs}}}},
+		}}},
+		options.Find().SetProjection(bson.D{{Key: "_id", Value: 1}}).SetSort(bson.D{{Key: "_id", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	orphans := []struct {
+		ID primitive.ObjectID `bson:"_id"`
+	}{}
+	if err := cursor.All(ctx, &orphans); err != nil {
+		return nil, err
+	}
+	for _, orphan := range orphans {
+		report.Orphaned = append(report.Orphaned, orphan.ID)
+	}
+	return report, nil
+}
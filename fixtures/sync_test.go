@@ -0,0 +1,18 @@
+package fixtures
+
+import (
+	"testing"
+
+	"gomoney-mock-epl/teams"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestDriftFilter(t *testing.T) {
+	team := teams.Team{ID: "liverpool", Name: "Liverpool FC"}
+	assert.Equal(t, bson.D{
+		{Key: "away_team", Value: "liverpool"},
+		{Key: "away_team_name", Value: bson.D{{Key: "$ne", Value: "Liverpool FC"}}},
+	}, driftFilter("away_team", team))
+}
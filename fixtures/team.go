@@ -0,0 +1,144 @@
+package fixtures
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// playedBy matches the fixtures a team plays in, home or away.
+func playedBy(teamID string) bson.E {
+	return bson.E{Key: "$or", Value: bson.A{
+		bson.D{{Key: "home_team", Value: teamID}},
+		bson.D{{Key: "away_team", Value: teamID}},
+	}}
+}
+
+// hasFinalResult matches finished fixtures with a result. Fixtures in
+// play, and abandoned fixtures with a partial score, are left out.
+var hasFinalResult = bson.D{
+	{Key: "status", Value: Finished},
+	{Key: "result", Value: bson.D{{Key: "$exists", Value: true}}},
+}
+
+func latestFirstQuery(filter bson.D) mongo.Pipeline {
+	match := mongo.Pipeline{
+		bson.D{{Key: "$match", Value: filter}},
+		bson.D{{Key: "$sort", Value: bson.D{{Key: "match_date", Value: -1}}}},
+	}
+	return append(match, restFindStages()...)
+}
+
+// latestFirst lists the fixtures matching the filter, latest first.
+func (db DB) latestFirst(ctx context.Context, filter bson.D) ([]Fixture, error) {
+	cursor, err := db.Collection.Aggregate(ctx, latestFirstQuery(filter))
+	if err != nil {
+		return nil, err
+	}
+	fixtures := []Fixture{}
+	if err := cursor.All(ctx, &fixtures); err != nil {
+		return nil, err
+	}
+	return fixtures, nil
+}
+
+// TeamFixtures lists all the fixtures a team plays, latest first.
+func (db DB) TeamFixtures(ctx context.Context, teamID string) ([]Fixture, error) {
+	return db.latestFirst(ctx, bson.D{playedBy(teamID)})
+}
+
+// CountByTeam counts the fixtures a team plays in.
+func (db DB) CountByTeam(ctx context.Context, teamID string) (int64, error) {
+	return db.Collection.CountDocuments(ctx, bson.D{playedBy(teamID)})
+}
+
+// TeamFixtureIDs lists the IDs of a team's first fixtures by match
+// date, up to limit.
+func (db DB) TeamFixtureIDs(ctx context.Context, teamID string, limit int64) ([]primitive.ObjectID, error) {
+	cursor, err := db.Collection.Find(ctx, bson.D{playedBy(teamID)}, options.Find().
+		SetProjection(bson.D{{Key: "_id", Value: 1}}).
+		SetSort(bson.D{{Key: "match_date", Value: 1}, {Key: "_id", Value: 1}}).
+		SetLimit(limit))
+	if err != nil {
+		return nil, err
+	}
+	found := []struct {
+		ID primitive.ObjectID `bson:"_id"`
+	}{}
+	if err := cursor.All(ctx, &found); err != nil {
+		return nil, err
+	}
+	ids := make([]primitive.ObjectID, 0, len(found))
+	for _, fixture := range found {
+		ids = append(ids, fixture.ID)
+	}
+	return ids, nil
+}
+
+// DeleteTeamFixtures removes the fixtures a team plays in, publishes
+// them as they were, and returns how many there were.
+func (db DB) DeleteTeamFixtures(ctx context.Context, teamID string) (int64, error) {
+	deleted, err := db.TeamFixtures(ctx, teamID)
+	if err != nil {
+		return 0, err
+	}
+	result, err := db.Collection.DeleteMany(ctx, bson.D{playedBy(teamID)})
+	if err != nil {
+		return 0, err
+	}
+	for i := range deleted {
+		db.publish(FixtureDeleted, &deleted[i], nil)
+	}
+	return result.DeletedCount, nil
+}
+
+// TeamResults lists the finished fixtures a team has a result for,
+// latest first.
+func (db DB) TeamResults(ctx context.Context, teamID string) ([]Fixture, error) {
+	return db.latestFirst(ctx, append(bson.D{playedBy(teamID)}, hasFinalResult...))
+}
+
+// ResultsBetween lists the finished fixtures between two teams, latest
+// first, whichever team was at home.
+func (db DB) ResultsBetween(ctx context.Context, teamID, otherTeamID string) ([]Fixture, error) {
+	return db.latestFirst(ctx, append(bson.D{{Key: "$or", Value: bson.A{
+		bson.D{{Key: "home_team", Value: teamID}, {Key: "away_team", Value: otherTeamID}},
+		bson.D{{Key: "home_team", Value: otherTeamID}, {Key: "away_team", Value: teamID}},
+	}}}, hasFinalResult...))
+}
+
+func nextFixtureQuery(teamID string, now time.Time) mongo.Pipeline {
+	match := mongo.Pipeline{
+		bson.D{
+			{Key: "$match", Value: bson.D{
+				playedBy(teamID),
+				{Key: "status", Value: statusFilter(Scheduled)},
+				{Key: "match_date", Value: bson.D{{Key: "$gte", Value: now}}},
+			}},
+		},
+		bson.D{{Key: "$sort", Value: bson.D{{Key: "match_date", Value: 1}}}},
+		bson.D{{Key: "$limit", Value: 1}},
+	}
+	return append(match, restFindStages()...)
+}
+
+// NextFixture finds the next scheduled fixture a team plays in.
+// It returns (nil, nil) if the team has no upcoming fixtures.
+func (db DB) NextFixture(ctx context.Context, teamID string) (*Fixture, error) {
+	cursor, err := db.Collection.Aggregate(ctx, nextFixtureQuery(teamID, db.now()))
+	if err != nil {
+		return nil, err
+	}
+	fixture := []Fixture{}
+	if err := cursor.All(ctx, &fixture); err != nil {
+		return nil, err
+	}
+	if len(fixture) == 0 {
+		return nil, nil
+	}
+	return &fixture[0], nil
+}
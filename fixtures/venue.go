@@ -0,0 +1,137 @@
+package fixtures
+
+import (
+	"context"
+	customErrors "gomoney-mock-epl/errors"
+	"gomoney-mock-epl/stadiums"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// checkVenue validates that the stadium a fixture is moved to exists.
+func (db DB) checkVenue(ctx context.Context, venue string) ([]customErrors.ValidationErrorDetails, error) {
+	if venue == "" {
+		return nil, nil
+	}
+	stadium, err := db.Stadiums.ByID(ctx, venue)
+	if err != nil {
+		return nil, err
+	}
+	if stadium == nil {
+		return []customErrors.ValidationErrorDetails{{
+			Field:   "venue",
+			Message: "Unknown stadium",
+		}}, nil
+	}
+	return nil, nil
+}
+
+// venue is the ID of the stadium the fixture is played at, if known.
+func (f Fixture) venue() string {
+	if f.Venue != "" || f.HomeTeam == nil {
+		return f.Venue
+	}
+	return f.HomeTeam.Stadium
+}
+
+// NearQuery finds the scheduled fixtures played within a radius of
+// a point, from a date.
+type NearQuery struct {
+	Latitude  float64
+	Longitude float64
+	RadiusKM  float64
+	From      time.Time
+	Limit     int
+}
+
+// NearbyFixture is a fixture, the stadium it's played
+// at, and how far away that stadium is.
+type NearbyFixture struct {
+	Fixture
+	Stadium    stadiums.Stadium `json:"stadium"`
+	DistanceKM float64          `json:"distance_km"`
+}
+
+// nearQuery matches the fixtures played at the stadiums, either because
+// they were moved there or because they are the home team's stadium.
+func nearQuery(stadiumIDs, homeTeamIDs []string, from time.Time, limit int) mongo.Pipeline {
+	query := mongo.Pipeline{
+		bson.D{{Key: "$match", Value: bson.D{
+			{Key: "status", Value: statusFilter(Scheduled)},
+			{Key: "match_date", Value: bson.D{{Key: "$gte", Value: from}}},
+			{Key: "$or", Value: bson.A{
+				bson.D{{Key: "venue", Value: bson.D{{Key: "$in", Value: stadiumIDs}}}},
+				bson.D{
+					{Key: "venue", Value: bson.D{{Key: "$exists", Value: false}}},
+					{Key: "home_team", Value: bson.D{{Key: "$in", Value: homeTeamIDs}}},
+				},
+			}},
+		}}},
+		bson.D{{Key: "$sort", Value: bson.D{{Key: "match_date", Value: 1}, {Key: "_id", Value: 1}}}},
+	}
+	if limit > 0 {
+		query = append(query, bson.D{{Key: "$limit", Value: limit}})
+	}
+	return append(query, restFindStages()...)
+}
+
+// Near lists the scheduled fixtures played within a radius of a point
+// from a date, soonest first.
+func (db DB) Near(ctx context.Context, q NearQuery) ([]NearbyFixture, error) {
+	nearby, err := db.Stadiums.Near(ctx, *stadiums.NewPoint(q.Latitude, q.Longitude), q.RadiusKM)
+	if err != nil {
+		return nil, err
+	}
+	if len(nearby) == 0 {
+		return []NearbyFixture{}, nil
+	}
+	byID := map[string]stadiums.NearbyStadium{}
+	stadiumIDs := make([]string, 0, len(nearby))
+	for _, stadium := range nearby {
+		byID[stadium.ID] = stadium
+		stadiumIDs = append(stadiumIDs, stadium.ID)
+	}
+	homeTeams, err := db.TeamsDB.AtStadiums(ctx, stadiumIDs)
+	if err != nil {
+		return nil, err
+	}
+	homeTeamIDs := make([]string, 0, len(homeTeams))
+	for _, team := range homeTeams {
+		homeTeamIDs = append(homeTeamIDs, team.ID)
+	}
+	cursor, err := db.Collection.Aggregate(ctx, nearQuery(stadiumIDs, homeTeamIDs, q.From, q.Limit))
+	if err != nil {
+		return nil, err
+	}
+	fixtures := []Fixture{}
+	if err := cursor.All(ctx, &fixtures); err != nil {
+		return nil, err
+	}
+	nearbyFixtures := make([]NearbyFixture, 0, len(fixtures))
+	for _, fixture := range fixtures {
+		stadium, ok := byID[fixture.venue()]
+		if !ok {
+			// The home team moved stadium since it was looked up.
+			continue
+		}
+		nearbyFixtures = append(nearbyFixtures, NearbyFixture{
+			Fixture:    fixture,
+			Stadium:    stadium.Stadium,
+			DistanceKM: stadium.DistanceKM,
+		})
+	}
+	return nearbyFixtures, nil
+}
+
+// StadiumInUse reports whether any team plays at a stadium, or any
+// fixture has been moved there.
+func (db DB) StadiumInUse(ctx context.Context, stadiumID string) (bool, error) {
+	teams, err := db.TeamsDB.AtStadiums(ctx, []string{stadiumID})
+	if err != nil || len(teams) > 0 {
+		return len(teams) > 0, err
+	}
+	count, err := db.Collection.CountDocuments(ctx, bson.D{{Key: "venue", Value: stadiumID}})
+	return count > 0, err
+}
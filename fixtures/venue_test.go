@@ -0,0 +1,17 @@
+package fixtures
+
+import (
+	"testing"
+
+	"gomoney-mock-epl/teams"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFixture_venue(t *testing.T) {
+	fixture := Fixture{HomeTeam: &teams.Team{ID: "liv", Stadium: "anfield"}}
+	assert.Equal(t, "anfield", fixture.venue())
+
+	fixture.Venue = "wembley"
+	assert.Equal(t, "wembley", fixture.venue())
+}
@@ -2,21 +2,45 @@ package grifts
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"gomoney-mock-epl/backup"
 	"gomoney-mock-epl/config"
 	"gomoney-mock-epl/database"
 	"gomoney-mock-epl/fixtures"
+	"gomoney-mock-epl/schedule"
+	"gomoney-mock-epl/seasons"
+	"gomoney-mock-epl/stadiums"
 	"gomoney-mock-epl/teams"
 	"gomoney-mock-epl/users"
 	"gomoney-mock-epl/web"
 	"io/ioutil"
 	"math/rand"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"time"
 
 	. "github.com/markbates/grift/grift"
 	"syreclabs.com/go/faker"
 )
 
+// archiveCounts lists the number of documents of each collection in an
+// archive.
+func archiveCounts(counts map[string]int) string {
+	collections := []string{
+		database.StadiumsCollection, database.TeamsCollection, database.SeasonsCollection,
+		database.OfficialsCollection, database.PlayersCollection, database.TransfersCollection,
+		database.FixturesCollection, database.UsersCollection, database.AdminsCollection,
+	}
+	listed := make([]string, 0, len(collections))
+	for _, collection := range collections {
+		listed = append(listed, fmt.Sprintf("%d %s", counts[collection], collection))
+	}
+	return strings.Join(listed[:len(listed)-1], ", ") + " and " + listed[len(listed)-1]
+}
+
 func panicOnErr(err error) {
 	if err != nil {
 		panic(err)
@@ -60,7 +84,12 @@ var _ = Namespace("db", func() {
 		err = json.Unmarshal(jsonBytes, &seedTeams)
 		panicOnErr(err)
 		tms := teamsFromSeedData(seedTeams)
-		for _, t := range tms {
+		for i, t := range tms {
+			stadium, err := app.StadiumsDB.Create(c, toStadiumRequest(seedTeams[i].Grounds[0]))
+			if err != nil {
+				return err
+			}
+			t.Stadium = stadium.ID
 			if _, err := app.TeamsDB.Create(c, t); err != nil {
 				return err
 			}
@@ -68,16 +97,144 @@ var _ = Namespace("db", func() {
 		return nil
 	})
 
-	Desc("create-fixtures", "Seed database with fixtures")
+	Desc("create-fixtures", "Seed database with a season of fixtures between 20 teams")
 	Add("create-fixtures", func(c *Context) error {
 		existingTeams, err := app.TeamsDB.List(c)
 		panicOnErr(err)
-		for i := 0; i < len(existingTeams)-1; i++ {
-			req := makeFixtureRequest(existingTeams[i], existingTeams[i+1])
-			if _, err := app.FixturesDB.Create(c, req); err != nil {
-				return err
+		season, err := app.SeasonsDB.Create(c, makeSeasonRequest(existingTeams, time.Now()))
+		if err != nil {
+			return err
+		}
+		fixtures, err := schedule.Scheduler{DB: app.FixturesDB}.Schedule(c, season.ID, schedule.Options{})
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Scheduled %d fixtures in the %s season (%s)\n", len(fixtures), season.Name, season.ID)
+		return nil
+	})
+
+	Desc("schedule-season", "Generate the fixtures of a season. Usage: grift db:schedule-season <season ID> [seed]")
+	Add("schedule-season", func(c *Context) error {
+		if len(c.Args) < 1 {
+			return errors.New("a season ID is required")
+		}
+		opts := schedule.Options{}
+		if len(c.Args) > 1 {
+			seed, err := strconv.ParseInt(c.Args[1], 10, 64)
+			if err != nil {
+				return fmt.Errorf("the seed must be a number: %w", err)
+			}
+			opts.Seed = seed
+		}
+		fixtures, err := schedule.Scheduler{DB: app.FixturesDB}.Schedule(c, c.Args[0], opts)
+		if err != nil {
+			return err
+		}
+		if fixtures == nil {
+			return fmt.Errorf("season %q does not exist", c.Args[0])
+		}
+		fmt.Printf("Scheduled %d fixtures\n", len(fixtures))
+		return nil
+	})
+
+	Desc("import-fixtures", "Import fixtures from a CSV or JSON lines file. Usage: grift db:import-fixtures <file> [dry-run]")
+	Add("import-fixtures", func(c *Context) error {
+		if len(c.Args) < 1 {
+			return errors.New("a file is required")
+		}
+		dryRun := len(c.Args) > 1 && c.Args[1] == "dry-run"
+		f, err := os.Open(c.Args[0])
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		parse := fixtures.ParseCSV
+		if ext := strings.ToLower(filepath.Ext(c.Args[0])); ext == ".jsonl" || ext == ".ndjson" {
+			parse = fixtures.ParseJSONLines
+		}
+		rows, err := parse(f)
+		if err != nil {
+			return err
+		}
+		result, err := app.FixturesDB.Import(c, rows, dryRun)
+		if err != nil {
+			return err
+		}
+		for _, row := range result.Rows {
+			for _, rowErr := range row.Errors {
+				fmt.Printf("line %d: %s: %s\n", row.Line, rowErr.Field, rowErr.Message)
 			}
 		}
+		switch {
+		case !result.Valid:
+			return errors.New("some rows are invalid, nothing was imported")
+		case dryRun:
+			fmt.Printf("%d fixtures can be imported\n", len(result.Rows))
+		default:
+			fmt.Printf("Imported %d fixtures\n", result.Created)
+		}
+		return nil
+	})
+
+	Desc("resync", "Repair the team names copied into fixtures, and report what changed")
+	Add("resync", func(c *Context) error {
+		report, err := app.FixturesDB.Resync(c)
+		if err != nil {
+			return err
+		}
+		for _, fix := range report.Fixed {
+			fmt.Printf("fixture %s: %s %q -> %q\n", fix.Fixture.Hex(), fix.Field, fix.Was, fix.Now)
+		}
+		for _, orphan := range report.Orphaned {
+			fmt.Printf("fixture %s: a team no longer exists\n", orphan.Hex())
+		}
+		fmt.Printf("Repaired %d team names, %d fixtures are between missing teams\n",
+			len(report.Fixed), len(report.Orphaned))
+		return nil
+	})
+
+	Desc("export", "Export the league, users and admins to an archive. "+
+		"Usage: grift db:export [file] [passwords]")
+	Add("export", func(c *Context) error {
+		opts := backup.Options{Passwords: len(c.Args) > 1 && c.Args[1] == "passwords"}
+		name := backup.FileName(app.Clock.Now())
+		if len(c.Args) > 0 {
+			name = c.Args[0]
+		}
+		f, err := os.Create(name)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		manifest, err := app.Backups.Export(c, f, opts)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Exported %s to %s\n", archiveCounts(manifest.Counts), name)
+		return nil
+	})
+
+	Desc("import", "Restore an archive into a database without a league or users. "+
+		"Usage: grift db:import <file>")
+	Add("import", func(c *Context) error {
+		if len(c.Args) < 1 {
+			return errors.New("an archive is required")
+		}
+		f, err := os.Open(c.Args[0])
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		restored, err := app.Backups.Restore(c, f)
+		if err != nil {
+			return err
+		}
+		restored.Counts[database.AdminsCollection] -= restored.SkippedAdmins
+		fmt.Printf("Restored %s (%d admins already existed)\n",
+			archiveCounts(restored.Counts), restored.SkippedAdmins)
+		if !restored.Passwords {
+			fmt.Println("The archive has no password hashes, restored accounts can't log in")
+		}
 		return nil
 	})
 
@@ -103,11 +260,25 @@ func chainGrifts(c *Context, grifts ...string) error {
 	return nil
 }
 
-func makeFixtureRequest(teamA teams.Team, teamB teams.Team) fixtures.CreateFixtureRequest {
-	return fixtures.CreateFixtureRequest{
-		HomeTeam:  teamA.ID,
-		AwayTeam:  teamB.ID,
-		MatchDate: time.Now().Add(time.Hour * time.Duration(rand.Intn(96))),
+// makeSeasonRequest sets up a season for up to 20 of the teams, starting
+// on the next Friday and lasting ten months.
+func makeSeasonRequest(ts []teams.Team, now time.Time) seasons.SeasonRequest {
+	if len(ts) > 20 {
+		ts = ts[:20]
+	}
+	teamIDs := make([]string, 0, len(ts))
+	for _, t := range ts {
+		teamIDs = append(teamIDs, t.ID)
+	}
+	daysToFriday := (int(time.Friday) - int(now.Weekday()) + 7) % 7
+	start := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC).
+		AddDate(0, 0, daysToFriday)
+	end := start.AddDate(0, 10, 0)
+	return seasons.SeasonRequest{
+		Name:      fmt.Sprintf("%d/%02d", start.Year(), end.Year()%100),
+		StartDate: start,
+		EndDate:   end,
+		Teams:     teamIDs,
 	}
 }
 
@@ -126,5 +297,26 @@ func toDomainTeam(t Team) teams.Team {
 		Name:        t.Club.Name,
 		NameAbbr:    t.Club.Abbr,
 		ShortName:   t.Club.ShortName,
+		Rating:      seedRating(t),
+	}
+}
+
+// toStadiumRequest keeps the capacity and location of a
+// ground, which teams only have the name and city of.
+func toStadiumRequest(g Ground) stadiums.StadiumRequest {
+	dto := stadiums.StadiumRequest{Name: g.Name, City: g.City}
+	if g.Capacity != nil {
+		dto.Capacity = int(*g.Capacity)
+	}
+	if g.Location != nil {
+		dto.Latitude = &g.Location.Latitude
+		dto.Longitude = &g.Location.Longitude
 	}
+	return dto
+}
+
+// seedRating gives each seeded team a rating between 55 and 90
+// that is the same every time the database is seeded.
+func seedRating(t Team) int {
+	return 55 + rand.New(rand.NewSource(int64(t.ID))).Intn(36)
 }
@@ -0,0 +1,239 @@
+// Package live fans the changes made to fixtures out to the clients
+// following them, and keeps recent changes so that clients that lose
+// their connection can catch up.
+package live
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gomoney-mock-epl/fixtures"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	// AllFixtures is the topic every change is published to.
+	AllFixtures = "fixtures"
+	// TodaysFixtures is the topic for the changes to fixtures played on
+	// the day (in UTC) the change is made.
+	TodaysFixtures = "fixtures:today"
+
+	fixtureTopicPrefix = "fixture:"
+	teamTopicPrefix    = "team:"
+)
+
+// FixtureTopic is the topic for the changes to a single fixture.
+func FixtureTopic(fixtureID string) string {
+	return fixtureTopicPrefix + fixtureID
+}
+
+// TeamTopic is the topic for the changes to the fixtures of a team.
+func TeamTopic(teamID string) string {
+	return teamTopicPrefix + teamID
+}
+
+// ValidTopic reports whether clients can subscribe to a topic.
+func ValidTopic(topic string) bool {
+	switch {
+	case topic == AllFixtures, topic == TodaysFixtures:
+		return true
+	case strings.HasPrefix(topic, fixtureTopicPrefix):
+		_, err := primitive.ObjectIDFromHex(strings.TrimPrefix(topic, fixtureTopicPrefix))
+		return err == nil
+	case strings.HasPrefix(topic, teamTopicPrefix):
+		return len(topic) > len(teamTopicPrefix)
+	}
+	return false
+}
+
+// Message is a change published to the hub. IDs increase with every
+// message, so clients can tell the hub the last one they received.
+type Message struct {
+	ID     uint64
+	Topics []string
+	Change fixtures.Change
+}
+
+func (m Message) in(topics map[string]bool) bool {
+	for _, topic := range m.Topics {
+		if topics[topic] {
+			return true
+		}
+	}
+	return false
+}
+
+func sameDay(a, b time.Time) bool {
+	ay, am, ad := a.UTC().Date()
+	by, bm, bd := b.UTC().Date()
+	return ay == by && am == bm && ad == bd
+}
+
+// topicsOf lists the topics a change is published to.
+func topicsOf(change fixtures.Change) []string {
+	fixture := change.Fixture
+	topics := []string{AllFixtures, FixtureTopic(fixture.ID.Hex())}
+	if fixture.HomeTeam != nil {
+		topics = append(topics, TeamTopic(fixture.HomeTeam.ID))
+	}
+	if fixture.AwayTeam != nil {
+		topics = append(topics, TeamTopic(fixture.AwayTeam.ID))
+	}
+	if sameDay(fixture.MatchDate, change.At) {
+		topics = append(topics, TodaysFixtures)
+	}
+	return topics
+}
+
+// subscriberBuffer is the number of messages a subscriber can fall
+// behind by before it is dropped.
+const subscriberBuffer = 64
+
+// Hub publishes fixture changes to subscribers. It implements
+// fixtures.Publisher. The zero value is not usable; use NewHub.
+type Hub struct {
+	mu          sync.Mutex
+	lastID      uint64
+	history     []Message
+	next        int
+	subscribers map[*Subscription]bool
+}
+
+// NewHub creates a hub that keeps the last historySize messages for
+// replaying to clients that reconnect.
+func NewHub(historySize int) *Hub {
+	return &Hub{
+		history:     make([]Message, 0, historySize),
+		subscribers: map[*Subscription]bool{},
+	}
+}
+
+// Publish sends a change to the subscribers of its topics. Subscribers
+// that are too slow to keep up are dropped rather than blocking the
+// write that made the change; they can reconnect and catch up.
+func (h *Hub) Publish(change fixtures.Change) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.lastID++
+	message := Message{ID: h.lastID, Topics: topicsOf(change), Change: change}
+	if cap(h.history) > 0 {
+		if len(h.history) < cap(h.history) {
+			h.history = append(h.history, message)
+		} else {
+			h.history[h.next] = message
+			h.next = (h.next + 1) % cap(h.history)
+		}
+	}
+	for subscription := range h.subscribers {
+		if !message.in(subscription.topics) {
+			continue
+		}
+		select {
+		case subscription.messages <- message:
+		default:
+			h.drop(subscription)
+		}
+	}
+}
+
+// since lists the messages kept after the one with the given ID.
+func (h *Hub) since(lastID uint64) []Message {
+	messages := []Message{}
+	for i := range h.history {
+		message := h.history[(h.next+i)%len(h.history)]
+		if message.ID > lastID {
+			messages = append(messages, message)
+		}
+	}
+	return messages
+}
+
+// Subscribe starts following the given topics. The messages kept after
+// lastID in those topics are returned, so that no message is missed
+// between them and the ones delivered to the subscription. Pass a
+// lastID of 0 to only receive new messages.
+func (h *Hub) Subscribe(lastID uint64, topics ...string) (*Subscription, []Message) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	subscription := &Subscription{
+		hub:      h,
+		topics:   map[string]bool{},
+		messages: make(chan Message, subscriberBuffer),
+	}
+	for _, topic := range topics {
+		subscription.topics[topic] = true
+	}
+	h.subscribers[subscription] = true
+	missed := []Message{}
+	if lastID > 0 {
+		for _, message := range h.since(lastID) {
+			if message.in(subscription.topics) {
+				missed = append(missed, message)
+			}
+		}
+	}
+	return subscription, missed
+}
+
+// drop removes a subscription and closes its channel. The hub's lock
+// must be held.
+func (h *Hub) drop(subscription *Subscription) {
+	if h.subscribers[subscription] {
+		delete(h.subscribers, subscription)
+		close(subscription.messages)
+	}
+}
+
+// Subscription receives the messages published to its topics.
+type Subscription struct {
+	hub      *Hub
+	topics   map[string]bool
+	messages chan Message
+}
+
+// Messages delivers the messages published to the subscription's
+// topics. It is closed when the subscription is closed, or dropped
+// for falling behind.
+func (s *Subscription) Messages() <-chan Message {
+	return s.messages
+}
+
+// Follow adds topics to the subscription.
+func (s *Subscription) Follow(topics ...string) {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+	for _, topic := range topics {
+		s.topics[topic] = true
+	}
+}
+
+// Unfollow removes topics from the subscription.
+func (s *Subscription) Unfollow(topics ...string) {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+	for _, topic := range topics {
+		delete(s.topics, topic)
+	}
+}
+
+// Topics lists the topics the subscription follows, in order.
+func (s *Subscription) Topics() []string {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+	topics := make([]string, 0, len(s.topics))
+	for topic := range s.topics {
+		topics = append(topics, topic)
+	}
+	sort.Strings(topics)
+	return topics
+}
+
+// Close stops the subscription.
+func (s *Subscription) Close() {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+	s.hub.drop(s)
+}
@@ -0,0 +1,113 @@
+package live
+
+import (
+	"testing"
+	"time"
+
+	"gomoney-mock-epl/fixtures"
+	"gomoney-mock-epl/teams"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func change(fixtureID primitive.ObjectID, changeType fixtures.ChangeType) fixtures.Change {
+	return fixtures.Change{Type: changeType, Fixture: fixtures.Fixture{ID: fixtureID}}
+}
+
+func TestHub(t *testing.T) {
+	first, second := primitive.NewObjectID(), primitive.NewObjectID()
+
+	t.Run("Delivers changes to the subscribers of their topics", func(t *testing.T) {
+		hub := NewHub(10)
+		all, _ := hub.Subscribe(0, AllFixtures)
+		one, _ := hub.Subscribe(0, FixtureTopic(first.Hex()))
+		hub.Publish(change(second, fixtures.StatusChanged))
+		hub.Publish(change(first, fixtures.EventAdded))
+
+		assert.Equal(t, uint64(1), (<-all.Messages()).ID)
+		assert.Equal(t, uint64(2), (<-all.Messages()).ID)
+		message := <-one.Messages()
+		assert.Equal(t, uint64(2), message.ID)
+		assert.Equal(t, fixtures.EventAdded, message.Change.Type)
+		assert.Len(t, one.Messages(), 0)
+	})
+
+	t.Run("Replays the messages missed since the last one received", func(t *testing.T) {
+		hub := NewHub(3)
+		for i := 0; i < 5; i++ {
+			hub.Publish(change(first, fixtures.EventAdded))
+		}
+		hub.Publish(change(second, fixtures.EventAdded))
+
+		_, missed := hub.Subscribe(2, FixtureTopic(first.Hex()))
+		assert.Len(t, missed, 2, "only the messages kept are replayed")
+		assert.Equal(t, uint64(4), missed[0].ID)
+		assert.Equal(t, uint64(5), missed[1].ID)
+
+		_, missed = hub.Subscribe(0, AllFixtures)
+		assert.Empty(t, missed)
+	})
+
+	t.Run("Publishes changes to the teams playing and to today's fixtures", func(t *testing.T) {
+		now := time.Now()
+		topics := topicsOf(fixtures.Change{
+			Fixture: fixtures.Fixture{
+				ID:        first,
+				HomeTeam:  &teams.Team{ID: "ars"},
+				AwayTeam:  &teams.Team{ID: "che"},
+				MatchDate: now.Add(-time.Minute),
+			},
+			At: now,
+		})
+		assert.ElementsMatch(t, []string{
+			AllFixtures, FixtureTopic(first.Hex()), TeamTopic("ars"), TeamTopic("che"), TodaysFixtures,
+		}, topics)
+
+		topics = topicsOf(fixtures.Change{
+			Fixture: fixtures.Fixture{ID: first, MatchDate: now.Add(-48 * time.Hour)},
+			At:      now,
+		})
+		assert.NotContains(t, topics, TodaysFixtures)
+	})
+
+	t.Run("Subscribers can change the topics they follow", func(t *testing.T) {
+		hub := NewHub(0)
+		subscription, _ := hub.Subscribe(0)
+		subscription.Follow(FixtureTopic(first.Hex()), FixtureTopic(second.Hex()))
+		subscription.Unfollow(FixtureTopic(first.Hex()))
+		assert.Equal(t, []string{FixtureTopic(second.Hex())}, subscription.Topics())
+
+		hub.Publish(change(first, fixtures.EventAdded))
+		hub.Publish(change(second, fixtures.EventAdded))
+		assert.Equal(t, uint64(2), (<-subscription.Messages()).ID)
+	})
+
+	t.Run("Only well-formed topics can be followed", func(t *testing.T) {
+		for topic, valid := range map[string]bool{
+			AllFixtures:               true,
+			TodaysFixtures:            true,
+			FixtureTopic(first.Hex()): true,
+			TeamTopic("ars"):          true,
+			FixtureTopic("ars"):       false,
+			TeamTopic(""):             false,
+			"teams":                   false,
+		} {
+			assert.Equal(t, valid, ValidTopic(topic), topic)
+		}
+	})
+
+	t.Run("Drops subscribers that fall behind", func(t *testing.T) {
+		hub := NewHub(0)
+		slow, _ := hub.Subscribe(0, AllFixtures)
+		for i := 0; i <= subscriberBuffer; i++ {
+			hub.Publish(change(first, fixtures.EventAdded))
+		}
+		received := 0
+		for range slow.Messages() {
+			received++
+		}
+		assert.Equal(t, subscriberBuffer, received)
+		slow.Close()
+	})
+}
@@ -8,6 +8,7 @@ import (
 
 	"gomoney-mock-epl/config"
 	"gomoney-mock-epl/database"
+	"gomoney-mock-epl/simulator"
 	"gomoney-mock-epl/web"
 
 	"github.com/tylerb/graceful"
@@ -28,6 +29,19 @@ func main() {
 	}
 	defer app.DBClient.Disconnect(context.Background())
 
+	ctx, stop := context.WithCancel(context.Background())
+	defer stop()
+	go app.Transfers.Run(ctx)
+	if config.SimulatorEnabled {
+		sim := &simulator.Simulator{
+			Fixtures: app.FixturesDB,
+			Seed:     config.SimulatorSeed,
+			Speed:    config.SimulatorSpeed,
+			Clock:    app.Clock,
+		}
+		go sim.Run(ctx)
+	}
+
 	if err := graceful.ListenAndServe(app.Echo.Server, 10*time.Second); err != nil {
 		log.Fatalf("error: %v\n", err)
 	}
@@ -0,0 +1,175 @@
+package officials
+
+import (
+	"context"
+	"errors"
+	"gomoney-mock-epl/clock"
+	customErrors "gomoney-mock-epl/errors"
+	"time"
+
+	v "github.com/go-ozzo/ozzo-validation"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Role is the part an official plays in the matches they work.
+type Role string
+
+const (
+	Referee   = Role("referee")
+	Assistant = Role("assistant")
+	VAR       = Role("var")
+)
+
+// Official is a match official: a referee, an assistant referee
+// running the line, or a video assistant referee.
+type Official struct {
+	ID          string    `json:"id" bson:"_id"`
+	Name        string    `json:"name" bson:"name"`
+	Role        Role      `json:"role" bson:"role"`
+	Nationality string    `json:"nationality" bson:"nationality"`
+	CreatedAt   time.Time `json:"created_at" bson:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at" bson:"updated_at"`
+}
+
+// OfficialRequest is the DTO we receive from the
+// clients when creating or updating officials.
+type OfficialRequest struct {
+	Name        string `json:"name"`
+	Role        Role   `json:"role"`
+	Nationality string `json:"nationality"`
+}
+
+func (r *OfficialRequest) FromOfficial(official Official) *OfficialRequest {
+	r.Name = official.Name
+	r.Role = official.Role
+	r.Nationality = official.Nationality
+	return r
+}
+
+func (r OfficialRequest) Validate() (*customErrors.ValidationError, error) {
+	err := v.ValidateStruct(&r,
+		v.Field(&r.Name, v.Required.Error("Official name is required"), v.Length(1, 100)),
+		v.Field(&r.Role, v.Required.Error("Official role is required"),
+			v.In(Referee, Assistant, VAR).Error("Officials are referees, assistants or VARs")),
+		v.Field(&r.Nationality, v.Length(0, 60)),
+	)
+
+	return customErrors.ToValidationError(err,
+		"Parts of the official supplied are invalid.",
+		"officials/invalid-official")
+}
+
+// OfficialsDB stores match officials. Timestamps are taken from the
+// clock, or the real time if it's not set.
+type OfficialsDB struct {
+	*mongo.Collection
+	Clock clock.Clock
+}
+
+// Create adds a new official to the database.
+func (db OfficialsDB) Create(ctx context.Context, dto OfficialRequest) (*Official, error) {
+	validationErr, err := dto.Validate()
+	if err != nil {
+		return nil, err
+	}
+	if validationErr != nil {
+		return nil, *validationErr
+	}
+	now := clock.Now(db.Clock)
+	official := Official{
+		ID:          primitive.NewObjectID().Hex(),
+		Name:        dto.Name,
+		Role:        dto.Role,
+		Nationality: dto.Nationality,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	_, err = db.InsertOne(ctx, &official, options.InsertOne().SetBypassDocumentValidation(false))
+	return &official, err
+}
+
+// Update changes an official's information in the database. Fixtures
+// keep the role they were assigned with. It returns (nil, nil) if no
+// official matched.
+func (db OfficialsDB) Update(ctx context.Context, id string, dto OfficialRequest) (*Official, error) {
+	validationErr, err := dto.Validate()
+	if err != nil {
+		return nil, err
+	}
+	if validationErr != nil {
+		return nil, *validationErr
+	}
+	official, err := db.ByID(ctx, id)
+	if err != nil || official == nil {
+		return nil, err
+	}
+	official.Name = dto.Name
+	official.Role = dto.Role
+	official.Nationality = dto.Nationality
+	official.UpdatedAt = clock.Now(db.Clock)
+	result, err := db.ReplaceOne(ctx, bson.D{{Key: "_id", Value: id}}, official)
+	if err != nil {
+		return nil, err
+	}
+	if result.MatchedCount == 0 {
+		return nil, nil
+	}
+	return official, nil
+}
+
+// List fetches the officials in the database by name, only
+// those in the role if it's set.
+func (db OfficialsDB) List(ctx context.Context, role Role) ([]Official, error) {
+	filter := bson.D{}
+	if role != "" {
+		filter = bson.D{{Key: "role", Value: role}}
+	}
+	cursor, err := db.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "name", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	officials := []Official{}
+	if err := cursor.All(ctx, &officials); err != nil {
+		return nil, err
+	}
+	return officials, nil
+}
+
+// ByIDs fetches the officials with the IDs. Unknown IDs are left out.
+func (db OfficialsDB) ByIDs(ctx context.Context, ids []string) ([]Official, error) {
+	cursor, err := db.Find(ctx, bson.D{{Key: "_id", Value: bson.D{{Key: "$in", Value: ids}}}})
+	if err != nil {
+		return nil, err
+	}
+	officials := []Official{}
+	if err := cursor.All(ctx, &officials); err != nil {
+		return nil, err
+	}
+	return officials, nil
+}
+
+// ByID fetches an official by ID. It returns (nil, nil) if no official matched.
+func (db OfficialsDB) ByID(ctx context.Context, id string) (*Official, error) {
+	result := db.FindOne(ctx, bson.D{{Key: "_id", Value: id}})
+	err := result.Err()
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	official := Official{}
+	if err := result.Decode(&official); err != nil {
+		return nil, err
+	}
+	return &official, nil
+}
+
+// Delete removes an official from the database.
+func (db OfficialsDB) Delete(ctx context.Context, id string) error {
+	_, err := db.DeleteOne(ctx, bson.D{{Key: "_id", Value: id}})
+	return err
+}
@@ -0,0 +1,25 @@
+package officials
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOfficialRequest_Validate(t *testing.T) {
+	t.Run("Requires the name and role", func(t *testing.T) {
+		validationErr, err := OfficialRequest{}.Validate()
+		assert.Nil(t, err)
+		assert.Equal(t, "officials/invalid-official", validationErr.Code)
+		assert.Len(t, validationErr.Details, 2)
+	})
+
+	t.Run("Accepts referees, assistants and VARs", func(t *testing.T) {
+		for _, role := range []Role{Referee, Assistant, VAR} {
+			validationErr, _ := OfficialRequest{Name: "Michael Oliver", Role: role}.Validate()
+			assert.Nil(t, validationErr, role)
+		}
+		validationErr, _ := OfficialRequest{Name: "Michael Oliver", Role: "linesman"}.Validate()
+		assert.Equal(t, "role", validationErr.Details[0].Field)
+	})
+}
@@ -0,0 +1,313 @@
+package players
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gomoney-mock-epl/clock"
+	customErrors "gomoney-mock-epl/errors"
+	"gomoney-mock-epl/seasons"
+	"gomoney-mock-epl/teams"
+	"time"
+
+	v "github.com/go-ozzo/ozzo-validation"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Position is where a player plays on the pitch.
+type Position string
+
+const (
+	Goalkeeper = Position("goalkeeper")
+	Defender   = Position("defender")
+	Midfielder = Position("midfielder")
+	Forward    = Position("forward")
+)
+
+// positionOrder is the order positions are listed in squads.
+var positionOrder = []interface{}{Goalkeeper, Defender, Midfielder, Forward}
+
+// Player is a footballer. Players without a team are free agents. The
+// shirt number is registered with the team for the season, if given;
+// no two players in a team's squad for a season share a shirt number.
+type Player struct {
+	ID          string    `json:"id" bson:"_id"`
+	Name        string    `json:"name" bson:"name"`
+	Position    Position  `json:"position" bson:"position"`
+	ShirtNumber int       `json:"shirt_number,omitempty" bson:"shirt_number,omitempty"`
+	Nationality string    `json:"nationality" bson:"nationality"`
+	DateOfBirth time.Time `json:"date_of_birth" bson:"date_of_birth"`
+	Team        string    `json:"team,omitempty" bson:"team,omitempty"`
+	Season      string    `json:"season,omitempty" bson:"season,omitempty"`
+	CreatedAt   time.Time `json:"created_at" bson:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at" bson:"updated_at"`
+}
+
+// PlayerRequest is the DTO we receive from the
+// clients when creating or updating players.
+type PlayerRequest struct {
+	Name        string    `json:"name"`
+	Position    Position  `json:"position"`
+	ShirtNumber int       `json:"shirt_number"`
+	Nationality string    `json:"nationality"`
+	DateOfBirth time.Time `json:"date_of_birth"`
+	Team        string    `json:"team"`
+	Season      string    `json:"season"`
+}
+
+func (r *PlayerRequest) FromPlayer(player Player) *PlayerRequest {
+	r.Name = player.Name
+	r.Position = player.Position
+	r.ShirtNumber = player.ShirtNumber
+	r.Nationality = player.Nationality
+	r.DateOfBirth = player.DateOfBirth
+	r.Team = player.Team
+	r.Season = player.Season
+	return r
+}
+
+// needsTeam is the rule for the parts of a request that
+// only make sense for players in a team.
+func (r PlayerRequest) needsTeam(message string) v.Rule {
+	return v.By(func(value interface{}) error {
+		if r.Team == "" && !v.IsEmpty(value) {
+			return errors.New(message)
+		}
+		return nil
+	})
+}
+
+func (r PlayerRequest) Validate() (*customErrors.ValidationError, error) {
+	err := v.ValidateStruct(&r,
+		v.Field(&r.Name, v.Required.Error("Player name is required"), v.Length(1, 100)),
+		v.Field(&r.Position, v.Required.Error("Player position is required"),
+			v.In(positionOrder...).Error("Players are goalkeepers, defenders, midfielders or forwards")),
+		v.Field(&r.ShirtNumber, v.Min(1), v.Max(99),
+			r.needsTeam("Only players in a team have shirt numbers")),
+		v.Field(&r.Nationality, v.Length(0, 60)),
+		v.Field(&r.DateOfBirth, v.Required.Error("Player date of birth is required")),
+		v.Field(&r.Season, r.needsTeam("Only players in a team are registered for a season")),
+	)
+
+	return customErrors.ToValidationError(err,
+		"Parts of the player supplied are invalid.",
+		"players/invalid-player")
+}
+
+// PlayersDB provides methods for storing and accessing players in the
+// database. It uses the teams and seasons databases for lookups.
+// Timestamps are taken from the clock, or the real time if it's not set.
+type PlayersDB struct {
+	*mongo.Collection
+	teams.TeamsDB
+	seasons.SeasonsDB
+	Clock clock.Clock
+}
+
+// validate checks the request, and that the team and season it
+// references exist, with the team taking part in the season.
+func (db PlayersDB) validate(ctx context.Context, dto PlayerRequest) error {
+	validationErr, err := dto.Validate()
+	if err != nil {
+		return err
+	}
+	if validationErr != nil {
+		return *validationErr
+	}
+	invalid := func(field, message string) error {
+		return customErrors.ValidationError{
+			Code:    "players/invalid-player",
+			Message: "Parts of the player supplied are invalid.",
+			Details: []customErrors.ValidationErrorDetails{{Field: field, Message: message}},
+		}
+	}
+	if dto.DateOfBirth.After(clock.Now(db.Clock)) {
+		return invalid("date_of_birth", "Players cannot be born in the future")
+	}
+	if dto.Team != "" {
+		team, err := db.TeamsDB.ByID(ctx, dto.Team)
+		if err != nil {
+			return err
+		}
+		if team == nil {
+			return invalid("team", "Unknown team "+dto.Team)
+		}
+	}
+	if dto.Season != "" {
+		season, err := db.SeasonsDB.ByID(ctx, dto.Season)
+		if err != nil {
+			return err
+		}
+		if season == nil {
+			return invalid("season", "Unknown season "+dto.Season)
+		}
+		if !season.Includes(dto.Team) {
+			return invalid("season", fmt.Sprintf("The team does not play in %s", season.Name))
+		}
+	}
+	return nil
+}
+
+// Create adds a new player to the database. Shirt numbers that are
+// already taken are reported as duplicate key errors.
+func (db PlayersDB) Create(ctx context.Context, dto PlayerRequest) (*Player, error) {
+	if err := db.validate(ctx, dto); err != nil {
+		return nil, err
+	}
+	now := clock.Now(db.Clock)
+	player := Player{
+		ID:          primitive.NewObjectID().Hex(),
+		Name:        dto.Name,
+		Position:    dto.Position,
+		ShirtNumber: dto.ShirtNumber,
+		Nationality: dto.Nationality,
+		DateOfBirth: dto.DateOfBirth,
+		Team:        dto.Team,
+		Season:      dto.Season,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	_, err := db.InsertOne(ctx, &player, options.InsertOne().SetBypassDocumentValidation(false))
+	return &player, err
+}
+
+// Update changes a player's information in the database.
+// It returns (nil, nil) if no player matched.
+func (db PlayersDB) Update(ctx context.Context, id string, dto PlayerRequest) (*Player, error) {
+	if err := db.validate(ctx, dto); err != nil {
+		return nil, err
+	}
+	player, err := db.ByID(ctx, id)
+	if err != nil || player == nil {
+		return nil, err
+	}
+	player.Name = dto.Name
+	player.Position = dto.Position
+	player.ShirtNumber = dto.ShirtNumber
+	player.Nationality = dto.Nationality
+	player.DateOfBirth = dto.DateOfBirth
+	player.Team = dto.Team
+	player.Season = dto.Season
+	player.UpdatedAt = clock.Now(db.Clock)
+	result, err := db.ReplaceOne(ctx, bson.D{{Key: "_id", Value: id}}, player)
+	if err != nil {
+		return nil, err
+	}
+	if result.MatchedCount == 0 {
+		return nil, nil
+	}
+	return player, nil
+}
+
+// Move puts a player in a team's squad, or makes them a free agent if
+// teamID is empty. Their shirt number and season registration are for
+// their old team, so they are cleared.
+func (db PlayersDB) Move(ctx context.Context, id, teamID string) error {
+	update := bson.D{
+		{Key: "$unset", Value: bson.D{
+			{Key: "shirt_number", Value: ""},
+			{Key: "season", Value: ""},
+		}},
+		{Key: "$set", Value: bson.D{
+			{Key: "team", Value: teamID},
+			{Key: "updated_at", Value: clock.Now(db.Clock)},
+		}},
+	}
+	if teamID == "" {
+		update = bson.D{
+			{Key: "$unset", Value: bson.D{
+				{Key: "shirt_number", Value: ""},
+				{Key: "season", Value: ""},
+				{Key: "team", Value: ""},
+			}},
+			{Key: "$set", Value: bson.D{{Key: "updated_at", Value: clock.Now(db.Clock)}}},
+		}
+	}
+	_, err := db.UpdateOne(ctx, bson.D{{Key: "_id", Value: id}}, update)
+	return err
+}
+
+// List fetches all the players in the database, by name.
+func (db PlayersDB) List(ctx context.Context) ([]Player, error) {
+	return db.find(ctx, bson.D{}, bson.D{{Key: "name", Value: 1}})
+}
+
+// Squad lists a team's players by shirt number. If seasonID is not
+// empty, only the players registered for that season are listed.
+func (db PlayersDB) Squad(ctx context.Context, teamID, seasonID string) ([]Player, error) {
+	filter := bson.D{{Key: "team", Value: teamID}}
+	if seasonID != "" {
+		filter = append(filter, bson.E{Key: "season", Value: seasonID})
+	}
+	return db.find(ctx, filter, bson.D{
+		{Key: "shirt_number", Value: 1},
+		{Key: "name", Value: 1},
+	})
+}
+
+func (db PlayersDB) find(ctx context.Context, filter, sort bson.D) ([]Player, error) {
+	cursor, err := db.Find(ctx, filter, options.Find().SetSort(sort))
+	if err != nil {
+		return nil, err
+	}
+	players := []Player{}
+	if err := cursor.All(ctx, &players); err != nil {
+		return nil, err
+	}
+	return players, nil
+}
+
+// ByID fetches a player by ID. It returns (nil, nil) if no player matched.
+func (db PlayersDB) ByID(ctx context.Context, id string) (*Player, error) {
+	result := db.FindOne(ctx, bson.D{{Key: "_id", Value: id}})
+	err := result.Err()
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	player := Player{}
+	if err := result.Decode(&player); err != nil {
+		return nil, err
+	}
+	return &player, nil
+}
+
+// Delete removes a player from the database.
+func (db PlayersDB) Delete(ctx context.Context, id string) error {
+	_, err := db.DeleteOne(ctx, bson.D{{Key: "_id", Value: id}})
+	return err
+}
+
+// Search finds the players matching a text query, best matches first.
+func (db PlayersDB) Search(ctx context.Context, query string) ([]Player, error) {
+	q := bson.D{
+		{Key: "$text", Value: bson.D{
+			{Key: "$search", Value: query},
+		}},
+	}
+	score := bson.D{
+		{Key: "score", Value: bson.D{{Key: "$meta", Value: "textScore"}}},
+	}
+	cursor, err := db.Find(ctx, q, options.Find().SetProjection(score).SetSort(score))
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return []Player{}, nil
+		}
+		return nil, err
+	}
+	players := []Player{}
+	if err := cursor.All(ctx, &players); err != nil {
+		return nil, err
+	}
+	return players, nil
+}
+
+// CountByTeam counts the players in a team's squad.
+func (db PlayersDB) CountByTeam(ctx context.Context, teamID string) (int64, error) {
+	return db.Collection.CountDocuments(ctx, bson.D{{Key: "team", Value: teamID}})
+}
@@ -0,0 +1,77 @@
+package players
+
+import (
+	"testing"
+	"time"
+
+	"gomoney-mock-epl/errors"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var salah = PlayerRequest{
+	Name:        "Mohamed Salah",
+	Position:    Forward,
+	ShirtNumber: 11,
+	Nationality: "Egypt",
+	DateOfBirth: time.Date(1992, time.June, 15, 0, 0, 0, 0, time.UTC),
+	Team:        "liv",
+}
+
+func TestPlayerRequest_Validate(t *testing.T) {
+	t.Run("Requires a name, position and date of birth", func(t *testing.T) {
+		validationError, internalError := PlayerRequest{}.Validate()
+		assert.Nil(t, internalError)
+		assert.Equal(t, "players/invalid-player", validationError.Code)
+		assert.Contains(t, validationError.Details, errors.ValidationErrorDetails{
+			Field:   "name",
+			Message: "Player name is required",
+		})
+		assert.Contains(t, validationError.Details, errors.ValidationErrorDetails{
+			Field:   "position",
+			Message: "Player position is required",
+		})
+		assert.Contains(t, validationError.Details, errors.ValidationErrorDetails{
+			Field:   "date_of_birth",
+			Message: "Player date of birth is required",
+		})
+	})
+
+	t.Run("Accepts players in a team", func(t *testing.T) {
+		validationError, internalError := salah.Validate()
+		assert.Nil(t, internalError)
+		assert.Nil(t, validationError)
+	})
+
+	t.Run("Only accepts known positions", func(t *testing.T) {
+		player := salah
+		player.Position = "winger"
+		validationError, _ := player.Validate()
+		assert.Contains(t, validationError.Details, errors.ValidationErrorDetails{
+			Field:   "position",
+			Message: "Players are goalkeepers, defenders, midfielders or forwards",
+		})
+	})
+
+	t.Run("Only gives players in a team shirt numbers and seasons", func(t *testing.T) {
+		player := salah
+		player.Team = ""
+		player.Season = "2021/22"
+		validationError, _ := player.Validate()
+		assert.Contains(t, validationError.Details, errors.ValidationErrorDetails{
+			Field:   "shirt_number",
+			Message: "Only players in a team have shirt numbers",
+		})
+		assert.Contains(t, validationError.Details, errors.ValidationErrorDetails{
+			Field:   "season",
+			Message: "Only players in a team are registered for a season",
+		})
+	})
+
+	t.Run("Limits shirt numbers to 1 to 99", func(t *testing.T) {
+		player := salah
+		player.ShirtNumber = 100
+		validationError, _ := player.Validate()
+		assert.Equal(t, "shirt_number", validationError.Details[0].Field)
+	})
+}
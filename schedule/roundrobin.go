@@ -0,0 +1,67 @@
+package schedule
+
+// pairing is a match between two teams in a round.
+type pairing struct {
+	home, away string
+}
+
+// bye pads a league with an odd number of teams. The team paired
+// with it sits the round out.
+const bye = ""
+
+// doubleRoundRobin pairs every team with every other team twice, once at
+// home and once away, using the circle method. Teams alternate between
+// home and away games, and no team plays more than two home or two away
+// games in a row. The second half of the schedule mirrors the first with
+// home and away swapped. It starts from the second round of the first
+// half, so that a team that ends the first half with two away games does
+// not start the second half with a third.
+func doubleRoundRobin(teamIDs []string) [][]pairing {
+	teams := append([]string{}, teamIDs...)
+	if len(teams)%2 == 1 {
+		teams = append(teams, bye)
+	}
+	n := len(teams)
+	circle := n - 1
+	fixed := teams[circle]
+	firstHalf := make([][]pairing, 0, circle)
+	for round := 0; round < circle; round++ {
+		pairings := make([]pairing, 0, n/2)
+		// The fixed team alternates between home and away every round.
+		if round%2 == 0 {
+			pairings = append(pairings, pairing{home: teams[round], away: fixed})
+		} else {
+			pairings = append(pairings, pairing{home: fixed, away: teams[round]})
+		}
+		for k := 1; k < n/2; k++ {
+			a := teams[(round+k)%circle]
+			b := teams[(round-k+circle)%circle]
+			if k%2 == 0 {
+				a, b = b, a
+			}
+			pairings = append(pairings, pairing{home: a, away: b})
+		}
+		firstHalf = append(firstHalf, withoutByes(pairings))
+	}
+
+	rounds := firstHalf
+	secondHalf := append(append([][]pairing{}, firstHalf[1:]...), firstHalf[0])
+	for _, pairings := range secondHalf {
+		mirrored := make([]pairing, 0, len(pairings))
+		for _, p := range pairings {
+			mirrored = append(mirrored, pairing{home: p.away, away: p.home})
+		}
+		rounds = append(rounds, mirrored)
+	}
+	return rounds
+}
+
+func withoutByes(pairings []pairing) []pairing {
+	kept := pairings[:0]
+	for _, p := range pairings {
+		if p.home != bye && p.away != bye {
+			kept = append(kept, p)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,175 @@
+package schedule
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	customErrors "gomoney-mock-epl/errors"
+	"gomoney-mock-epl/fixtures"
+	"gomoney-mock-epl/seasons"
+	"math/rand"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Slot is a kick-off time in a matchweek, like Saturday at 15:00.
+// Times are in UTC.
+type Slot struct {
+	Day     string `json:"day"`
+	KickOff string `json:"kick_off"`
+}
+
+// DefaultSlots spreads the ten games of a Premier League
+// matchweek from Saturday lunchtime to Monday night.
+var DefaultSlots = []Slot{
+	{Day: "saturday", KickOff: "12:30"},
+	{Day: "saturday", KickOff: "15:00"},
+	{Day: "saturday", KickOff: "15:00"},
+	{Day: "saturday", KickOff: "15:00"},
+	{Day: "saturday", KickOff: "15:00"},
+	{Day: "saturday", KickOff: "17:30"},
+	{Day: "sunday", KickOff: "14:00"},
+	{Day: "sunday", KickOff: "14:00"},
+	{Day: "sunday", KickOff: "16:30"},
+	{Day: "monday", KickOff: "20:00"},
+}
+
+var weekdays = map[string]time.Weekday{
+	"sunday":    time.Sunday,
+	"monday":    time.Monday,
+	"tuesday":   time.Tuesday,
+	"wednesday": time.Wednesday,
+	"thursday":  time.Thursday,
+	"friday":    time.Friday,
+	"saturday":  time.Saturday,
+}
+
+// in returns the first time on or after weekStart that falls in the slot.
+func (s Slot) in(weekStart time.Time) (time.Time, error) {
+	weekday, ok := weekdays[strings.ToLower(s.Day)]
+	if !ok {
+		return time.Time{}, fmt.Errorf("unknown day %q", s.Day)
+	}
+	kickOff, err := time.Parse("15:04", s.KickOff)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("kick-off time %q is not in the form HH:MM", s.KickOff)
+	}
+	days := (int(weekday) - int(weekStart.Weekday()) + 7) % 7
+	date := weekStart.AddDate(0, 0, days)
+	return time.Date(date.Year(), date.Month(), date.Day(),
+		kickOff.Hour(), kickOff.Minute(), 0, 0, time.UTC), nil
+}
+
+// Options controls how a season's fixtures are spread over time.
+type Options struct {
+	// Slots are the kick-off times games in a matchweek are assigned to,
+	// in turn. DefaultSlots are used if there are none.
+	Slots []Slot `json:"slots"`
+	// IntervalDays is the number of days between the starts of two
+	// consecutive matchweeks. It defaults to seven.
+	IntervalDays int `json:"interval_days"`
+	// Seed shuffles the teams, so that different seeds give different
+	// schedules. The same seed always gives the same schedule.
+	Seed int64 `json:"seed"`
+}
+
+func invalidSchedule(field, message string) customErrors.ValidationError {
+	return customErrors.ValidationError{
+		Code:    "schedule/cannot-schedule-season",
+		Message: "The season could not be scheduled",
+		Details: []customErrors.ValidationErrorDetails{{Field: field, Message: message}},
+	}
+}
+
+// Generate builds a double round-robin schedule for a season. Matchweek
+// N starts (N-1) intervals after the season starts, and its games are
+// assigned to the slots in turn. It fails if the season is too short to
+// hold every matchweek.
+func Generate(season seasons.Season, opts Options) ([]fixtures.CreateFixtureRequest, error) {
+	slots := opts.Slots
+	if len(slots) == 0 {
+		slots = DefaultSlots
+	}
+	interval := opts.IntervalDays
+	if interval == 0 {
+		interval = 7
+	}
+	if interval < 0 {
+		return nil, invalidSchedule("interval_days", "The interval between matchweeks cannot be negative")
+	}
+
+	teamIDs := append([]string{}, season.Teams...)
+	random := rand.New(rand.NewSource(opts.Seed))
+	random.Shuffle(len(teamIDs), func(i, j int) {
+		teamIDs[i], teamIDs[j] = teamIDs[j], teamIDs[i]
+	})
+
+	start := season.StartDate.UTC()
+	seasonStart := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.UTC)
+	requests := []fixtures.CreateFixtureRequest{}
+	for i, round := range doubleRoundRobin(teamIDs) {
+		weekStart := seasonStart.AddDate(0, 0, i*interval)
+		for j, p := range round {
+			matchDate, err := slots[j%len(slots)].in(weekStart)
+			if err != nil {
+				return nil, invalidSchedule("slots", err.Error())
+			}
+			if matchDate.Before(season.StartDate) || matchDate.After(season.EndDate) {
+				return nil, invalidSchedule("interval_days", fmt.Sprintf(
+					"The %s season is too short for %d matchweeks", season.Name, season.Matchweeks()))
+			}
+			requests = append(requests, fixtures.CreateFixtureRequest{
+				HomeTeam:  p.home,
+				AwayTeam:  p.away,
+				MatchDate: matchDate,
+				Season:    season.ID,
+				Matchweek: i + 1,
+			})
+		}
+	}
+	return requests, nil
+}
+
+var ErrAlreadyScheduled = errors.New("season already has fixtures")
+
+// Scheduler creates the fixtures of seasons.
+type Scheduler struct {
+	fixtures.DB
+}
+
+// Schedule generates the fixtures of a season and saves them. Seasons
+// that already have fixtures can't be scheduled. If saving any fixture
+// fails, the fixtures saved before it are removed. It returns (nil, nil)
+// if the season does not exist.
+func (s Scheduler) Schedule(ctx context.Context, seasonID string, opts Options) ([]fixtures.Fixture, error) {
+	season, err := s.SeasonsDB.ByID(ctx, seasonID)
+	if err != nil || season == nil {
+		return nil, err
+	}
+	seasonFixtures := bson.D{{Key: "season", Value: season.ID}}
+	existing, err := s.DB.Collection.CountDocuments(ctx, seasonFixtures)
+	if err != nil {
+		return nil, err
+	}
+	if existing > 0 {
+		return nil, fmt.Errorf("%w: the %s season has %d fixtures", ErrAlreadyScheduled, season.Name, existing)
+	}
+	requests, err := Generate(*season, opts)
+	if err != nil {
+		return nil, err
+	}
+	created := make([]fixtures.Fixture, 0, len(requests))
+	for _, request := range requests {
+		fixture, err := s.DB.Create(ctx, request)
+		if err != nil {
+			if _, rollbackErr := s.DB.Collection.DeleteMany(ctx, seasonFixtures); rollbackErr != nil {
+				return nil, fmt.Errorf("%v (removing the fixtures created failed: %v)", err, rollbackErr)
+			}
+			return nil, err
+		}
+		created = append(created, *fixture)
+	}
+	return created, nil
+}
@@ -0,0 +1,133 @@
+package schedule
+
+import (
+	"fmt"
+	"gomoney-mock-epl/seasons"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func teamIDs(n int) []string {
+	ids := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		ids = append(ids, fmt.Sprintf("team-%d", i))
+	}
+	return ids
+}
+
+func TestDoubleRoundRobin(t *testing.T) {
+	for _, n := range []int{2, 3, 4, 7, 10, 19, 20} {
+		t.Run(fmt.Sprintf("%d teams", n), func(t *testing.T) {
+			rounds := doubleRoundRobin(teamIDs(n))
+			meetings := map[pairing]int{}
+			homeAway := map[string][]bool{}
+			for _, round := range rounds {
+				playing := map[string]bool{}
+				for _, p := range round {
+					assert.False(t, playing[p.home] || playing[p.away], "a team plays twice in a round")
+					playing[p.home], playing[p.away] = true, true
+					meetings[p]++
+					homeAway[p.home] = append(homeAway[p.home], true)
+					homeAway[p.away] = append(homeAway[p.away], false)
+				}
+			}
+
+			expectedRounds := 2 * (n - 1)
+			if n%2 == 1 {
+				expectedRounds = 2 * n
+			}
+			assert.Len(t, rounds, expectedRounds)
+			assert.Len(t, meetings, n*(n-1), "every team hosts every other team")
+			for p, count := range meetings {
+				assert.Equal(t, 1, count, "%s hosts %s more than once", p.home, p.away)
+			}
+			for team, games := range homeAway {
+				run := 1
+				for i := 1; i < len(games); i++ {
+					if games[i] == games[i-1] {
+						run++
+					} else {
+						run = 1
+					}
+					assert.LessOrEqual(t, run, 2, "%s plays three home or away games in a row", team)
+				}
+			}
+		})
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	start := time.Date(2026, time.August, 14, 0, 0, 0, 0, time.UTC) // A Friday
+	season := seasons.Season{
+		ID:        "2026-27",
+		Name:      "2026/27",
+		StartDate: start,
+		EndDate:   start.AddDate(0, 10, 0),
+		Teams:     teamIDs(20),
+	}
+
+	t.Run("Schedules 38 matchweeks of 10 games", func(t *testing.T) {
+		requests, err := Generate(season, Options{})
+		assert.NoError(t, err)
+		assert.Len(t, requests, 380)
+		assert.Equal(t, 1, requests[0].Matchweek)
+		assert.Equal(t, 38, requests[379].Matchweek)
+		for _, request := range requests {
+			assert.Equal(t, season.ID, request.Season)
+		}
+	})
+
+	t.Run("Assigns games to the slots in turn", func(t *testing.T) {
+		long := season
+		long.EndDate = start.AddDate(2, 0, 0)
+		requests, err := Generate(long, Options{
+			Slots: []Slot{
+				{Day: "saturday", KickOff: "15:00"},
+				{Day: "Sunday", KickOff: "16:30"},
+			},
+			IntervalDays: 14,
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, time.Date(2026, time.August, 15, 15, 0, 0, 0, time.UTC), requests[0].MatchDate)
+		assert.Equal(t, time.Date(2026, time.August, 16, 16, 30, 0, 0, time.UTC), requests[1].MatchDate)
+		assert.Equal(t, time.Date(2026, time.August, 15, 15, 0, 0, 0, time.UTC), requests[2].MatchDate)
+		assert.Equal(t, time.Date(2026, time.August, 29, 15, 0, 0, 0, time.UTC), requests[10].MatchDate)
+	})
+
+	t.Run("Fits the matchweeks of seasons with an odd number of teams", func(t *testing.T) {
+		odd := season
+		odd.Teams = teamIDs(19)
+		requests, err := Generate(odd, Options{})
+		assert.NoError(t, err)
+		assert.Len(t, requests, 19*18)
+		for _, request := range requests {
+			assert.GreaterOrEqual(t, request.Matchweek, 1)
+			assert.LessOrEqual(t, request.Matchweek, odd.Matchweeks())
+		}
+		assert.Equal(t, odd.Matchweeks(), requests[len(requests)-1].Matchweek)
+	})
+
+	t.Run("Gives the same schedule for the same seed", func(t *testing.T) {
+		first, _ := Generate(season, Options{Seed: 42})
+		second, _ := Generate(season, Options{Seed: 42})
+		other, _ := Generate(season, Options{Seed: 7})
+		assert.Equal(t, first, second)
+		assert.NotEqual(t, first, other)
+	})
+
+	t.Run("Fails if the season is too short", func(t *testing.T) {
+		short := season
+		short.EndDate = start.AddDate(0, 3, 0)
+		_, err := Generate(short, Options{})
+		assert.Error(t, err)
+	})
+
+	t.Run("Fails on unknown slots", func(t *testing.T) {
+		_, err := Generate(season, Options{Slots: []Slot{{Day: "someday", KickOff: "15:00"}}})
+		assert.Error(t, err)
+		_, err = Generate(season, Options{Slots: []Slot{{Day: "monday", KickOff: "3pm"}}})
+		assert.Error(t, err)
+	})
+}
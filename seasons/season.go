@@ -0,0 +1,267 @@
+package seasons
+
+import (
+	"context"
+	"errors"
+	"gomoney-mock-epl/clock"
+	customErrors "gomoney-mock-epl/errors"
+	"gomoney-mock-epl/teams"
+	"time"
+
+	v "github.com/go-ozzo/ozzo-validation"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Season is a run of the league, played by a fixed set of teams
+// between its start and end dates.
+type Season struct {
+	ID        string    `json:"id" bson:"_id"`
+	Name      string    `json:"name" bson:"name"`
+	StartDate time.Time `json:"start_date" bson:"start_date"`
+	EndDate   time.Time `json:"end_date" bson:"end_date"`
+	Teams     []string  `json:"teams" bson:"teams"`
+	CreatedAt time.Time `json:"created_at" bson:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" bson:"updated_at"`
+}
+
+// Includes reports whether a team takes part in the season.
+func (s Season) Includes(teamID string) bool {
+	for _, id := range s.Teams {
+		if id == teamID {
+			return true
+		}
+	}
+	return false
+}
+
+// Covers reports whether t falls within the season.
+func (s Season) Covers(t time.Time) bool {
+	return !t.Before(s.StartDate) && !t.After(s.EndDate)
+}
+
+// Matchweeks is the number of matchweeks in the season. Every
+// team plays every other team twice, once at home and once away.
+// With an odd number of teams, one team sits out each matchweek,
+// so there are two more.
+func (s Season) Matchweeks() int {
+	if len(s.Teams)%2 == 1 {
+		return 2 * len(s.Teams)
+	}
+	return 2 * (len(s.Teams) - 1)
+}
+
+// SeasonRequest is the DTO we receive from the
+// clients when creating or updating seasons.
+type SeasonRequest struct {
+	Name      string    `json:"name"`
+	StartDate time.Time `json:"start_date"`
+	EndDate   time.Time `json:"end_date"`
+	Teams     []string  `json:"teams"`
+}
+
+func (r *SeasonRequest) FromSeason(season Season) *SeasonRequest {
+	r.Name = season.Name
+	r.StartDate = season.StartDate
+	r.EndDate = season.EndDate
+	r.Teams = season.Teams
+	return r
+}
+
+func distinctTeams(value interface{}) error {
+	seen := map[string]bool{}
+	for _, id := range value.([]string) {
+		if seen[id] {
+			return errors.New("A team can only be added to a season once")
+		}
+		seen[id] = true
+	}
+	return nil
+}
+
+func (r SeasonRequest) Validate() (*customErrors.ValidationError, error) {
+	err := v.ValidateStruct(&r,
+		v.Field(&r.Name, v.Required.Error("Season name is required"), v.Length(1, 100)),
+		v.Field(&r.StartDate, v.Required.Error("Season start date is required")),
+		v.Field(&r.EndDate, v.Required.Error("Season end date is required"),
+			v.Min(r.StartDate).Error("Season end date must be after its start date")),
+		v.Field(&r.Teams, v.Required.Error("Season teams are required"),
+			v.Length(2, 0).Error("A season needs at least two teams"),
+			v.By(distinctTeams)),
+	)
+
+	return customErrors.ToValidationError(err,
+		"Parts of the season supplied are invalid.",
+		"seasons/invalid-season")
+}
+
+// ErrInUse is returned when deleting a season fixtures are placed in.
+var ErrInUse = errors.New("fixtures are placed in the season")
+
+// Fixtures checks changes to seasons against the fixtures placed in
+// them, so the fixtures still fit their season.
+type Fixtures interface {
+	// CheckSeason lists what keeps the season's fixtures from fitting
+	// in it as it would be.
+	CheckSeason(ctx context.Context, season Season) ([]customErrors.ValidationErrorDetails, error)
+	// SeasonInUse reports whether any fixture is placed in the season.
+	SeasonInUse(ctx context.Context, seasonID string) (bool, error)
+}
+
+// SeasonsDB provides methods for storing and accessing seasons
+// in the database. It uses the teams database for lookups. Updates
+// and deletes are checked against the fixtures, if they're set.
+type SeasonsDB struct {
+	*mongo.Collection
+	teams.TeamsDB
+	Fixtures Fixtures
+	Clock    clock.Clock
+}
+
+// validate checks the request, and that the teams referenced exist.
+func (db SeasonsDB) validate(ctx context.Context, dto SeasonRequest) error {
+	validationErr, err := dto.Validate()
+	if err != nil {
+		return err
+	}
+	if validationErr != nil {
+		return *validationErr
+	}
+	validationErrs := customErrors.ValidationError{
+		Code:    "seasons/invalid-season",
+		Message: "Parts of the season supplied are invalid.",
+		Details: []customErrors.ValidationErrorDetails{},
+	}
+	for _, teamID := range dto.Teams {
+		team, err := db.TeamsDB.ByID(ctx, teamID)
+		if err != nil {
+			return err
+		}
+		if team == nil {
+			validationErrs.Details = append(validationErrs.Details, customErrors.ValidationErrorDetails{
+				Field:   "teams",
+				Message: "Unknown team " + teamID,
+			})
+		}
+	}
+	if len(validationErrs.Details) > 0 {
+		return validationErrs
+	}
+	return nil
+}
+
+// Create adds a new season to the database.
+func (db SeasonsDB) Create(ctx context.Context, dto SeasonRequest) (*Season, error) {
+	if err := db.validate(ctx, dto); err != nil {
+		return nil, err
+	}
+	now := clock.Now(db.Clock)
+	season := Season{
+		ID:        primitive.NewObjectID().Hex(),
+		Name:      dto.Name,
+		StartDate: dto.StartDate,
+		EndDate:   dto.EndDate,
+		Teams:     dto.Teams,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	_, err := db.InsertOne(ctx, &season, options.InsertOne().SetBypassDocumentValidation(false))
+	return &season, err
+}
+
+// Update changes a season's information in the database. Teams can't
+// be taken out, nor dates moved, from under the season's fixtures.
+// It returns (nil, nil) if no season matched.
+func (db SeasonsDB) Update(ctx context.Context, id string, dto SeasonRequest) (*Season, error) {
+	if err := db.validate(ctx, dto); err != nil {
+		return nil, err
+	}
+	if db.Fixtures != nil {
+		details, err := db.Fixtures.CheckSeason(ctx, Season{
+			ID:        id,
+			Name:      dto.Name,
+			StartDate: dto.StartDate,
+			EndDate:   dto.EndDate,
+			Teams:     dto.Teams,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if len(details) > 0 {
+			return nil, customErrors.ValidationError{
+				Code:    "seasons/invalid-season",
+				Message: "The season's fixtures would no longer fit in it.",
+				Details: details,
+			}
+		}
+	}
+	result, err := db.UpdateOne(ctx, bson.D{{Key: "_id", Value: id}},
+		bson.D{{Key: "$set", Value: bson.D{
+			{Key: "name", Value: dto.Name},
+			{Key: "start_date", Value: dto.StartDate},
+			{Key: "end_date", Value: dto.EndDate},
+			{Key: "teams", Value: dto.Teams},
+			{Key: "updated_at", Value: clock.Now(db.Clock)},
+		}}})
+	if err != nil {
+		return nil, err
+	}
+	if result.MatchedCount == 0 {
+		return nil, nil
+	}
+	return db.ByID(ctx, id)
+}
+
+// List fetches all the seasons in the database, latest first.
+func (db SeasonsDB) List(ctx context.Context) ([]Season, error) {
+	cursor, err := db.Find(ctx, bson.D{},
+		options.Find().SetSort(bson.D{{Key: "start_date", Value: -1}}))
+	if err != nil {
+		return nil, err
+	}
+	seasons := []Season{}
+	if err := cursor.All(ctx, &seasons); err != nil {
+		return nil, err
+	}
+	return seasons, nil
+}
+
+// ByID fetches a season by ID. It returns (nil, nil) if no season matched.
+func (db SeasonsDB) ByID(ctx context.Context, id string) (*Season, error) {
+	result := db.FindOne(ctx, bson.D{{Key: "_id", Value: id}})
+	err := result.Err()
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	season := Season{}
+	if err := result.Decode(&season); err != nil {
+		return nil, err
+	}
+	return &season, nil
+}
+
+// Delete removes a season from the database. Seasons with fixtures
+// are kept, and ErrInUse is returned.
+func (db SeasonsDB) Delete(ctx context.Context, id string) error {
+	if db.Fixtures != nil {
+		inUse, err := db.Fixtures.SeasonInUse(ctx, id)
+		if err != nil {
+			return err
+		}
+		if inUse {
+			return ErrInUse
+		}
+	}
+	_, err := db.DeleteOne(ctx, bson.D{{Key: "_id", Value: id}})
+	return err
+}
+
+// CountByTeam counts the seasons a team takes part in.
+func (db SeasonsDB) CountByTeam(ctx context.Context, teamID string) (int64, error) {
+	return db.Collection.CountDocuments(ctx, bson.D{{Key: "teams", Value: teamID}})
+}
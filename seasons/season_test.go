@@ -0,0 +1,73 @@
+package seasons
+
+import (
+	"testing"
+	"time"
+
+	"gomoney-mock-epl/errors"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSeasonRequest_Validate(t *testing.T) {
+	t.Run("Requires a name, dates and teams", func(t *testing.T) {
+		validationError, internalError := SeasonRequest{}.Validate()
+		assert.Nil(t, internalError)
+		assert.Equal(t, "seasons/invalid-season", validationError.Code)
+		assert.Contains(t, validationError.Details, errors.ValidationErrorDetails{
+			Field:   "name",
+			Message: "Season name is required",
+		})
+		assert.Contains(t, validationError.Details, errors.ValidationErrorDetails{
+			Field:   "teams",
+			Message: "Season teams are required",
+		})
+	})
+
+	t.Run("Requires the season to end after it starts", func(t *testing.T) {
+		start := time.Date(2026, time.August, 15, 0, 0, 0, 0, time.UTC)
+		validationError, _ := SeasonRequest{
+			Name:      "2026/27",
+			StartDate: start,
+			EndDate:   start.AddDate(0, -1, 0),
+			Teams:     []string{"a", "b"},
+		}.Validate()
+		assert.Contains(t, validationError.Details, errors.ValidationErrorDetails{
+			Field:   "end_date",
+			Message: "Season end date must be after its start date",
+		})
+	})
+
+	t.Run("Refuses duplicate teams", func(t *testing.T) {
+		start := time.Date(2026, time.August, 15, 0, 0, 0, 0, time.UTC)
+		validationError, _ := SeasonRequest{
+			Name:      "2026/27",
+			StartDate: start,
+			EndDate:   start.AddDate(0, 9, 0),
+			Teams:     []string{"a", "b", "a"},
+		}.Validate()
+		assert.Contains(t, validationError.Details, errors.ValidationErrorDetails{
+			Field:   "teams",
+			Message: "A team can only be added to a season once",
+		})
+	})
+}
+
+func TestSeason(t *testing.T) {
+	start := time.Date(2026, time.August, 15, 0, 0, 0, 0, time.UTC)
+	season := Season{
+		StartDate: start,
+		EndDate:   start.AddDate(0, 9, 0),
+		Teams:     []string{"a", "b", "c", "d"},
+	}
+	assert.True(t, season.Includes("c"))
+	assert.False(t, season.Includes("e"))
+	assert.True(t, season.Covers(start))
+	assert.False(t, season.Covers(start.Add(-time.Hour)))
+	assert.Equal(t, 6, season.Matchweeks())
+
+	season.Teams = []string{"a", "b", "c"}
+	assert.Equal(t, 6, season.Matchweeks(), "one team sits out each matchweek")
+	season.Teams = []string{"a", "b", "c", "d", "e"}
+	assert.Equal(t, 10, season.Matchweeks())
+}
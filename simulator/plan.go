@@ -0,0 +1,248 @@
+// Package simulator plays out the fixtures of the mock league. Matches are
+// planned from a seeded random model weighted by the strength of the teams,
+// so the same seed always plays a fixture out the same way.
+package simulator
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"time"
+
+	"gomoney-mock-epl/fixtures"
+	"gomoney-mock-epl/teams"
+)
+
+const (
+	// goalsPerMatch is the average number of goals scored in a match
+	// between two teams of equal strength, before home advantage.
+	goalsPerMatch = 2.7
+	homeAdvantage = 1.1
+	// Cards are given per team per match, on average.
+	yellowCardsPerMatch = 1.7
+	redCardsPerMatch    = 0.06
+	// The share of goals that are penalties and own goals.
+	penaltyShare = 0.1
+	ownGoalShare = 0.05
+	assistShare  = 0.7
+
+	maxSubstitutions = 5
+	halfTimeBreak    = 15 * time.Minute
+	matchMinute      = time.Minute
+)
+
+// Step is something that happens at some point after kick-off: either
+// the fixture changes status, or an event is added to its timeline.
+type Step struct {
+	At     time.Duration
+	Status fixtures.Status
+	Event  *fixtures.EventRequest
+}
+
+// side is a team's state during a planned match.
+type side struct {
+	team          *teams.Team
+	expectedGoals float64
+	onPitch       []int
+	bench         []int
+	substitutions int
+}
+
+func newSide(team *teams.Team, expectedGoals float64) *side {
+	s := &side{team: team, expectedGoals: expectedGoals}
+	for shirt := 1; shirt <= 11; shirt++ {
+		s.onPitch = append(s.onPitch, shirt)
+	}
+	for shirt := 12; shirt <= 20; shirt++ {
+		s.bench = append(s.bench, shirt)
+	}
+	return s
+}
+
+func (s *side) player(shirt int) string {
+	name := s.team.ShortName
+	if name == "" {
+		name = s.team.Name
+	}
+	return fmt.Sprintf("%s #%d", name, shirt)
+}
+
+// outfieldPlayer picks a player on the pitch other than the goalkeeper,
+// and other than the given shirt number.
+func (s *side) outfieldPlayer(r *rand.Rand, except int) int {
+	return s.pick(r, func(shirt int) bool { return shirt != 1 && shirt != except })
+}
+
+// starter picks an outfield player on the pitch that started the match.
+func (s *side) starter(r *rand.Rand) int {
+	return s.pick(r, func(shirt int) bool { return shirt != 1 && shirt <= 11 })
+}
+
+func (s *side) pick(r *rand.Rand, eligible func(int) bool) int {
+	candidates := []int{}
+	for _, shirt := range s.onPitch {
+		if eligible(shirt) {
+			candidates = append(candidates, shirt)
+		}
+	}
+	if len(candidates) == 0 {
+		return 0
+	}
+	return candidates[r.Intn(len(candidates))]
+}
+
+func (s *side) remove(shirt int) {
+	for i, onPitch := range s.onPitch {
+		if onPitch == shirt {
+			s.onPitch = append(s.onPitch[:i], s.onPitch[i+1:]...)
+			return
+		}
+	}
+}
+
+// seedFor combines the simulation seed with the fixture, so that each
+// fixture is played differently but reproducibly.
+func seedFor(seed int64, fixture fixtures.Fixture) int64 {
+	h := fnv.New64a()
+	h.Write(fixture.ID[:])
+	return seed ^ int64(h.Sum64())
+}
+
+// expectedGoals shares the goals of a match between the teams
+// according to their strength, with an advantage for the home team.
+func expectedGoals(home, away *teams.Team) (float64, float64) {
+	homeShare := float64(home.Strength()) / float64(home.Strength()+away.Strength())
+	return goalsPerMatch * homeShare * homeAdvantage,
+		goalsPerMatch * (1 - homeShare) * (2 - homeAdvantage)
+}
+
+// Plan works out how a fixture will be played. The plan only depends on
+// the seed, the fixture's ID and the strength of its teams.
+func Plan(fixture fixtures.Fixture, seed int64) []Step {
+	r := rand.New(rand.NewSource(seedFor(seed, fixture)))
+	homeGoals, awayGoals := expectedGoals(fixture.HomeTeam, fixture.AwayTeam)
+	home := newSide(fixture.HomeTeam, homeGoals)
+	away := newSide(fixture.AwayTeam, awayGoals)
+	substitutionMinutes := map[*side][]int{
+		home: substitutionWindows(r),
+		away: substitutionWindows(r),
+	}
+
+	firstHalfStoppage := 1 + r.Intn(5)
+	secondHalfStoppage := 2 + r.Intn(6)
+	secondHalfKickOff := time.Duration(45+firstHalfStoppage)*matchMinute + halfTimeBreak
+
+	steps := []Step{{At: 0, Status: fixtures.Live}}
+	play := func(minute, stoppage int, at time.Duration) {
+		for _, s := range []*side{home, away} {
+			other := away
+			if s == away {
+				other = home
+			}
+			for _, event := range playMinute(r, s, other, substitutionMinutes[s], minute, stoppage) {
+				event := event
+				steps = append(steps, Step{At: at, Event: &event})
+			}
+		}
+	}
+	for minute := 1; minute <= 45; minute++ {
+		play(minute, 0, time.Duration(minute)*matchMinute)
+	}
+	for stoppage := 1; stoppage <= firstHalfStoppage; stoppage++ {
+		play(45, stoppage, time.Duration(45+stoppage)*matchMinute)
+	}
+	steps = append(steps,
+		Step{At: time.Duration(45+firstHalfStoppage) * matchMinute, Status: fixtures.HalfTime},
+		Step{At: secondHalfKickOff, Status: fixtures.Live})
+	for minute := 46; minute <= 90; minute++ {
+		play(minute, 0, secondHalfKickOff+time.Duration(minute-45)*matchMinute)
+	}
+	for stoppage := 1; stoppage <= secondHalfStoppage; stoppage++ {
+		play(90, stoppage, secondHalfKickOff+time.Duration(45+stoppage)*matchMinute)
+	}
+	return append(steps, Step{
+		At:     secondHalfKickOff + time.Duration(45+secondHalfStoppage)*matchMinute,
+		Status: fixtures.Finished,
+	})
+}
+
+// substitutionWindows picks up to three minutes in the second
+// half when a team makes its substitutions.
+func substitutionWindows(r *rand.Rand) []int {
+	windows := []int{}
+	for _, window := range [][2]int{{55, 65}, {66, 78}, {79, 88}} {
+		if r.Float64() < 0.85 {
+			windows = append(windows, window[0]+r.Intn(window[1]-window[0]+1))
+		}
+	}
+	return windows
+}
+
+// playMinute works out what a side does in a minute of the match.
+func playMinute(r *rand.Rand, s, other *side, substitutionMinutes []int, minute, stoppage int) []fixtures.EventRequest {
+	events := []fixtures.EventRequest{}
+	event := func(eventType fixtures.EventType, team *side, shirt int) fixtures.EventRequest {
+		return fixtures.EventRequest{
+			Type:         eventType,
+			Team:         team.team.ID,
+			Player:       team.player(shirt),
+			Minute:       minute,
+			StoppageTime: stoppage,
+		}
+	}
+
+	if r.Float64() < s.expectedGoals/90 {
+		switch roll := r.Float64(); {
+		case roll < ownGoalShare:
+			// Own goals are scored by the other side's players.
+			if shirt := other.outfieldPlayer(r, 0); shirt != 0 {
+				events = append(events, event(fixtures.OwnGoal, other, shirt))
+			}
+		case roll < ownGoalShare+penaltyShare:
+			if shirt := s.outfieldPlayer(r, 0); shirt != 0 {
+				events = append(events, event(fixtures.PenaltyGoal, s, shirt))
+			}
+		default:
+			if shirt := s.outfieldPlayer(r, 0); shirt != 0 {
+				goal := event(fixtures.Goal, s, shirt)
+				if r.Float64() < assistShare {
+					if assist := s.outfieldPlayer(r, shirt); assist != 0 {
+						goal.RelatedPlayer = s.player(assist)
+					}
+				}
+				events = append(events, goal)
+			}
+		}
+	}
+	if r.Float64() < yellowCardsPerMatch/90 {
+		if shirt := s.outfieldPlayer(r, 0); shirt != 0 {
+			events = append(events, event(fixtures.YellowCard, s, shirt))
+		}
+	}
+	if r.Float64() < redCardsPerMatch/90 {
+		if shirt := s.outfieldPlayer(r, 0); shirt != 0 {
+			events = append(events, event(fixtures.RedCard, s, shirt))
+			s.remove(shirt)
+		}
+	}
+	for _, substitutionMinute := range substitutionMinutes {
+		if substitutionMinute != minute || stoppage != 0 {
+			continue
+		}
+		for changes := 1 + r.Intn(2); changes > 0 && s.substitutions < maxSubstitutions && len(s.bench) > 0; changes-- {
+			off := s.starter(r)
+			if off == 0 {
+				break
+			}
+			on := s.bench[0]
+			s.bench = s.bench[1:]
+			substitution := event(fixtures.Substitution, s, off)
+			substitution.RelatedPlayer = s.player(on)
+			events = append(events, substitution)
+			s.remove(off)
+			s.onPitch = append(s.onPitch, on)
+			s.substitutions++
+		}
+	}
+	return events
+}
@@ -0,0 +1,104 @@
+package simulator
+
+import (
+	"testing"
+
+	"gomoney-mock-epl/fixtures"
+	"gomoney-mock-epl/teams"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func fixture(homeRating, awayRating int) fixtures.Fixture {
+	return fixtures.Fixture{
+		ID:       primitive.NewObjectID(),
+		HomeTeam: &teams.Team{ID: "home", ShortName: "Home", Rating: homeRating},
+		AwayTeam: &teams.Team{ID: "away", ShortName: "Away", Rating: awayRating},
+	}
+}
+
+func score(f fixtures.Fixture, steps []Step) (int, int) {
+	home, away := 0, 0
+	for _, step := range steps {
+		if step.Event == nil {
+			continue
+		}
+		switch step.Event.Type {
+		case fixtures.Goal, fixtures.PenaltyGoal:
+			if step.Event.Team == f.HomeTeam.ID {
+				home++
+			} else {
+				away++
+			}
+		case fixtures.OwnGoal:
+			if step.Event.Team == f.HomeTeam.ID {
+				away++
+			} else {
+				home++
+			}
+		}
+	}
+	return home, away
+}
+
+func TestPlan(t *testing.T) {
+	t.Run("Plays a fixture the same way for the same seed", func(t *testing.T) {
+		f := fixture(70, 60)
+		assert.Equal(t, Plan(f, 42), Plan(f, 42))
+		assert.NotEqual(t, Plan(f, 42), Plan(f, 43))
+	})
+
+	t.Run("Plays different fixtures differently", func(t *testing.T) {
+		first, second := fixture(60, 60), fixture(60, 60)
+		assert.NotEqual(t, Plan(first, 1), Plan(second, 1))
+	})
+
+	t.Run("Moves the fixture through both halves in order", func(t *testing.T) {
+		statuses := []fixtures.Status{}
+		steps := Plan(fixture(50, 50), 7)
+		for i, step := range steps {
+			if i > 0 {
+				assert.True(t, step.At >= steps[i-1].At, "steps are out of order")
+			}
+			if step.Event == nil {
+				statuses = append(statuses, step.Status)
+			}
+		}
+		assert.Equal(t, []fixtures.Status{
+			fixtures.Live, fixtures.HalfTime, fixtures.Live, fixtures.Finished,
+		}, statuses)
+	})
+
+	t.Run("Only plans valid events", func(t *testing.T) {
+		for seed := int64(0); seed < 50; seed++ {
+			f := fixture(80, 40)
+			substitutions := map[string]int{}
+			for _, step := range Plan(f, seed) {
+				if step.Event == nil {
+					continue
+				}
+				validationErr, err := step.Event.Validate()
+				assert.Nil(t, err)
+				assert.Nil(t, validationErr, "%+v", step.Event)
+				if step.Event.Type == fixtures.Substitution {
+					substitutions[step.Event.Team]++
+				}
+			}
+			for _, count := range substitutions {
+				assert.True(t, count <= maxSubstitutions)
+			}
+		}
+	})
+
+	t.Run("Stronger teams score more", func(t *testing.T) {
+		strongGoals, weakGoals := 0, 0
+		for seed := int64(0); seed < 200; seed++ {
+			f := fixture(90, 30)
+			home, away := score(f, Plan(f, seed))
+			strongGoals += home
+			weakGoals += away
+		}
+		assert.Greater(t, strongGoals, 2*weakGoals)
+	})
+}
@@ -0,0 +1,152 @@
+package simulator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"gomoney-mock-epl/clock"
+	"gomoney-mock-epl/fixtures"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	// pollInterval is how often the simulator looks for fixtures to play.
+	pollInterval = 15 * time.Second
+	// lookback is how long after kick-off a fixture is still picked up, so
+	// that fixtures that kicked off while the simulator was down are not
+	// all played at once when it starts.
+	lookback = 15 * time.Minute
+	// stepInterval is how often the steps of the fixtures being played
+	// are checked. The clock can be changed at any time, so the simulator
+	// checks whether steps are due rather than sleeping until they are.
+	stepInterval = time.Second
+	// retries is the number of times a step is retried when the fixture
+	// is changed by someone else at the same time.
+	retries = 3
+)
+
+var errFixtureRemoved = errors.New("fixture no longer exists")
+
+// Simulator plays out scheduled fixtures when their kick-off arrives,
+// writing their status changes and events through the fixtures database.
+// The zero value of Speed plays matches in real time. The time is taken
+// from the clock, or the real time if it's not set.
+type Simulator struct {
+	Fixtures fixtures.DB
+	Seed     int64
+	// Speed is how many times faster than real time matches are played.
+	Speed float64
+	Clock clock.Clock
+
+	mu      sync.Mutex
+	playing map[primitive.ObjectID]bool
+	wg      sync.WaitGroup
+}
+
+func (s *Simulator) speed() float64 {
+	if s.Speed <= 0 {
+		return 1
+	}
+	return s.Speed
+}
+
+// Run plays fixtures as they kick off, until the context is cancelled.
+func (s *Simulator) Run(ctx context.Context) {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for {
+		if err := s.kickOff(ctx); err != nil {
+			log.Printf("simulator: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			s.wg.Wait()
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// kickOff starts playing the fixtures that have kicked off.
+func (s *Simulator) kickOff(ctx context.Context) error {
+	now := clock.Now(s.Clock)
+	due, err := s.Fixtures.KickingOff(ctx, now.Add(-lookback), now)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.playing == nil {
+		s.playing = map[primitive.ObjectID]bool{}
+	}
+	for _, fixture := range due {
+		if s.playing[fixture.ID] {
+			continue
+		}
+		s.playing[fixture.ID] = true
+		s.wg.Add(1)
+		go s.play(ctx, fixture)
+	}
+	return nil
+}
+
+// play applies the steps of a fixture's plan as their time comes. It
+// stops if a step can't be applied, like when an admin abandons the
+// fixture while it is being played.
+func (s *Simulator) play(ctx context.Context, fixture fixtures.Fixture) {
+	defer func() {
+		s.mu.Lock()
+		delete(s.playing, fixture.ID)
+		s.mu.Unlock()
+		s.wg.Done()
+	}()
+	ticker := time.NewTicker(stepInterval)
+	defer ticker.Stop()
+	steps := Plan(fixture, s.Seed)
+	for {
+		elapsed := time.Duration(float64(clock.Now(s.Clock).Sub(fixture.MatchDate)) * s.speed())
+		for len(steps) > 0 && steps[0].At <= elapsed {
+			if err := s.apply(ctx, fixture.ID, steps[0]); err != nil {
+				log.Printf("simulator: stopped playing fixture %s: %v", fixture.ID.Hex(), err)
+				return
+			}
+			steps = steps[1:]
+		}
+		if len(steps) == 0 {
+			return
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Simulator) apply(ctx context.Context, id primitive.ObjectID, step Step) error {
+	if step.Event == nil {
+		fixture, err := s.Fixtures.Transition(ctx, id, fixtures.TransitionRequest{Status: string(step.Status)})
+		if err == nil && fixture == nil {
+			return errFixtureRemoved
+		}
+		return err
+	}
+	for attempt := 1; ; attempt++ {
+		event, err := s.Fixtures.AddEvent(ctx, id, *step.Event)
+		if errors.Is(err, fixtures.ErrConcurrentUpdate) && attempt < retries {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("adding %s: %w", step.Event.Type, err)
+		}
+		if event == nil {
+			return errFixtureRemoved
+		}
+		return nil
+	}
+}
@@ -0,0 +1,248 @@
+package stadiums
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gomoney-mock-epl/clock"
+	customErrors "gomoney-mock-epl/errors"
+	"time"
+
+	v "github.com/go-ozzo/ozzo-validation"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Point is a GeoJSON point. Its coordinates are the
+// longitude then the latitude, as GeoJSON has them.
+type Point struct {
+	Type        string    `json:"type" bson:"type"`
+	Coordinates []float64 `json:"coordinates" bson:"coordinates"`
+}
+
+func NewPoint(latitude, longitude float64) *Point {
+	return &Point{Type: "Point", Coordinates: []float64{longitude, latitude}}
+}
+
+func (p Point) Latitude() float64 {
+	return p.Coordinates[1]
+}
+
+func (p Point) Longitude() float64 {
+	return p.Coordinates[0]
+}
+
+// Stadium is a ground matches are played at. Stadiums without a
+// location can't be found by distance.
+type Stadium struct {
+	ID        string    `json:"id" bson:"_id"`
+	Name      string    `json:"name" bson:"name"`
+	City      string    `json:"city" bson:"city"`
+	Capacity  int       `json:"capacity,omitempty" bson:"capacity,omitempty"`
+	Location  *Point    `json:"location,omitempty" bson:"location,omitempty"`
+	CreatedAt time.Time `json:"created_at" bson:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" bson:"updated_at"`
+}
+
+// StadiumRequest is the DTO we receive from the
+// clients when creating or updating stadiums.
+type StadiumRequest struct {
+	Name      string   `json:"name"`
+	City      string   `json:"city"`
+	Capacity  int      `json:"capacity"`
+	Latitude  *float64 `json:"latitude"`
+	Longitude *float64 `json:"longitude"`
+}
+
+func (r *StadiumRequest) FromStadium(stadium Stadium) *StadiumRequest {
+	r.Name = stadium.Name
+	r.City = stadium.City
+	r.Capacity = stadium.Capacity
+	r.Latitude, r.Longitude = nil, nil
+	if stadium.Location != nil {
+		latitude, longitude := stadium.Location.Latitude(), stadium.Location.Longitude()
+		r.Latitude, r.Longitude = &latitude, &longitude
+	}
+	return r
+}
+
+// location is where the request puts the stadium, if anywhere.
+func (r StadiumRequest) location() *Point {
+	if r.Latitude == nil || r.Longitude == nil {
+		return nil
+	}
+	return NewPoint(*r.Latitude, *r.Longitude)
+}
+
+// coordinateRule checks a coordinate is within its bounds, and
+// that it's given along with the other coordinate.
+func coordinateRule(other *float64, limit float64) v.RuleFunc {
+	return func(value interface{}) error {
+		coordinate := value.(*float64)
+		if (coordinate == nil) != (other == nil) {
+			return errors.New("Locations need both a latitude and a longitude")
+		}
+		if coordinate != nil && (*coordinate < -limit || *coordinate > limit) {
+			return fmt.Errorf("must be no less than %g and no greater than %g", -limit, limit)
+		}
+		return nil
+	}
+}
+
+func (r StadiumRequest) Validate() (*customErrors.ValidationError, error) {
+	err := v.ValidateStruct(&r,
+		v.Field(&r.Name, v.Required.Error("Stadium name is required"), v.Length(1, 100)),
+		v.Field(&r.City, v.Length(0, 100)),
+		v.Field(&r.Capacity, v.Min(0), v.Max(200000)),
+		v.Field(&r.Latitude, v.By(coordinateRule(r.Longitude, 90))),
+		v.Field(&r.Longitude, v.By(coordinateRule(r.Latitude, 180))),
+	)
+
+	return customErrors.ToValidationError(err,
+		"Parts of the stadium supplied are invalid.",
+		"stadiums/invalid-stadium")
+}
+
+// Syncer keeps the stadium fields copied into other documents, like the
+// home stadium and city of the teams that play there, up to date. It's
+// told about every update so the copies don't drift.
+type Syncer interface {
+	SyncStadium(ctx context.Context, stadium Stadium) error
+}
+
+// StadiumsDB stores stadiums. Timestamps are taken from the clock,
+// or the real time if it's not set. Updates are passed on to the
+// syncer, if there is one.
+type StadiumsDB struct {
+	*mongo.Collection
+	Syncer Syncer
+	Clock  clock.Clock
+}
+
+// Create adds a new stadium to the database.
+func (db StadiumsDB) Create(ctx context.Context, dto StadiumRequest) (*Stadium, error) {
+	validationErr, err := dto.Validate()
+	if err != nil {
+		return nil, err
+	}
+	if validationErr != nil {
+		return nil, *validationErr
+	}
+	now := clock.Now(db.Clock)
+	stadium := Stadium{
+		ID:        primitive.NewObjectID().Hex(),
+		Name:      dto.Name,
+		City:      dto.City,
+		Capacity:  dto.Capacity,
+		Location:  dto.location(),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	_, err = db.InsertOne(ctx, &stadium, options.InsertOne().SetBypassDocumentValidation(false))
+	return &stadium, err
+}
+
+// Update changes a stadium's information in the database, then has the
+// syncer rewrite the copies of its fields. It returns (nil, nil) if no
+// stadium matched.
+func (db StadiumsDB) Update(ctx context.Context, id string, dto StadiumRequest) (*Stadium, error) {
+	validationErr, err := dto.Validate()
+	if err != nil {
+		return nil, err
+	}
+	if validationErr != nil {
+		return nil, *validationErr
+	}
+	stadium, err := db.ByID(ctx, id)
+	if err != nil || stadium == nil {
+		return nil, err
+	}
+	stadium.Name = dto.Name
+	stadium.City = dto.City
+	stadium.Capacity = dto.Capacity
+	stadium.Location = dto.location()
+	stadium.UpdatedAt = clock.Now(db.Clock)
+	result, err := db.ReplaceOne(ctx, bson.D{{Key: "_id", Value: id}}, stadium)
+	if err != nil {
+		return nil, err
+	}
+	if result.MatchedCount == 0 {
+		return nil, nil
+	}
+	if db.Syncer != nil {
+		if err := db.Syncer.SyncStadium(ctx, *stadium); err != nil {
+			return stadium, err
+		}
+	}
+	return stadium, nil
+}
+
+// List fetches all the stadiums in the database, by name.
+func (db StadiumsDB) List(ctx context.Context) ([]Stadium, error) {
+	cursor, err := db.Find(ctx, bson.D{}, options.Find().SetSort(bson.D{{Key: "name", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	stadiums := []Stadium{}
+	if err := cursor.All(ctx, &stadiums); err != nil {
+		return nil, err
+	}
+	return stadiums, nil
+}
+
+// ByID fetches a stadium by ID. It returns (nil, nil) if no stadium matched.
+func (db StadiumsDB) ByID(ctx context.Context, id string) (*Stadium, error) {
+	result := db.FindOne(ctx, bson.D{{Key: "_id", Value: id}})
+	err := result.Err()
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	stadium := Stadium{}
+	if err := result.Decode(&stadium); err != nil {
+		return nil, err
+	}
+	return &stadium, nil
+}
+
+// Delete removes a stadium from the database.
+func (db StadiumsDB) Delete(ctx context.Context, id string) error {
+	_, err := db.DeleteOne(ctx, bson.D{{Key: "_id", Value: id}})
+	return err
+}
+
+// NearbyStadium is a stadium and how far away it is.
+type NearbyStadium struct {
+	Stadium    `bson:",inline"`
+	DistanceKM float64 `json:"distance_km" bson:"distance_km"`
+}
+
+func nearQuery(point Point, radiusKM float64) mongo.Pipeline {
+	return mongo.Pipeline{
+		bson.D{{Key: "$geoNear", Value: bson.D{
+			{Key: "near", Value: point},
+			{Key: "key", Value: "location"},
+			{Key: "spherical", Value: true},
+			{Key: "maxDistance", Value: radiusKM * 1000},
+			{Key: "distanceField", Value: "distance_km"},
+			{Key: "distanceMultiplier", Value: 0.001},
+		}}},
+	}
+}
+
+// Near lists the stadiums within a radius of a point, nearest first.
+func (db StadiumsDB) Near(ctx context.Context, point Point, radiusKM float64) ([]NearbyStadium, error) {
+	cursor, err := db.Aggregate(ctx, nearQuery(point, radiusKM))
+	if err != nil {
+		return nil, err
+	}
+	stadiums := []NearbyStadium{}
+	if err := cursor.All(ctx, &stadiums); err != nil {
+		return nil, err
+	}
+	return stadiums, nil
+}
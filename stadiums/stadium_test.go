@@ -0,0 +1,60 @@
+package stadiums
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func coordinate(f float64) *float64 {
+	return &f
+}
+
+func TestStadiumRequest_Validate(t *testing.T) {
+	t.Run("Requires the name", func(t *testing.T) {
+		validationErr, err := StadiumRequest{}.Validate()
+		assert.Nil(t, err)
+		assert.Equal(t, "stadiums/invalid-stadium", validationErr.Code)
+		assert.Equal(t, "name", validationErr.Details[0].Field)
+	})
+
+	t.Run("Accepts stadiums with or without a location", func(t *testing.T) {
+		validationErr, _ := StadiumRequest{Name: "Anfield", Capacity: 61276}.Validate()
+		assert.Nil(t, validationErr)
+		validationErr, _ = StadiumRequest{
+			Name: "Anfield", Latitude: coordinate(53.4308), Longitude: coordinate(-2.96096),
+		}.Validate()
+		assert.Nil(t, validationErr)
+	})
+
+	t.Run("Needs both coordinates, within their bounds", func(t *testing.T) {
+		validationErr, _ := StadiumRequest{Name: "Anfield", Latitude: coordinate(53.4308)}.Validate()
+		assert.NotNil(t, validationErr)
+		validationErr, _ = StadiumRequest{
+			Name: "Anfield", Latitude: coordinate(95), Longitude: coordinate(-190),
+		}.Validate()
+		assert.Len(t, validationErr.Details, 2)
+	})
+}
+
+func TestStadiumRequest_FromStadium(t *testing.T) {
+	stadium := Stadium{Name: "Anfield", City: "Liverpool", Location: NewPoint(53.4308, -2.96096)}
+	dto := (&StadiumRequest{}).FromStadium(stadium)
+	assert.Equal(t, 53.4308, *dto.Latitude)
+	assert.Equal(t, -2.96096, *dto.Longitude)
+	assert.Equal(t, stadium.Location, dto.location())
+}
+
+func TestNearQuery(t *testing.T) {
+	point := NewPoint(53.4308, -2.96096)
+	assert.Equal(t, []float64{-2.96096, 53.4308}, point.Coordinates)
+	assert.Equal(t, bson.D{{Key: "$geoNear", Value: bson.D{
+		{Key: "near", Value: *point},
+		{Key: "key", Value: "location"},
+		{Key: "spherical", Value: true},
+		{Key: "maxDistance", Value: 25000.0},
+		{Key: "distanceField", Value: "distance_km"},
+		{Key: "distanceMultiplier", Value: 0.001},
+	}}}, nearQuery(*point, 25)[0])
+}
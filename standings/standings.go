@@ -0,0 +1,209 @@
+package standings
+
+import (
+	"context"
+	"gomoney-mock-epl/fixtures"
+	"gomoney-mock-epl/teams"
+	"sort"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	pointsForWin  = 3
+	pointsForDraw = 1
+)
+
+// Row is a team's line in the league table.
+type Row struct {
+	Position       int         `json:"position"`
+	Team           *teams.Team `json:"team"`
+	Played         int         `json:"played"`
+	Won            int         `json:"won"`
+	Drawn          int         `json:"drawn"`
+	Lost           int         `json:"lost"`
+	GoalsFor       int         `json:"goals_for"`
+	GoalsAgainst   int         `json:"goals_against"`
+	GoalDifference int         `json:"goal_difference"`
+	Points         int         `json:"points"`
+
+	teamID string
+}
+
+// matchResult is the part of a finished fixture the table is built from.
+type matchResult struct {
+	HomeTeam string         `bson:"home_team"`
+	AwayTeam string         `bson:"away_team"`
+	Score    fixtures.Score `bson:"score"`
+}
+
+func (r *Row) record(goalsFor, goalsAgainst int) {
+	r.Played++
+	r.GoalsFor += goalsFor
+	r.GoalsAgainst += goalsAgainst
+	r.GoalDifference = r.GoalsFor - r.GoalsAgainst
+	switch {
+	case goalsFor > goalsAgainst:
+		r.Won++
+		r.Points += pointsForWin
+	case goalsFor == goalsAgainst:
+		r.Drawn++
+		r.Points += pointsForDraw
+	default:
+		r.Lost++
+	}
+}
+
+// Service computes league tables from the fixtures collection.
+type Service struct {
+	Fixtures fixtures.DB
+	Teams    teams.TeamsDB
+}
+
+func finishedResultsQuery(seasonID string) mongo.Pipeline {
+	filter := bson.D{
+		{Key: "status", Value: fixtures.Finished},
+		{Key: "result", Value: bson.D{{Key: "$exists", Value: true}}},
+	}
+	if seasonID != "" {
+		filter = append(filter, bson.E{Key: "season", Value: seasonID})
+	}
+	return mongo.Pipeline{
+		bson.D{{Key: "$match", Value: filter}},
+		bson.D{{Key: "$project", Value: bson.D{
+			{Key: "home_team", Value: 1},
+			{Key: "away_team", Value: 1},
+			{Key: "score", Value: "$result.full_time"},
+		}}},
+	}
+}
+
+// Table returns the league table. Every team is listed, including teams
+// that are yet to play, and archived teams so their results still count.
+// If seasonID is not empty, the table only covers that season and its
+// teams. It returns (nil, nil) if the season does not exist.
+func (s Service) Table(ctx context.Context, seasonID string) ([]Row, error) {
+	tableTeams, err := s.Teams.All(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if seasonID != "" {
+		season, err := s.Fixtures.SeasonsDB.ByID(ctx, seasonID)
+		if err != nil || season == nil {
+			return nil, err
+		}
+		seasonTeams := make([]teams.Team, 0, len(season.Teams))
+		for _, team := range tableTeams {
+			if season.Includes(team.ID) {
+				seasonTeams = append(seasonTeams, team)
+			}
+		}
+		tableTeams = seasonTeams
+	}
+	cursor, err := s.Fixtures.Aggregate(ctx, finishedResultsQuery(seasonID))
+	if err != nil {
+		return nil, err
+	}
+	results := []matchResult{}
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+	return compute(tableTeams, results), nil
+}
+
+// compute builds a table for the given teams from the results.
+// Results involving other teams are ignored.
+func compute(ts []teams.Team, results []matchResult) []Row {
+	rows := make(map[string]*Row, len(ts))
+	for i := range ts {
+		rows[ts[i].ID] = &Row{Team: &ts[i], teamID: ts[i].ID}
+	}
+	for _, result := range results {
+		home, away := rows[result.HomeTeam], rows[result.AwayTeam]
+		if home == nil || away == nil {
+			continue
+		}
+		home.record(result.Score.Home, result.Score.Away)
+		away.record(result.Score.Away, result.Score.Home)
+	}
+
+	table := make([]Row, 0, len(rows))
+	for _, row := range rows {
+		table = append(table, *row)
+	}
+	rank(table, results)
+	return table
+}
+
+// rank sorts the table using the Premier League tie-breakers: points,
+// goal difference, goals scored, then the points and away goals from the
+// matches between the tied teams. Teams that are still level are ordered
+// by name, which stands in for a play-off.
+func rank(table []Row, results []matchResult) {
+	sort.Slice(table, func(i, j int) bool {
+		a, b := table[i], table[j]
+		if a.Points != b.Points {
+			return a.Points > b.Points
+		}
+		if a.GoalDifference != b.GoalDifference {
+			return a.GoalDifference > b.GoalDifference
+		}
+		return a.GoalsFor > b.GoalsFor
+	})
+
+	for start := 0; start < len(table); {
+		end := start + 1
+		for end < len(table) && level(table[start], table[end]) {
+			end++
+		}
+		if end-start > 1 {
+			breakTie(table[start:end], results)
+		}
+		start = end
+	}
+
+	for i := range table {
+		table[i].Position = i + 1
+	}
+}
+
+func level(a, b Row) bool {
+	return a.Points == b.Points &&
+		a.GoalDifference == b.GoalDifference &&
+		a.GoalsFor == b.GoalsFor
+}
+
+func breakTie(tied []Row, results []matchResult) {
+	inGroup := make(map[string]bool, len(tied))
+	for _, row := range tied {
+		inGroup[row.teamID] = true
+	}
+	points := map[string]int{}
+	awayGoals := map[string]int{}
+	for _, result := range results {
+		if !inGroup[result.HomeTeam] || !inGroup[result.AwayTeam] {
+			continue
+		}
+		awayGoals[result.AwayTeam] += result.Score.Away
+		switch {
+		case result.Score.Home > result.Score.Away:
+			points[result.HomeTeam] += pointsForWin
+		case result.Score.Home < result.Score.Away:
+			points[result.AwayTeam] += pointsForWin
+		default:
+			points[result.HomeTeam] += pointsForDraw
+			points[result.AwayTeam] += pointsForDraw
+		}
+	}
+	sort.SliceStable(tied, func(i, j int) bool {
+		a, b := tied[i].teamID, tied[j].teamID
+		if points[a] != points[b] {
+			return points[a] > points[b]
+		}
+		if awayGoals[a] != awayGoals[b] {
+			return awayGoals[a] > awayGoals[b]
+		}
+		return tied[i].Team.Name < tied[j].Team.Name
+	})
+}
@@ -0,0 +1,90 @@
+package standings
+
+import (
+	"gomoney-mock-epl/fixtures"
+	"gomoney-mock-epl/teams"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testTeams = []teams.Team{
+	{ID: "ars", Name: "Arsenal"},
+	{ID: "che", Name: "Chelsea"},
+	{ID: "liv", Name: "Liverpool"},
+	{ID: "tot", Name: "Tottenham Hotspur"},
+}
+
+func result(home, away string, homeGoals, awayGoals int) matchResult {
+	return matchResult{
+		HomeTeam: home,
+		AwayTeam: away,
+		Score:    fixtures.Score{Home: homeGoals, Away: awayGoals},
+	}
+}
+
+func positions(table []Row) []string {
+	ids := make([]string, 0, len(table))
+	for _, row := range table {
+		ids = append(ids, row.Team.ID)
+	}
+	return ids
+}
+
+func TestCompute(t *testing.T) {
+	t.Run("Tallies results for both teams", func(t *testing.T) {
+		table := compute(testTeams, []matchResult{
+			result("ars", "che", 3, 1),
+			result("liv", "tot", 2, 2),
+		})
+		assert.Equal(t, []string{"ars", "tot", "liv", "che"}, positions(table))
+		assert.Equal(t, Row{
+			Position: 1, Team: &testTeams[0], Played: 1, Won: 1,
+			GoalsFor: 3, GoalsAgainst: 1, GoalDifference: 2, Points: 3, teamID: "ars",
+		}, table[0])
+		assert.Equal(t, 1, table[2].Drawn)
+		assert.Equal(t, 1, table[3].Lost)
+	})
+
+	t.Run("Lists teams that are yet to play", func(t *testing.T) {
+		table := compute(testTeams, nil)
+		assert.Len(t, table, len(testTeams))
+		assert.Equal(t, 1, table[0].Position)
+		assert.Equal(t, 4, table[3].Position)
+	})
+
+	t.Run("Ranks by goal difference then goals scored", func(t *testing.T) {
+		table := compute(testTeams, []matchResult{
+			result("ars", "che", 1, 0),
+			result("liv", "tot", 4, 3),
+			result("che", "tot", 3, 0),
+		})
+		assert.Equal(t, []string{"che", "liv", "ars", "tot"}, positions(table))
+	})
+
+	t.Run("Breaks ties on head-to-head points", func(t *testing.T) {
+		table := compute(testTeams, []matchResult{
+			result("ars", "tot", 0, 1),
+			result("ars", "liv", 1, 0),
+			result("tot", "che", 0, 1),
+		})
+		assert.Equal(t, []string{"che", "tot", "ars", "liv"}, positions(table))
+	})
+
+	t.Run("Breaks ties on head-to-head away goals", func(t *testing.T) {
+		table := compute(testTeams, []matchResult{
+			result("tot", "ars", 1, 2),
+			result("tot", "che", 2, 1),
+			result("ars", "che", 1, 2),
+		})
+		// Arsenal, Chelsea and Spurs are level on every count and took
+		// three points each from each other. Chelsea scored the most away
+		// goals in those games.
+		assert.Equal(t, []string{"che", "ars", "tot", "liv"}, positions(table))
+	})
+
+	t.Run("Ignores results of unknown teams", func(t *testing.T) {
+		table := compute(testTeams, []matchResult{result("ars", "mun", 5, 0)})
+		assert.Equal(t, 0, table[0].Played)
+	})
+}
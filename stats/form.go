@@ -0,0 +1,209 @@
+package stats
+
+import (
+	"context"
+	"gomoney-mock-epl/fixtures"
+	"gomoney-mock-epl/teams"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Outcome is a fixture's result from one team's side.
+type Outcome string
+
+const (
+	Win  = Outcome("W")
+	Draw = Outcome("D")
+	Loss = Outcome("L")
+)
+
+func outcome(goalsFor, goalsAgainst int) Outcome {
+	switch {
+	case goalsFor > goalsAgainst:
+		return Win
+	case goalsFor == goalsAgainst:
+		return Draw
+	}
+	return Loss
+}
+
+// FormResult is a finished fixture from one team's side.
+type FormResult struct {
+	FixtureID    primitive.ObjectID `json:"fixture_id"`
+	MatchDate    time.Time          `json:"match_date"`
+	Venue        fixtures.Venue     `json:"venue"`
+	Opponent     *teams.Team        `json:"opponent"`
+	Outcome      Outcome            `json:"outcome"`
+	GoalsFor     int                `json:"goals_for"`
+	GoalsAgainst int                `json:"goals_against"`
+}
+
+// Streaks are the runs a team is on, counted back from its latest result.
+type Streaks struct {
+	Winning  int `json:"winning"`
+	Unbeaten int `json:"unbeaten"`
+	Losing   int `json:"losing"`
+}
+
+// Split tallies a team's results at one venue.
+type Split struct {
+	Played       int `json:"played"`
+	Won          int `json:"won"`
+	Drawn        int `json:"drawn"`
+	Lost         int `json:"lost"`
+	GoalsFor     int `json:"goals_for"`
+	GoalsAgainst int `json:"goals_against"`
+}
+
+func (s *Split) record(result FormResult) {
+	s.Played++
+	s.GoalsFor += result.GoalsFor
+	s.GoalsAgainst += result.GoalsAgainst
+	switch result.Outcome {
+	case Win:
+		s.Won++
+	case Draw:
+		s.Drawn++
+	default:
+		s.Lost++
+	}
+}
+
+// Form is a team's form guide. The results are the team's latest, most
+// recent first; the streaks and splits cover all its finished fixtures.
+type Form struct {
+	Team    *teams.Team  `json:"team"`
+	Results []FormResult `json:"results"`
+	Streaks Streaks      `json:"streaks"`
+	Home    Split        `json:"home"`
+	Away    Split        `json:"away"`
+}
+
+// Last returns the form with only the last n results.
+func (f Form) Last(n int) Form {
+	if n < len(f.Results) {
+		f.Results = f.Results[:n]
+	}
+	return f
+}
+
+// form builds a team's form guide from its finished fixtures, given
+// latest first.
+func form(team *teams.Team, finished []fixtures.Fixture) Form {
+	f := Form{Team: team, Results: make([]FormResult, 0, len(finished))}
+	for _, fixture := range finished {
+		result := FormResult{FixtureID: fixture.ID, MatchDate: fixture.MatchDate}
+		score := fixture.Result.FullTime
+		if fixture.HomeTeam != nil && fixture.HomeTeam.ID == team.ID {
+			result.Venue, result.Opponent = fixtures.Home, fixture.AwayTeam
+			result.GoalsFor, result.GoalsAgainst = score.Home, score.Away
+			result.Outcome = outcome(score.Home, score.Away)
+			f.Home.record(result)
+		} else {
+			result.Venue, result.Opponent = fixtures.Away, fixture.HomeTeam
+			result.GoalsFor, result.GoalsAgainst = score.Away, score.Home
+			result.Outcome = outcome(score.Away, score.Home)
+			f.Away.record(result)
+		}
+		f.Results = append(f.Results, result)
+	}
+	f.Streaks = streaks(f.Results)
+	return f
+}
+
+func streaks(results []FormResult) Streaks {
+	s := Streaks{}
+	count := func(in func(Outcome) bool) int {
+		n := 0
+		for n < len(results) && in(results[n].Outcome) {
+			n++
+		}
+		return n
+	}
+	s.Winning = count(func(o Outcome) bool { return o == Win })
+	s.Unbeaten = count(func(o Outcome) bool { return o != Loss })
+	s.Losing = count(func(o Outcome) bool { return o == Loss })
+	return s
+}
+
+// FormCache keeps the form guides of teams until a change is made to one
+// of their fixtures, deletes included. It implements fixtures.Publisher
+// to hear about the changes. Renaming teams is not published, so entries
+// also expire after a while.
+type FormCache struct {
+	mu  sync.Mutex
+	ttl time.Duration
+	// generation counts the changes heard of, so that guides built
+	// while a change was being made are not kept.
+	generation uint64
+	forms      map[string]cachedForm
+}
+
+type cachedForm struct {
+	form    Form
+	expires time.Time
+}
+
+// NewFormCache creates a cache that keeps form guides for at most ttl.
+func NewFormCache(ttl time.Duration) *FormCache {
+	return &FormCache{ttl: ttl, forms: map[string]cachedForm{}}
+}
+
+func (c *FormCache) get(teamID string) (Form, uint64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cached, ok := c.forms[teamID]
+	if ok && time.Now().After(cached.expires) {
+		delete(c.forms, teamID)
+		ok = false
+	}
+	return cached.form, c.generation, ok
+}
+
+func (c *FormCache) put(teamID string, form Form, generation uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if generation == c.generation {
+		c.forms[teamID] = cachedForm{form: form, expires: time.Now().Add(c.ttl)}
+	}
+}
+
+// Publish forgets the form guides of the teams playing in the fixture.
+func (c *FormCache) Publish(change fixtures.Change) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.generation++
+	for _, team := range []*teams.Team{change.Fixture.HomeTeam, change.Fixture.AwayTeam} {
+		if team != nil {
+			delete(c.forms, team.ID)
+		}
+	}
+}
+
+// Form returns a team's form guide, from the cache if there is one. It
+// returns (nil, nil) if the team does not exist.
+func (s Service) Form(ctx context.Context, teamID string) (*Form, error) {
+	var generation uint64
+	if s.Forms != nil {
+		cached, gen, ok := s.Forms.get(teamID)
+		if ok {
+			return &cached, nil
+		}
+		generation = gen
+	}
+	team, err := s.Teams.ByID(ctx, teamID)
+	if err != nil || team == nil {
+		return nil, err
+	}
+	finished, err := s.Fixtures.TeamResults(ctx, team.ID)
+	if err != nil {
+		return nil, err
+	}
+	f := form(team, finished)
+	if s.Forms != nil {
+		s.Forms.put(teamID, f, generation)
+	}
+	return &f, nil
+}
@@ -0,0 +1,88 @@
+package stats
+
+import (
+	"gomoney-mock-epl/fixtures"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func outcomes(f Form) []Outcome {
+	o := []Outcome{}
+	for _, result := range f.Results {
+		o = append(o, result.Outcome)
+	}
+	return o
+}
+
+func TestForm(t *testing.T) {
+	finished := []fixtures.Fixture{
+		meeting(arsenal, chelsea, 2, 0),
+		meeting(chelsea, arsenal, 1, 3),
+		meeting(chelsea, arsenal, 1, 1),
+		meeting(arsenal, chelsea, 0, 1),
+		meeting(arsenal, chelsea, 4, 0),
+	}
+	f := form(arsenal, finished)
+
+	t.Run("Gives results from the team's side", func(t *testing.T) {
+		assert.Equal(t, []Outcome{Win, Win, Draw, Loss, Win}, outcomes(f))
+		assert.Equal(t, FormResult{
+			Venue: fixtures.Away, Opponent: chelsea, Outcome: Win, GoalsFor: 3, GoalsAgainst: 1,
+		}, f.Results[1])
+	})
+
+	t.Run("Counts streaks back from the latest result", func(t *testing.T) {
+		assert.Equal(t, Streaks{Winning: 2, Unbeaten: 3, Losing: 0}, f.Streaks)
+		assert.Equal(t, Streaks{Losing: 2}, form(chelsea, finished).Streaks)
+		assert.Equal(t, Streaks{}, form(chelsea, nil).Streaks)
+	})
+
+	t.Run("Splits home and away results", func(t *testing.T) {
+		assert.Equal(t, Split{Played: 3, Won: 2, Lost: 1, GoalsFor: 6, GoalsAgainst: 1}, f.Home)
+		assert.Equal(t, Split{Played: 2, Won: 1, Drawn: 1, GoalsFor: 4, GoalsAgainst: 2}, f.Away)
+	})
+
+	t.Run("Keeps the latest results", func(t *testing.T) {
+		assert.Equal(t, []Outcome{Win, Win}, outcomes(f.Last(2)))
+		assert.Len(t, f.Last(10).Results, 5)
+		assert.Len(t, f.Results, 5)
+	})
+}
+
+func TestFormCache(t *testing.T) {
+	cache := NewFormCache(time.Minute)
+	_, generation, ok := cache.get("ars")
+	assert.False(t, ok)
+	cache.put("ars", Form{Team: arsenal}, generation)
+
+	t.Run("Keeps form guides", func(t *testing.T) {
+		cached, _, ok := cache.get("ars")
+		assert.True(t, ok)
+		assert.Equal(t, arsenal, cached.Team)
+	})
+
+	t.Run("Forgets the teams in changed fixtures", func(t *testing.T) {
+		cache.Publish(fixtures.Change{Fixture: meeting(chelsea, arsenal, 0, 0)})
+		_, _, ok := cache.get("ars")
+		assert.False(t, ok)
+	})
+
+	t.Run("Does not keep guides built during a change", func(t *testing.T) {
+		_, generation, _ := cache.get("ars")
+		cache.Publish(fixtures.Change{Fixture: meeting(chelsea, arsenal, 0, 0)})
+		cache.put("ars", Form{Team: arsenal}, generation)
+		_, _, ok := cache.get("ars")
+		assert.False(t, ok)
+	})
+
+	t.Run("Expires form guides", func(t *testing.T) {
+		cache := NewFormCache(0)
+		_, generation, _ := cache.get("ars")
+		cache.put("ars", Form{Team: arsenal}, generation)
+		time.Sleep(time.Millisecond)
+		_, _, ok := cache.get("ars")
+		assert.False(t, ok)
+	})
+}
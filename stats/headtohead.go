@@ -0,0 +1,99 @@
+package stats
+
+import (
+	"context"
+	"gomoney-mock-epl/fixtures"
+	"gomoney-mock-epl/teams"
+)
+
+// Record is how a team has fared in the meetings between two teams.
+// The biggest win is the one with the widest margin, then the most
+// goals, then the latest; it is nil if the team has never won.
+type Record struct {
+	Team        *teams.Team       `json:"team"`
+	Won         int               `json:"won"`
+	Drawn       int               `json:"drawn"`
+	Lost        int               `json:"lost"`
+	GoalsScored int               `json:"goals_scored"`
+	BiggestWin  *fixtures.Fixture `json:"biggest_win"`
+}
+
+// HeadToHead compares two teams by the fixtures they have played
+// against each other, listed latest first.
+type HeadToHead struct {
+	Played    int                `json:"played"`
+	Team      Record             `json:"team"`
+	OtherTeam Record             `json:"other_team"`
+	Fixtures  []fixtures.Fixture `json:"fixtures"`
+}
+
+// HeadToHead compares two teams. It returns (nil, nil) if either
+// team does not exist.
+func (s Service) HeadToHead(ctx context.Context, teamID, otherTeamID string) (*HeadToHead, error) {
+	team, err := s.Teams.ByID(ctx, teamID)
+	if err != nil || team == nil {
+		return nil, err
+	}
+	otherTeam, err := s.Teams.ByID(ctx, otherTeamID)
+	if err != nil || otherTeam == nil {
+		return nil, err
+	}
+	meetings, err := s.Fixtures.ResultsBetween(ctx, team.ID, otherTeam.ID)
+	if err != nil {
+		return nil, err
+	}
+	return compare(team, otherTeam, meetings), nil
+}
+
+// compare tallies the results of the meetings, given latest first.
+func compare(team, otherTeam *teams.Team, meetings []fixtures.Fixture) *HeadToHead {
+	h2h := &HeadToHead{
+		Played:    len(meetings),
+		Team:      Record{Team: team},
+		OtherTeam: Record{Team: otherTeam},
+		Fixtures:  meetings,
+	}
+	for i := range meetings {
+		fixture := &meetings[i]
+		home, away := &h2h.Team, &h2h.OtherTeam
+		if fixture.HomeTeam.ID != team.ID {
+			home, away = away, home
+		}
+		score := fixture.Result.FullTime
+		home.record(fixture, score.Home, score.Away)
+		away.record(fixture, score.Away, score.Home)
+	}
+	return h2h
+}
+
+func (r *Record) record(fixture *fixtures.Fixture, goalsFor, goalsAgainst int) {
+	r.GoalsScored += goalsFor
+	switch {
+	case goalsFor > goalsAgainst:
+		r.Won++
+		if r.BiggestWin == nil || biggerWin(fixture.Result.FullTime, r.BiggestWin.Result.FullTime) {
+			r.BiggestWin = fixture
+		}
+	case goalsFor == goalsAgainst:
+		r.Drawn++
+	default:
+		r.Lost++
+	}
+}
+
+// biggerWin reports whether the winning score a is a bigger win than b.
+// Later wins are seen first, so ties go to b.
+func biggerWin(a, b fixtures.Score) bool {
+	marginA, marginB := abs(a.Home-a.Away), abs(b.Home-b.Away)
+	if marginA != marginB {
+		return marginA > marginB
+	}
+	return a.Home+a.Away > b.Home+b.Away
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,58 @@
+package stats
+
+import (
+	"gomoney-mock-epl/fixtures"
+	"gomoney-mock-epl/teams"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	arsenal = &teams.Team{ID: "ars", Name: "Arsenal"}
+	chelsea = &teams.Team{ID: "che", Name: "Chelsea"}
+)
+
+func meeting(home, away *teams.Team, homeGoals, awayGoals int) fixtures.Fixture {
+	return fixtures.Fixture{
+		HomeTeam: home,
+		AwayTeam: away,
+		Result:   &fixtures.Result{FullTime: fixtures.Score{Home: homeGoals, Away: awayGoals}},
+	}
+}
+
+func TestCompare(t *testing.T) {
+	t.Run("Tallies the meetings from each team's side", func(t *testing.T) {
+		h2h := compare(arsenal, chelsea, []fixtures.Fixture{
+			meeting(arsenal, chelsea, 2, 1),
+			meeting(chelsea, arsenal, 0, 0),
+			meeting(chelsea, arsenal, 3, 1),
+		})
+		assert.Equal(t, 3, h2h.Played)
+		assert.Equal(t, Record{Team: arsenal, Won: 1, Drawn: 1, Lost: 1, GoalsScored: 3, BiggestWin: &h2h.Fixtures[0]}, h2h.Team)
+		assert.Equal(t, Record{Team: chelsea, Won: 1, Drawn: 1, Lost: 1, GoalsScored: 4, BiggestWin: &h2h.Fixtures[2]}, h2h.OtherTeam)
+	})
+
+	t.Run("Picks the widest win, then the highest scoring, then the latest", func(t *testing.T) {
+		h2h := compare(arsenal, chelsea, []fixtures.Fixture{
+			meeting(arsenal, chelsea, 1, 0),
+			meeting(chelsea, arsenal, 1, 3),
+			meeting(arsenal, chelsea, 2, 0),
+			meeting(arsenal, chelsea, 4, 2),
+		})
+		assert.Same(t, &h2h.Fixtures[3], h2h.Team.BiggestWin)
+
+		h2h = compare(arsenal, chelsea, []fixtures.Fixture{
+			meeting(chelsea, arsenal, 1, 3),
+			meeting(arsenal, chelsea, 3, 1),
+		})
+		assert.Same(t, &h2h.Fixtures[0], h2h.Team.BiggestWin)
+	})
+
+	t.Run("Has no biggest win for teams that never won", func(t *testing.T) {
+		h2h := compare(arsenal, chelsea, []fixtures.Fixture{meeting(arsenal, chelsea, 1, 1)})
+		assert.Nil(t, h2h.Team.BiggestWin)
+		assert.Nil(t, h2h.OtherTeam.BiggestWin)
+		assert.Equal(t, 1, h2h.Team.Drawn)
+	})
+}
@@ -0,0 +1,77 @@
+package stats
+
+import (
+	"context"
+	"gomoney-mock-epl/fixtures"
+	"time"
+)
+
+// Period is the span of match dates statistics are taken from. A zero
+// time leaves the period open on that side.
+type Period struct {
+	From time.Time
+	To   time.Time
+}
+
+// PlayerStats adds up a player's performances over a period. It returns
+// (nil, nil) if the player does not exist.
+func (s Service) PlayerStats(ctx context.Context, playerID string, period Period) (*fixtures.PlayerTotals, error) {
+	player, err := s.Players.ByID(ctx, playerID)
+	if err != nil || player == nil {
+		return nil, err
+	}
+	totals, err := s.Fixtures.Totals(ctx, fixtures.TotalsQuery{
+		Player: player.ID,
+		From:   period.From,
+		To:     period.To,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(totals) == 0 {
+		return &fixtures.PlayerTotals{Player: player}, nil
+	}
+	return &totals[0], nil
+}
+
+// Leaderboard is a ranking of players by some of their numbers.
+type Leaderboard string
+
+const (
+	TopScorers = Leaderboard("top-scorers")
+	Assists    = Leaderboard("assists")
+	Cards      = Leaderboard("cards")
+)
+
+// leaderboardStats are the stats each leaderboard ranks players by.
+var leaderboardStats = map[Leaderboard][]string{
+	TopScorers: {"goals"},
+	Assists:    {"assists"},
+	Cards:      {"red_cards", "yellow_cards"},
+}
+
+// Leader is a player's place on a leaderboard.
+type Leader struct {
+	Position int `json:"position"`
+	fixtures.PlayerTotals
+}
+
+// Leaders ranks the players on a leaderboard over a period, up to limit
+// players. Players level on the leaderboard's stats are ranked by who
+// played fewer minutes.
+func (s Service) Leaders(ctx context.Context, board Leaderboard, period Period, limit int) ([]Leader, error) {
+	totals, err := s.Fixtures.Totals(ctx, fixtures.TotalsQuery{
+		From:    period.From,
+		To:      period.To,
+		Leaders: leaderboardStats[board],
+		Limit:   limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+	leaders := make([]Leader, len(totals))
+	for i, total := range totals {
+		leaders[i] = Leader{Position: i + 1, PlayerTotals: total}
+	}
+	return leaders, nil
+}
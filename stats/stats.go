@@ -0,0 +1,18 @@
+// Package stats computes statistics about teams and players from their
+// fixtures.
+package stats
+
+import (
+	"gomoney-mock-epl/fixtures"
+	"gomoney-mock-epl/players"
+	"gomoney-mock-epl/teams"
+)
+
+// Service computes statistics from the fixtures collection. Form
+// guides are cached if the service has a cache.
+type Service struct {
+	Fixtures fixtures.DB
+	Teams    teams.TeamsDB
+	Players  players.PlayersDB
+	Forms    *FormCache
+}
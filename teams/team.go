@@ -3,6 +3,8 @@ package teams
 import (
 	"context"
 	"errors"
+	"gomoney-mock-epl/clock"
+	"gomoney-mock-epl/stadiums"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
@@ -11,43 +13,146 @@ import (
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
+// AverageRating is the rating of teams that have not been rated.
+const AverageRating = 50
+
+// Team is a club in the league. The rating is the strength of the team
+// from 1 to 100, used to simulate its matches. Teams linked to a stadium
+// play their home fixtures there; their home stadium and city are taken
+// from the stadium when they are linked. Archived teams have left the
+// league, but are kept so the fixtures they played still resolve.
 type Team struct {
-	ID          string    `json:"id" bson:"_id"`
-	City        string    `json:"city" bson:"city"`
-	HomeStadium string    `json:"home_stadium" bson:"home_stadium"`
-	LogoURL     string    `json:"logo_url" bson:"logo_url"`
-	Name        string    `json:"name" bson:"name"`
-	NameAbbr    string    `json:"name_abbr" bson:"name_abbr"`
-	ShortName   string    `json:"short_name" bson:"short_name"`
-	CreatedAt   time.Time `json:"created_at" bson:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at" bson:"updated_at"`
+	ID          string     `json:"id" bson:"_id"`
+	City        string     `json:"city" bson:"city"`
+	HomeStadium string     `json:"home_stadium" bson:"home_stadium"`
+	Stadium     string     `json:"stadium,omitempty" bson:"stadium,omitempty"`
+	LogoURL     string     `json:"logo_url" bson:"logo_url"`
+	Name        string     `json:"name" bson:"name"`
+	NameAbbr    string     `json:"name_abbr" bson:"name_abbr"`
+	ShortName   string     `json:"short_name" bson:"short_name"`
+	Rating      int        `json:"rating,omitempty" bson:"rating,omitempty"`
+	ArchivedAt  *time.Time `json:"archived_at,omitempty" bson:"archived_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at" bson:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at" bson:"updated_at"`
 }
 
+// DeletePolicy is what happens to the fixtures of a team that's deleted.
+type DeletePolicy string
+
+const (
+	// Restrict refuses to delete teams that fixtures reference.
+	Restrict = DeletePolicy("restrict")
+	// Cascade deletes the team's fixtures with it.
+	Cascade = DeletePolicy("cascade")
+	// Archive keeps the team, archived, instead of deleting it.
+	Archive = DeletePolicy("archive")
+)
+
+// Strength is the team's rating, or the average rating if
+// the team has not been rated.
+func (t Team) Strength() int {
+	switch {
+	case t.Rating <= 0:
+		return AverageRating
+	case t.Rating > 100:
+		return 100
+	}
+	return t.Rating
+}
+
+// Syncer keeps the team fields copied into other documents, like the
+// team names fixtures store for text search, up to date. It's told
+// about every update so the copies don't drift.
+type Syncer interface {
+	SyncTeam(ctx context.Context, team Team) error
+}
+
+// TeamsDB stores teams. Timestamps are taken from the clock, or the
+// real time if it's not set. Updates are passed on to the syncer, if
+// there is one.
 type TeamsDB struct {
 	*mongo.Collection
+	Syncer Syncer
+	Clock  clock.Clock
 }
 
 // Create adds a new team to the database.
 func (t TeamsDB) Create(ctx context.Context, team Team) (*Team, error) {
 	team.ID = primitive.NewObjectID().Hex()
-	team.CreatedAt = time.Now()
+	team.CreatedAt = clock.Now(t.Clock)
 	team.UpdatedAt = team.CreatedAt
 	_, err := t.InsertOne(ctx, &team, options.InsertOne().SetBypassDocumentValidation(false))
 	return &team, err
 }
 
-// Update changes a team's information in the database.
+// Update changes a team's information in the database, then has the
+// syncer rewrite the copies of its fields.
 func (t TeamsDB) Update(ctx context.Context, team Team) (*Team, error) {
-	team.UpdatedAt = time.Now()
+	team.UpdatedAt = clock.Now(t.Clock)
 	filter := bson.D{bson.E{Key: "_id", Value: team.ID}}
-	_, err := t.ReplaceOne(ctx, filter, &team)
-	return &team, err
+	if _, err := t.ReplaceOne(ctx, filter, &team); err != nil {
+		return &team, err
+	}
+	if t.Syncer != nil {
+		if err := t.Syncer.SyncTeam(ctx, team); err != nil {
+			return &team, err
+		}
+	}
+	return &team, nil
 }
 
-// List fetches all the teams in the database. It's currently
-// not paginated.
+// SyncStadium rewrites the home stadium and city of the teams linked to
+// the stadium. It keeps teams in sync as stadiums are updated.
+func (t TeamsDB) SyncStadium(ctx context.Context, stadium stadiums.Stadium) error {
+	filter := bson.D{
+		{Key: "stadium", Value: stadium.ID},
+		{Key: "$or", Value: bson.A{
+			bson.D{{Key: "home_stadium", Value: bson.D{{Key: "$ne", Value: stadium.Name}}}},
+			bson.D{{Key: "city", Value: bson.D{{Key: "$ne", Value: stadium.City}}}},
+		}},
+	}
+	_, err := t.UpdateMany(ctx, filter, bson.D{{Key: "$set", Value: bson.D{
+		{Key: "home_stadium", Value: stadium.Name},
+		{Key: "city", Value: stadium.City},
+		{Key: "updated_at", Value: clock.Now(t.Clock)},
+	}}})
+	return err
+}
+
+// notArchived matches the teams that are still in the league.
+var notArchived = bson.E{Key: "archived_at", Value: bson.D{{Key: "$exists", Value: false}}}
+
+// List fetches the teams in the league, leaving out archived teams.
+// It's currently not paginated.
 func (t TeamsDB) List(ctx context.Context) ([]Team, error) {
-	cursor, err := t.Find(ctx, bson.D{})
+	return t.find(ctx, bson.D{notArchived})
+}
+
+// Archived fetches the archived teams.
+func (t TeamsDB) Archived(ctx context.Context) ([]Team, error) {
+	return t.find(ctx, bson.D{{Key: "archived_at", Value: bson.D{{Key: "$exists", Value: true}}}})
+}
+
+// All fetches every team in the database, archived or not.
+func (t TeamsDB) All(ctx context.Context) ([]Team, error) {
+	return t.find(ctx, bson.D{})
+}
+
+func (t TeamsDB) find(ctx context.Context, filter bson.D) ([]Team, error) {
+	cursor, err := t.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	teams := []Team{}
+	if err := cursor.All(ctx, &teams); err != nil {
+		return nil, err
+	}
+	return teams, nil
+}
+
+// AtStadiums fetches the teams that play at any of the stadiums.
+func (t TeamsDB) AtStadiums(ctx context.Context, stadiumIDs []string) ([]Team, error) {
+	cursor, err := t.Find(ctx, bson.D{{Key: "stadium", Value: bson.D{{Key: "$in", Value: stadiumIDs}}}})
 	if err != nil {
 		return nil, err
 	}
@@ -75,8 +180,34 @@ func (t TeamsDB) ByID(ctx context.Context, id string) (*Team, error) {
 	return &team, nil
 }
 
-// Delete removes a team from the database.
-func (t TeamsDB) Delete(ctx context.Context, id string) error {
-	_, err := t.DeleteOne(ctx, bson.E{Key: "_id", Value: id})
+// Archive takes a team out of the league without deleting it. Archiving
+// an archived team leaves it as it is. It returns (nil, nil) if no team
+// matched.
+func (t TeamsDB) Archive(ctx context.Context, id string) (*Team, error) {
+	now := clock.Now(t.Clock)
+	_, err := t.UpdateOne(ctx, bson.D{{Key: "_id", Value: id}, notArchived},
+		bson.D{{Key: "$set", Value: bson.D{
+			{Key: "archived_at", Value: now},
+			{Key: "updated_at", Value: now},
+		}}})
+	if err != nil {
+		return nil, err
+	}
+	return t.ByID(ctx, id)
+}
+
+// Reinsert puts a deleted team back as it was.
+func (t TeamsDB) Reinsert(ctx context.Context, team Team) error {
+	_, err := t.InsertOne(ctx, team)
 	return err
 }
+
+// Delete removes a team from the database. It reports whether
+// there was a team to remove.
+func (t TeamsDB) Delete(ctx context.Context, id string) (bool, error) {
+	result, err := t.DeleteOne(ctx, bson.D{{Key: "_id", Value: id}})
+	if err != nil {
+		return false, err
+	}
+	return result.DeletedCount > 0, nil
+}
@@ -0,0 +1,95 @@
+package transfers
+
+import (
+	"sort"
+	"time"
+)
+
+// Membership is a spell a player spent at a team. Since is nil for
+// spells that began before the first recorded transfer, and Until is
+// nil for spells that have not ended.
+type Membership struct {
+	Player string     `json:"player"`
+	Team   string     `json:"team"`
+	Since  *time.Time `json:"since"`
+	Until  *time.Time `json:"until"`
+	Loan   bool       `json:"loan"`
+}
+
+// covers reports whether the spell overlaps the range [from, to).
+func (m Membership) covers(from, to time.Time) bool {
+	return (m.Since == nil || m.Since.Before(to)) &&
+		(m.Until == nil || m.Until.After(from))
+}
+
+// memberships derives the spells of a player from their transfers.
+// A transfer ends the player's spell at the team they left and starts
+// one at the team they joined. A loan spell ends when the loan does, and
+// the player goes back to the team that loaned them out, unless another
+// transfer is made first.
+func memberships(player string, history []Transfer) []Membership {
+	history = append([]Transfer{}, history...)
+	sort.SliceStable(history, func(i, j int) bool {
+		return history[i].Date.Before(history[j].Date)
+	})
+	spells := []Membership{}
+	current := -1
+	leave := func(at time.Time) {
+		if current >= 0 {
+			spell := &spells[current]
+			if spell.Until == nil || at.Before(*spell.Until) {
+				until := at
+				spell.Until = &until
+			}
+		}
+		current = -1
+	}
+	join := func(team string, at *time.Time, loan bool) {
+		spells = append(spells, Membership{Player: player, Team: team, Since: at, Loan: loan})
+		current = len(spells) - 1
+	}
+	var returnTo *Transfer
+	comeBack := func() {
+		since := *returnTo.LoanEnd
+		join(returnTo.From, &since, false)
+		returnTo = nil
+	}
+
+	for i := range history {
+		transfer := history[i]
+		if returnTo != nil && !transfer.Date.Before(*returnTo.LoanEnd) {
+			comeBack()
+		}
+		if i == 0 && transfer.From != "" {
+			join(transfer.From, nil, false)
+		}
+		leave(transfer.Date)
+		returnTo = nil
+		if transfer.To == "" {
+			continue
+		}
+		since := transfer.Date
+		join(transfer.To, &since, transfer.Loan)
+		if transfer.Loan && transfer.LoanEnd != nil {
+			leave(*transfer.LoanEnd)
+			current = len(spells) - 1
+			if transfer.From != "" {
+				returnTo = &history[i]
+			}
+		}
+	}
+	if returnTo != nil {
+		comeBack()
+	}
+	return spells
+}
+
+// teamAt returns the team a player is at, at time t, from their spells.
+func teamAt(spells []Membership, t time.Time) string {
+	for _, spell := range spells {
+		if spell.covers(t, t.Add(time.Nanosecond)) {
+			return spell.Team
+		}
+	}
+	return ""
+}
@@ -0,0 +1,86 @@
+package transfers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func spell(team string, since, until *time.Time, loan bool) Membership {
+	return Membership{Player: "p", Team: team, Since: since, Until: until, Loan: loan}
+}
+
+func at(t time.Time) *time.Time {
+	return &t
+}
+
+func TestMemberships(t *testing.T) {
+	signed := date(2020, time.July, 1)
+	loaned := date(2021, time.August, 20)
+	loanEnds := date(2022, time.June, 30)
+	sold := date(2023, time.July, 15)
+
+	t.Run("Starts and ends spells with transfers", func(t *testing.T) {
+		spells := memberships("p", []Transfer{
+			{From: "che", To: "ars", Date: sold},
+			{To: "che", Date: signed},
+		})
+		assert.Equal(t, []Membership{
+			spell("che", at(signed), at(sold), false),
+			spell("ars", at(sold), nil, false),
+		}, spells)
+	})
+
+	t.Run("Starts with the team the player left in their first transfer", func(t *testing.T) {
+		spells := memberships("p", []Transfer{{From: "che", Date: sold}})
+		assert.Equal(t, []Membership{spell("che", nil, at(sold), false)}, spells)
+	})
+
+	t.Run("Sends players back at the end of loans", func(t *testing.T) {
+		spells := memberships("p", []Transfer{
+			{To: "che", Date: signed},
+			{From: "che", To: "cry", Date: loaned, Loan: true, LoanEnd: at(loanEnds)},
+		})
+		assert.Equal(t, []Membership{
+			spell("che", at(signed), at(loaned), false),
+			spell("cry", at(loaned), at(loanEnds), true),
+			spell("che", at(loanEnds), nil, false),
+		}, spells)
+		assert.Equal(t, "cry", teamAt(spells, loaned.AddDate(0, 1, 0)))
+		assert.Equal(t, "che", teamAt(spells, loanEnds))
+	})
+
+	t.Run("Cuts loans short with later transfers", func(t *testing.T) {
+		recalled := date(2022, time.January, 3)
+		spells := memberships("p", []Transfer{
+			{To: "che", Date: signed},
+			{From: "che", To: "cry", Date: loaned, Loan: true, LoanEnd: at(loanEnds)},
+			{From: "cry", To: "che", Date: recalled},
+		})
+		assert.Equal(t, []Membership{
+			spell("che", at(signed), at(loaned), false),
+			spell("cry", at(loaned), at(recalled), true),
+			spell("che", at(recalled), nil, false),
+		}, spells)
+	})
+
+	t.Run("Moves players on after loans end", func(t *testing.T) {
+		spells := memberships("p", []Transfer{
+			{To: "che", Date: signed},
+			{From: "che", To: "cry", Date: loaned, Loan: true, LoanEnd: at(loanEnds)},
+			{From: "che", To: "ars", Date: sold},
+		})
+		assert.Equal(t, spell("che", at(loanEnds), at(sold), false), spells[2])
+		assert.Equal(t, spell("ars", at(sold), nil, false), spells[3])
+		assert.Equal(t, "", teamAt(spells, signed.AddDate(0, 0, -1)))
+	})
+}
+
+func TestMembership_covers(t *testing.T) {
+	m := spell("che", at(date(2020, time.July, 1)), at(date(2023, time.July, 15)), false)
+	assert.True(t, m.covers(date(2023, time.January, 1), date(2024, time.January, 1)))
+	assert.False(t, m.covers(date(2024, time.January, 1), date(2025, time.January, 1)))
+	assert.False(t, m.covers(date(2019, time.January, 1), date(2020, time.July, 1)))
+	assert.True(t, spell("che", nil, nil, false).covers(date(2019, time.January, 1), date(2020, time.July, 1)))
+}
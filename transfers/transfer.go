@@ -0,0 +1,451 @@
+// Package transfers records the moves of players between teams, and
+// derives which teams players were at over time from them.
+package transfers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gomoney-mock-epl/clock"
+	customErrors "gomoney-mock-epl/errors"
+	"gomoney-mock-epl/players"
+	"log"
+	"sort"
+	"time"
+
+	v "github.com/go-ozzo/ozzo-validation"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Transfer is the move of a player from one team to another. Players
+// joining from or leaving for clubs outside the league have no from or
+// to team. Fees are in pounds. Loans end on their end date, when the
+// player goes back to the team that loaned them out.
+type Transfer struct {
+	ID        string     `json:"id" bson:"_id"`
+	Player    string     `json:"player" bson:"player"`
+	From      string     `json:"from,omitempty" bson:"from,omitempty"`
+	To        string     `json:"to,omitempty" bson:"to,omitempty"`
+	Date      time.Time  `json:"date" bson:"date"`
+	Window    Window     `json:"window" bson:"window"`
+	Fee       int64      `json:"fee" bson:"fee"`
+	Loan      bool       `json:"loan" bson:"loan"`
+	LoanEnd   *time.Time `json:"loan_end,omitempty" bson:"loan_end,omitempty"`
+	CreatedAt time.Time  `json:"created_at" bson:"created_at"`
+	// Applied and Returned record whether the player's team has been
+	// changed for the transfer, and back at the end of a loan.
+	Applied  bool `json:"-" bson:"applied"`
+	Returned bool `json:"-" bson:"returned,omitempty"`
+}
+
+// TransferRequest is the DTO we receive from the
+// clients when recording transfers.
+type TransferRequest struct {
+	Player  string     `json:"player"`
+	From    string     `json:"from"`
+	To      string     `json:"to"`
+	Date    time.Time  `json:"date"`
+	Fee     int64      `json:"fee"`
+	Loan    bool       `json:"loan"`
+	LoanEnd *time.Time `json:"loan_end"`
+}
+
+func (r TransferRequest) Validate() (*customErrors.ValidationError, error) {
+	loanEndRules := []v.Rule{}
+	if r.Loan {
+		loanEndRules = append(loanEndRules, v.Required.Error("Loans need an end date"),
+			v.By(func(interface{}) error {
+				if r.LoanEnd != nil && !r.LoanEnd.After(r.Date) {
+					return errors.New("Loans must end after they start")
+				}
+				return nil
+			}))
+	} else {
+		loanEndRules = append(loanEndRules, v.By(func(value interface{}) error {
+			if value.(*time.Time) != nil {
+				return errors.New("Only loans have an end date")
+			}
+			return nil
+		}))
+	}
+	err := v.ValidateStruct(&r,
+		v.Field(&r.Player, v.Required.Error("The player is required")),
+		v.Field(&r.To, v.By(func(value interface{}) error {
+			switch to := value.(string); {
+			case to == "" && r.From == "":
+				return errors.New("Transfers need a team to move from or to")
+			case to != "" && to == r.From:
+				return errors.New("Players cannot move to the team they are at")
+			case to == "" && r.Loan:
+				return errors.New("Loans need a team to move to")
+			}
+			return nil
+		})),
+		v.Field(&r.Date, v.Required.Error("The transfer date is required")),
+		v.Field(&r.Fee, v.Min(0).Error("Fees cannot be negative")),
+		v.Field(&r.LoanEnd, loanEndRules...),
+	)
+
+	return customErrors.ToValidationError(err,
+		"Parts of the transfer supplied are invalid.",
+		"transfers/invalid-transfer")
+}
+
+// DB records transfers, and moves players between teams as they take
+// effect. It uses the players database, and the teams database through
+// it, for lookups. The time is taken from the clock, or the real time
+// if it's not set.
+type DB struct {
+	*mongo.Collection
+	players.PlayersDB
+	Clock clock.Clock
+}
+
+// Now is the time on the registry's clock.
+func (db DB) Now() time.Time {
+	return clock.Now(db.Clock)
+}
+
+func invalidTransfer(field, message string) error {
+	return customErrors.ValidationError{
+		Code:    "transfers/invalid-transfer",
+		Message: "Parts of the transfer supplied are invalid.",
+		Details: []customErrors.ValidationErrorDetails{{Field: field, Message: message}},
+	}
+}
+
+// validate checks the request, that the player and teams exist, and that
+// the transfer follows on from the player's history: it cannot be dated
+// before their last transfer, and they must be at the team they leave.
+func (db DB) validate(ctx context.Context, dto TransferRequest, player *players.Player, history []Transfer) error {
+	validationErr, err := dto.Validate()
+	if err != nil {
+		return err
+	}
+	if validationErr != nil {
+		return *validationErr
+	}
+	for field, teamID := range map[string]string{"from": dto.From, "to": dto.To} {
+		if teamID == "" {
+			continue
+		}
+		team, err := db.TeamsDB.ByID(ctx, teamID)
+		if err != nil {
+			return err
+		}
+		if team == nil {
+			return invalidTransfer(field, "Unknown team "+teamID)
+		}
+	}
+	if len(history) > 0 && dto.Date.Before(history[len(history)-1].Date) {
+		return invalidTransfer("date", "Transfers cannot be dated before the player's last transfer")
+	}
+	current := player.Team
+	if len(history) > 0 {
+		current = teamAt(memberships(player.ID, history), dto.Date)
+	}
+	if dto.From != current {
+		return invalidTransfer("from", "The player is not at this team on the transfer date")
+	}
+	return nil
+}
+
+// Record adds a transfer to the registry. Transfers that have taken
+// effect move the player straight away; later ones are applied by Settle.
+func (db DB) Record(ctx context.Context, dto TransferRequest) (*Transfer, error) {
+	player, err := db.PlayersDB.ByID(ctx, dto.Player)
+	if err != nil {
+		return nil, err
+	}
+	if player == nil {
+		if dto.Player == "" {
+			return nil, invalidTransfer("player", "The player is required")
+		}
+		return nil, invalidTransfer("player", "Unknown player "+dto.Player)
+	}
+	history, err := db.ByPlayer(ctx, player.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.validate(ctx, dto, player, history); err != nil {
+		return nil, err
+	}
+	transfer := Transfer{
+		ID:        primitive.NewObjectID().Hex(),
+		Player:    player.ID,
+		From:      dto.From,
+		To:        dto.To,
+		Date:      dto.Date,
+		Window:    WindowOf(dto.Date),
+		Fee:       dto.Fee,
+		Loan:      dto.Loan,
+		LoanEnd:   dto.LoanEnd,
+		CreatedAt: db.Now(),
+	}
+	if _, err := db.InsertOne(ctx, &transfer, options.InsertOne().SetBypassDocumentValidation(false)); err != nil {
+		return nil, err
+	}
+	if err := db.Settle(ctx); err != nil {
+		return nil, err
+	}
+	return db.ByID(ctx, transfer.ID)
+}
+
+func (db DB) find(ctx context.Context, filter bson.D) ([]Transfer, error) {
+	cursor, err := db.Find(ctx, filter, options.Find().SetSort(bson.D{
+		{Key: "date", Value: 1},
+		{Key: "created_at", Value: 1},
+	}))
+	if err != nil {
+		return nil, err
+	}
+	transfers := []Transfer{}
+	if err := cursor.All(ctx, &transfers); err != nil {
+		return nil, err
+	}
+	return transfers, nil
+}
+
+// ByID fetches a transfer by ID. It returns (nil, nil) if no transfer matched.
+func (db DB) ByID(ctx context.Context, id string) (*Transfer, error) {
+	result := db.FindOne(ctx, bson.D{{Key: "_id", Value: id}})
+	err := result.Err()
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	transfer := Transfer{}
+	if err := result.Decode(&transfer); err != nil {
+		return nil, err
+	}
+	return &transfer, nil
+}
+
+// ByPlayer lists a player's transfers in the order they were made.
+func (db DB) ByPlayer(ctx context.Context, playerID string) ([]Transfer, error) {
+	return db.find(ctx, bson.D{{Key: "player", Value: playerID}})
+}
+
+// History is a player's transfers, and the spells at teams derived
+// from them.
+type History struct {
+	Transfers   []Transfer   `json:"transfers"`
+	Memberships []Membership `json:"memberships"`
+}
+
+// PlayerHistory returns a player's transfer history. It returns
+// (nil, nil) if the player does not exist.
+func (db DB) PlayerHistory(ctx context.Context, playerID string) (*History, error) {
+	player, err := db.PlayersDB.ByID(ctx, playerID)
+	if err != nil || player == nil {
+		return nil, err
+	}
+	transfers, err := db.ByPlayer(ctx, player.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &History{Transfers: transfers, Memberships: memberships(player.ID, transfers)}, nil
+}
+
+// WindowTransfers are the players a team signed and let go in a window.
+type WindowTransfers struct {
+	Window Window     `json:"window"`
+	In     []Transfer `json:"in"`
+	Out    []Transfer `json:"out"`
+}
+
+func involving(teamID string) bson.E {
+	return bson.E{Key: "$or", Value: bson.A{
+		bson.D{{Key: "from", Value: teamID}},
+		bson.D{{Key: "to", Value: teamID}},
+	}}
+}
+
+// ByTeam lists a team's transfers by window, latest window first. If
+// window is not empty, only that window is listed.
+func (db DB) ByTeam(ctx context.Context, teamID string, window Window) ([]WindowTransfers, error) {
+	filter := bson.D{involving(teamID)}
+	if window != "" {
+		filter = append(filter, bson.E{Key: "window", Value: window})
+	}
+	transfers, err := db.find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	return byWindow(teamID, transfers), nil
+}
+
+func byWindow(teamID string, transfers []Transfer) []WindowTransfers {
+	windows := map[Window]*WindowTransfers{}
+	for _, transfer := range transfers {
+		w := windows[transfer.Window]
+		if w == nil {
+			w = &WindowTransfers{Window: transfer.Window, In: []Transfer{}, Out: []Transfer{}}
+			windows[transfer.Window] = w
+		}
+		if transfer.To == teamID {
+			w.In = append(w.In, transfer)
+		} else {
+			w.Out = append(w.Out, transfer)
+		}
+	}
+	list := make([]WindowTransfers, 0, len(windows))
+	for _, w := range windows {
+		list = append(list, *w)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		a, _ := list[i].Window.Opens()
+		b, _ := list[j].Window.Opens()
+		return a.After(b)
+	})
+	return list
+}
+
+// Member is a player's spell at a team.
+type Member struct {
+	Player *players.Player `json:"player"`
+	Membership
+}
+
+// Members lists the spells of the players who were at a team at some
+// point in [from, to), derived from the transfers made to and from it.
+// Players who have never been transferred are not listed.
+func (db DB) Members(ctx context.Context, teamID string, from, to time.Time) ([]Member, error) {
+	playerIDs, err := db.Distinct(ctx, "player", bson.D{involving(teamID)})
+	if err != nil {
+		return nil, err
+	}
+	transfers, err := db.find(ctx, bson.D{{Key: "player", Value: bson.D{{Key: "$in", Value: playerIDs}}}})
+	if err != nil {
+		return nil, err
+	}
+	histories := map[string][]Transfer{}
+	for _, transfer := range transfers {
+		histories[transfer.Player] = append(histories[transfer.Player], transfer)
+	}
+	members := []Member{}
+	for playerID, history := range histories {
+		for _, spell := range memberships(playerID, history) {
+			if spell.Team != teamID || !spell.covers(from, to) {
+				continue
+			}
+			player, err := db.PlayersDB.ByID(ctx, playerID)
+			if err != nil {
+				return nil, err
+			}
+			if player != nil {
+				members = append(members, Member{Player: player, Membership: spell})
+			}
+		}
+	}
+	sort.Slice(members, func(i, j int) bool {
+		if members[i].Player.Name != members[j].Player.Name {
+			return members[i].Player.Name < members[j].Player.Name
+		}
+		return members[i].Since != nil && members[j].Since != nil && members[i].Since.Before(*members[j].Since)
+	})
+	return members, nil
+}
+
+// Settle moves players whose transfers have taken effect to their new
+// team, and players whose loans have ended back to the team that loaned
+// them out, in the order the moves happened.
+func (db DB) Settle(ctx context.Context) error {
+	now := db.Now()
+	due, err := db.find(ctx, bson.D{{Key: "$or", Value: bson.A{
+		bson.D{
+			{Key: "applied", Value: false},
+			{Key: "date", Value: bson.D{{Key: "$lte", Value: now}}},
+		},
+		bson.D{
+			{Key: "loan", Value: true},
+			{Key: "returned", Value: bson.D{{Key: "$ne", Value: true}}},
+			{Key: "loan_end", Value: bson.D{{Key: "$lte", Value: now}}},
+		},
+	}}})
+	if err != nil {
+		return err
+	}
+	type move struct {
+		transfer Transfer
+		at       time.Time
+		back     bool
+	}
+	moves := []move{}
+	for _, transfer := range due {
+		if !transfer.Applied && !transfer.Date.After(now) {
+			moves = append(moves, move{transfer: transfer, at: transfer.Date})
+		}
+		if transfer.Loan && !transfer.Returned && transfer.LoanEnd != nil && !transfer.LoanEnd.After(now) {
+			moves = append(moves, move{transfer: transfer, at: *transfer.LoanEnd, back: true})
+		}
+	}
+	sort.SliceStable(moves, func(i, j int) bool { return moves[i].at.Before(moves[j].at) })
+	for _, m := range moves {
+		if err := db.settle(ctx, m.transfer, m.back, now); err != nil {
+			return fmt.Errorf("settling transfer %s: %w", m.transfer.ID, err)
+		}
+	}
+	return nil
+}
+
+// settle makes one move. A player only goes back at the end of a loan
+// if they are still at the team they were loaned to.
+func (db DB) settle(ctx context.Context, transfer Transfer, back bool, now time.Time) error {
+	flag, teamID := "applied", transfer.To
+	if back {
+		flag, teamID = "returned", transfer.From
+		history, err := db.ByPlayer(ctx, transfer.Player)
+		if err != nil {
+			return err
+		}
+		if teamAt(memberships(transfer.Player, history), now) != teamID {
+			teamID = ""
+		}
+	}
+	result, err := db.UpdateOne(ctx,
+		bson.D{{Key: "_id", Value: transfer.ID}, {Key: flag, Value: bson.D{{Key: "$ne", Value: true}}}},
+		bson.D{{Key: "$set", Value: bson.D{{Key: flag, Value: true}}}})
+	if err != nil || result.ModifiedCount == 0 {
+		// Someone else settled it first.
+		return err
+	}
+	if back && teamID == "" {
+		return nil
+	}
+	return db.PlayersDB.Move(ctx, transfer.Player, teamID)
+}
+
+// settleInterval is how often Run settles transfers.
+const settleInterval = time.Minute
+
+// Run settles transfers as they take effect, until the context is
+// cancelled. The clock can be changed at any time, so transfers are
+// checked regularly rather than waited for.
+func (db DB) Run(ctx context.Context) {
+	ticker := time.NewTicker(settleInterval)
+	defer ticker.Stop()
+	for {
+		if err := db.Settle(ctx); err != nil {
+			log.Printf("transfers: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// CountByTeam counts the transfers a team made, in or out.
+func (db DB) CountByTeam(ctx context.Context, teamID string) (int64, error) {
+	return db.Collection.CountDocuments(ctx, bson.D{{Key: "$or", Value: bson.A{
+		bson.D{{Key: "from", Value: teamID}},
+		bson.D{{Key: "to", Value: teamID}},
+	}}})
+}
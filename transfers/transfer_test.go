@@ -0,0 +1,87 @@
+package transfers
+
+import (
+	"testing"
+	"time"
+
+	"gomoney-mock-epl/errors"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransferRequest_Validate(t *testing.T) {
+	loan := TransferRequest{
+		Player:  "p",
+		From:    "che",
+		To:      "cry",
+		Date:    date(2021, time.August, 20),
+		Loan:    true,
+		LoanEnd: at(date(2022, time.June, 30)),
+	}
+
+	t.Run("Accepts loans", func(t *testing.T) {
+		validationError, internalError := loan.Validate()
+		assert.Nil(t, internalError)
+		assert.Nil(t, validationError)
+	})
+
+	t.Run("Requires a player, a date and a team", func(t *testing.T) {
+		validationError, _ := TransferRequest{}.Validate()
+		assert.Equal(t, "transfers/invalid-transfer", validationError.Code)
+		assert.ElementsMatch(t, []errors.ValidationErrorDetails{
+			{Field: "player", Message: "The player is required"},
+			{Field: "date", Message: "The transfer date is required"},
+			{Field: "to", Message: "Transfers need a team to move from or to"},
+		}, validationError.Details)
+	})
+
+	t.Run("Requires loans to end after they start", func(t *testing.T) {
+		dto := loan
+		dto.LoanEnd = at(dto.Date)
+		validationError, _ := dto.Validate()
+		assert.Contains(t, validationError.Details, errors.ValidationErrorDetails{
+			Field: "loan_end", Message: "Loans must end after they start",
+		})
+		dto.LoanEnd = nil
+		validationError, _ = dto.Validate()
+		assert.Contains(t, validationError.Details, errors.ValidationErrorDetails{
+			Field: "loan_end", Message: "Loans need an end date",
+		})
+	})
+
+	t.Run("Only gives loans an end date", func(t *testing.T) {
+		dto := loan
+		dto.Loan = false
+		validationError, _ := dto.Validate()
+		assert.Contains(t, validationError.Details, errors.ValidationErrorDetails{
+			Field: "loan_end", Message: "Only loans have an end date",
+		})
+	})
+
+	t.Run("Refuses moves to the same team, and negative fees", func(t *testing.T) {
+		dto := loan
+		dto.To = dto.From
+		dto.Fee = -1
+		validationError, _ := dto.Validate()
+		assert.ElementsMatch(t, []errors.ValidationErrorDetails{
+			{Field: "to", Message: "Players cannot move to the team they are at"},
+			{Field: "fee", Message: "Fees cannot be negative"},
+		}, validationError.Details)
+	})
+}
+
+func TestByWindow(t *testing.T) {
+	windows := byWindow("che", []Transfer{
+		{ID: "1", To: "che", Window: "2024-summer"},
+		{ID: "2", From: "che", To: "ars", Window: "2024-summer"},
+		{ID: "3", From: "che", Window: "2025-winter"},
+	})
+	assert.Equal(t, []WindowTransfers{
+		{Window: "2025-winter", In: []Transfer{}, Out: []Transfer{{ID: "3", From: "che", Window: "2025-winter"}}},
+		{
+			Window: "2024-summer",
+			In:     []Transfer{{ID: "1", To: "che", Window: "2024-summer"}},
+			Out:    []Transfer{{ID: "2", From: "che", To: "ars", Window: "2024-summer"}},
+		},
+	}, windows)
+}
@@ -0,0 +1,57 @@
+package transfers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	summer = "summer"
+	winter = "winter"
+)
+
+// Window is a transfer window, named like "2024-summer". The summer
+// window opens in June and the winter window in January. Transfers
+// made between windows, such as the signing of free agents, belong to
+// the window before them.
+type Window string
+
+// WindowOf returns the window a transfer made at t belongs to.
+func WindowOf(t time.Time) Window {
+	t = t.UTC()
+	if t.Month() >= time.June {
+		return Window(fmt.Sprintf("%d-%s", t.Year(), summer))
+	}
+	return Window(fmt.Sprintf("%d-%s", t.Year(), winter))
+}
+
+// Opens returns when the window opens. It returns false for names
+// that are not windows.
+func (w Window) Opens() (time.Time, bool) {
+	parts := strings.SplitN(string(w), "-", 2)
+	if len(parts) != 2 {
+		return time.Time{}, false
+	}
+	year, err := strconv.Atoi(parts[0])
+	if err != nil || year < 1 {
+		return time.Time{}, false
+	}
+	switch parts[1] {
+	case summer:
+		return time.Date(year, time.June, 1, 0, 0, 0, 0, time.UTC), true
+	case winter:
+		return time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC), true
+	}
+	return time.Time{}, false
+}
+
+// Next returns the window after w.
+func (w Window) Next() Window {
+	opens, _ := w.Opens()
+	if opens.Month() == time.January {
+		return WindowOf(opens.AddDate(0, 5, 0))
+	}
+	return WindowOf(opens.AddDate(1, -5, 0))
+}
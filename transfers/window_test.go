@@ -0,0 +1,41 @@
+package transfers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestWindowOf(t *testing.T) {
+	t.Run("Places transfers in the window they are made in", func(t *testing.T) {
+		assert.Equal(t, Window("2024-summer"), WindowOf(date(2024, time.August, 30)))
+		assert.Equal(t, Window("2025-winter"), WindowOf(date(2025, time.January, 31)))
+	})
+
+	t.Run("Places transfers between windows in the window before", func(t *testing.T) {
+		assert.Equal(t, Window("2024-summer"), WindowOf(date(2024, time.October, 2)))
+		assert.Equal(t, Window("2025-winter"), WindowOf(date(2025, time.March, 12)))
+	})
+}
+
+func TestWindow(t *testing.T) {
+	t.Run("Knows when windows open", func(t *testing.T) {
+		opens, ok := Window("2024-summer").Opens()
+		assert.True(t, ok)
+		assert.Equal(t, date(2024, time.June, 1), opens)
+		_, ok = Window("2024-autumn").Opens()
+		assert.False(t, ok)
+		_, ok = Window("summer").Opens()
+		assert.False(t, ok)
+	})
+
+	t.Run("Knows the next window", func(t *testing.T) {
+		assert.Equal(t, Window("2025-winter"), Window("2024-summer").Next())
+		assert.Equal(t, Window("2025-summer"), Window("2025-winter").Next())
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"gomoney-mock-epl/clock"
 
 	"github.com/dgrijalva/jwt-go"
 	"golang.org/x/crypto/bcrypt"
@@ -65,5 +66,5 @@ func LoginAsAdmin(ctx context.Context, db AdminsDB, dto LoginDto) (*jwt.Token, e
 	if err != nil {
 		return nil, ErrIncorrectLogin
 	}
-	return makeJWT(JwtRequest{subject: admin.ID, IsAdmin: true}), nil
+	return makeJWT(JwtRequest{subject: admin.ID, IsAdmin: true}, clock.Now(db.Clock)), nil
 }
@@ -22,12 +22,13 @@ type jwtClaims struct {
 	*JwtRequest
 }
 
-func makeJWT(request JwtRequest) *jwt.Token {
-	oneHourFromNow := time.Now().Add(time.Hour * 1).Unix()
+// makeJWT makes a token that expires an hour after now.
+func makeJWT(request JwtRequest, now time.Time) *jwt.Token {
+	oneHourFromNow := now.Add(time.Hour * 1).Unix()
 	claims := jwtClaims{
 		StandardClaims: &jwt.StandardClaims{
 			ExpiresAt: oneHourFromNow,
-			IssuedAt:  time.Now().Unix(),
+			IssuedAt:  now.Unix(),
 			Issuer:    JWTIssuer,
 			Subject:   request.subject,
 		},
@@ -3,6 +3,7 @@ package users
 import (
 	"context"
 	"errors"
+	"gomoney-mock-epl/clock"
 	"gomoney-mock-epl/database"
 
 	"go.mongodb.org/mongo-driver/bson"
@@ -14,6 +15,7 @@ import (
 
 type UsersDB struct {
 	*mongo.Collection
+	Clock clock.Clock
 }
 
 func (db UsersDB) Create(ctx context.Context, user User) (*User, error) {
@@ -3,6 +3,7 @@ package users
 import (
 	"context"
 	"fmt"
+	"gomoney-mock-epl/clock"
 	"time"
 
 	"github.com/dgrijalva/jwt-go"
@@ -31,7 +32,7 @@ func SignUpUser(ctx context.Context, intent SignUpIntent, db UsersDB) (*User, er
 		return nil, err
 	}
 	user := &User{
-		CreatedAt:    time.Now(),
+		CreatedAt:    clock.Now(db.Clock),
 		Email:        intent.Email,
 		FirstName:    intent.FirstName,
 		LastName:     intent.LastName,
@@ -56,5 +57,5 @@ func LoginAsUser(ctx context.Context, db UsersDB, dto LoginDto) (*jwt.Token, err
 	if err != nil {
 		return nil, ErrIncorrectLogin
 	}
-	return makeJWT(JwtRequest{subject: user.ID, IsAdmin: false}), nil
+	return makeJWT(JwtRequest{subject: user.ID, IsAdmin: false}, clock.Now(db.Clock)), nil
 }
@@ -3,9 +3,19 @@ package web
 import "fmt"
 
 type DataDto struct {
-	Type    string      `json:"@type"`
-	Message string      `json:"message"`
-	Data    interface{} `json:"data"`
+	Type       string      `json:"@type"`
+	Message    string      `json:"message"`
+	Data       interface{} `json:"data"`
+	Pagination *Pagination `json:"pagination,omitempty"`
+}
+
+// Pagination describes a page of a list. The cursors are passed back
+// to get the next and previous pages, and are left out when there are
+// no more items in that direction.
+type Pagination struct {
+	Limit int    `json:"limit"`
+	Next  string `json:"next,omitempty"`
+	Prev  string `json:"prev,omitempty"`
 }
 
 func dataResponse(responseType string, message string, data interface{}) DataDto {
@@ -16,6 +26,12 @@ func dataResponse(responseType string, message string, data interface{}) DataDto
 	}
 }
 
+func pagedResponse(responseType string, message string, data interface{}, pagination Pagination) DataDto {
+	response := dataResponse(responseType, message, data)
+	response.Pagination = &pagination
+	return response
+}
+
 type ErrorDto struct {
 	Code    string `json:"code"`
 	Message string `json:"message"`
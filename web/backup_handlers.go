@@ -0,0 +1,62 @@
+package web
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"gomoney-mock-epl/backup"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+)
+
+// restoreBodyLimit is the largest archive that can be restored.
+const restoreBodyLimit = "32M"
+
+// exportBackup sends an archive of the league as a download. Password
+// hashes are only included with ?passwords=true.
+func exportBackup(s backup.Service) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		opts := backup.Options{}
+		if p := c.QueryParam("passwords"); p != "" {
+			var err error
+			if opts.Passwords, err = strconv.ParseBool(p); err != nil {
+				return echo.NewHTTPError(http.StatusBadRequest,
+					errorDto("backup/invalid-query", "passwords must be true or false"))
+			}
+		}
+		archive := bytes.Buffer{}
+		manifest, err := s.Export(c.Request().Context(), &archive, opts)
+		if err != nil {
+			return err
+		}
+		c.Response().Header().Set(echo.HeaderContentDisposition,
+			fmt.Sprintf("attachment; filename=%q", backup.FileName(manifest.CreatedAt)))
+		return c.Blob(http.StatusOK, backup.ContentType, archive.Bytes())
+	}
+}
+
+// restoreBackup restores an archive sent as the request body.
+func restoreBackup(s backup.Service) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		restored, err := s.Restore(c.Request().Context(), c.Request().Body)
+		if errors.Is(err, backup.ErrNotEmpty) {
+			return echo.NewHTTPError(http.StatusConflict,
+				errorDto("backup/not-empty", "Archives can only be restored into a database without a league or users"))
+		}
+		if err != nil {
+			return err
+		}
+		return c.JSON(http.StatusCreated,
+			dataResponse("Restore", "Archive restored successfully", restored))
+	}
+}
+
+func backupRoutesProvider(s backup.Service) RouteProvider {
+	return func(e *echo.Echo) {
+		e.GET("/admin/export", exportBackup(s), jwtMiddleware, onlyAdmins)
+		e.POST("/admin/import", restoreBackup(s), jwtMiddleware, onlyAdmins, middleware.BodyLimit(restoreBodyLimit))
+	}
+}
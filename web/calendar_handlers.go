@@ -0,0 +1,87 @@
+package web
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"gomoney-mock-epl/calendar"
+	"gomoney-mock-epl/fixtures"
+	"gomoney-mock-epl/teams"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// sendCalendar responds with an iCalendar feed of the fixtures. The
+// names of the stadiums fixtures were moved to are looked up.
+func sendCalendar(c echo.Context, db fixtures.DB, name string, list []fixtures.Fixture) error {
+	venues, err := venueNames(c.Request().Context(), db, list)
+	if err != nil {
+		return err
+	}
+	feed := bytes.Buffer{}
+	err = calendar.Calendar{Name: name, Fixtures: list, Venues: venues}.Encode(&feed)
+	if err != nil {
+		return err
+	}
+	return c.Blob(http.StatusOK, calendar.ContentType, feed.Bytes())
+}
+
+// venueNames names the stadiums the fixtures were moved to, by ID.
+func venueNames(ctx context.Context, db fixtures.DB, list []fixtures.Fixture) (map[string]string, error) {
+	venues := map[string]string{}
+	for _, fixture := range list {
+		if fixture.Venue == "" {
+			continue
+		}
+		if _, ok := venues[fixture.Venue]; ok {
+			continue
+		}
+		stadium, err := db.Stadiums.ByID(ctx, fixture.Venue)
+		if err != nil {
+			return nil, err
+		}
+		venues[fixture.Venue] = ""
+		if stadium != nil {
+			venues[fixture.Venue] = stadium.Name
+		}
+	}
+	return venues, nil
+}
+
+func fixturesCalendar(db fixtures.DB) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		list, err := db.List(c.Request().Context(), "")
+		if err != nil {
+			return err
+		}
+		return sendCalendar(c, db, "EPL fixtures", list)
+	}
+}
+
+func teamFixturesCalendar(teamsDB teams.TeamsDB, fixturesDB fixtures.DB) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		team, err := teamsDB.ByID(c.Request().Context(), c.Param("team_id"))
+		if err != nil {
+			return err
+		}
+		if team == nil {
+			return echo.NewHTTPError(http.StatusNotFound, teamNotFound)
+		}
+		list, err := fixturesDB.TeamFixtures(c.Request().Context(), team.ID)
+		if err != nil {
+			return err
+		}
+		return sendCalendar(c, fixturesDB, fmt.Sprintf("%s fixtures", team.Name), list)
+	}
+}
+
+// calendarRoutesProvider serves the iCalendar feeds. They're public:
+// calendar apps poll a feed for as long as it's subscribed to, long
+// after any token would expire, and can't set headers to send one.
+func calendarRoutesProvider(teamsDB teams.TeamsDB, fixturesDB fixtures.DB) RouteProvider {
+	return func(e *echo.Echo) {
+		e.GET("/fixtures.ics", fixturesCalendar(fixturesDB))
+		e.GET("/teams/:team_id/fixtures.ics", teamFixturesCalendar(teamsDB, fixturesDB))
+	}
+}
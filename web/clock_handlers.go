@@ -0,0 +1,69 @@
+package web
+
+import (
+	"gomoney-mock-epl/clock"
+	customErrors "gomoney-mock-epl/errors"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ClockRequest is the DTO we receive from admins when moving the
+// server's clock. Either field can be left out to keep it as it is.
+// A rate of 0 freezes the clock.
+type ClockRequest struct {
+	Now  *time.Time `json:"now"`
+	Rate *float64   `json:"rate"`
+}
+
+func viewClock(clk *clock.Virtual) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		return c.JSON(http.StatusOK,
+			dataResponse("Clock", "The server's clock", clk.State()))
+	}
+}
+
+func setClock(clk *clock.Virtual) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		dto := ClockRequest{}
+		if err := c.Bind(&dto); err != nil {
+			return err
+		}
+		if dto.Rate != nil {
+			if err := clk.SetRate(*dto.Rate); err != nil {
+				return customErrors.ValidationError{
+					Code:    "clock/invalid-rate",
+					Message: "Your request to change the clock failed",
+					Details: []customErrors.ValidationErrorDetails{{
+						Field:   "rate",
+						Message: "The rate must be 0 or more",
+					}},
+				}
+			}
+		}
+		if dto.Now != nil {
+			clk.Set(*dto.Now)
+		}
+		return c.JSON(http.StatusOK,
+			dataResponse("Clock", "Clock changed successfully", clk.State()))
+	}
+}
+
+func resetClock(clk *clock.Virtual) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		clk.Reset()
+		return c.JSON(http.StatusOK,
+			dataResponse("Clock", "Clock reset to the real time", clk.State()))
+	}
+}
+
+// clockRoutesProvider lets admins move the server's clock. It's
+// only registered outside production.
+func clockRoutesProvider(clk *clock.Virtual) RouteProvider {
+	return func(e *echo.Echo) {
+		e.GET("/admin/clock", viewClock(clk), jwtMiddleware, onlyAdmins)
+		e.PUT("/admin/clock", setClock(clk), jwtMiddleware, onlyAdmins)
+		e.DELETE("/admin/clock", resetClock(clk), jwtMiddleware, onlyAdmins)
+	}
+}
@@ -1,11 +1,17 @@
 package web
 
 import (
+	"errors"
 	"fmt"
+	"gomoney-mock-epl/clock"
 	"gomoney-mock-epl/fixtures"
+	"io"
+	"mime"
 	"net/http"
+	"strconv"
 
 	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
@@ -24,43 +30,140 @@ func createFixture(db fixtures.DB) echo.HandlerFunc {
 	}
 }
 
+// importBodyLimit is the most fixtures that can be imported at once,
+// with room for a season of 380 fixtures many times over.
+const importBodyLimit = "1M"
+
+// importParsers read the formats fixtures can be imported from.
+var importParsers = map[string]func(io.Reader) ([]fixtures.ImportRow, error){
+	"text/csv":             fixtures.ParseCSV,
+	"application/x-ndjson": fixtures.ParseJSONLines,
+	"application/jsonl":    fixtures.ParseJSONLines,
+}
+
+// importFixtures creates fixtures from CSV or JSON lines, going by the
+// content type. Nothing is created if any row is invalid, or on dry runs.
+func importFixtures(db fixtures.DB) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		dryRun := false
+		if s := c.QueryParam("dry_run"); s != "" {
+			var err error
+			if dryRun, err = strconv.ParseBool(s); err != nil {
+				return echo.NewHTTPError(http.StatusBadRequest,
+					errorDto("fixtures/invalid-query", "dry_run must be true or false"))
+			}
+		}
+		mediaType, _, _ := mime.ParseMediaType(c.Request().Header.Get(echo.HeaderContentType))
+		parse, ok := importParsers[mediaType]
+		if !ok {
+			return echo.NewHTTPError(http.StatusUnsupportedMediaType,
+				errorDto("fixtures/unsupported-import", "Fixtures are imported from text/csv or application/x-ndjson"))
+		}
+		rows, err := parse(c.Request().Body)
+		if err != nil {
+			return err
+		}
+		result, err := db.Import(c.Request().Context(), rows, dryRun)
+		if err != nil {
+			return err
+		}
+		switch {
+		case !result.Valid:
+			return c.JSON(http.StatusUnprocessableEntity,
+				dataResponse("FixtureImport", "Some rows are invalid, nothing was imported", result))
+		case dryRun:
+			return c.JSON(http.StatusOK,
+				dataResponse("FixtureImport", fmt.Sprintf("%d fixtures can be imported", len(result.Rows)), result))
+		}
+		return c.JSON(http.StatusCreated,
+			dataResponse("FixtureImport", fmt.Sprintf("Imported %d fixtures", result.Created), result))
+	}
+}
+
 func listFixtures(db fixtures.DB) echo.HandlerFunc {
 	return func(c echo.Context) error {
-		fixtures, err := db.List(c.Request().Context(),
-			fixtures.NewFixtureStatus(c.QueryParam("status")))
+		dto := fixtures.ListRequest{}
+		if err := (&echo.DefaultBinder{}).BindQueryParams(c, &dto); err != nil {
+			return err
+		}
+		page, err := db.Page(c.Request().Context(), dto)
 		if err != nil {
 			return err
 		}
 		return c.JSON(http.StatusOK,
-			dataResponse("Fixtures", "Available EPL fixtures", fixtures))
+			pagedResponse("Fixtures", "Available EPL fixtures", page.Fixtures, Pagination{
+				Limit: page.Limit,
+				Next:  page.Next,
+				Prev:  page.Prev,
+			}))
 	}
 }
 
 func deleteFixture(db fixtures.DB) echo.HandlerFunc {
 	return func(c echo.Context) error {
-		fixtureID := c.Param("fixture_id")
-		if err := db.Delete(c.Request().Context(), fixtureID); err != nil {
+		fixtureID, err := primitive.ObjectIDFromHex(c.Param("fixture_id"))
+		if err != nil {
+			return echo.NewHTTPError(http.StatusNotFound, fixtureNotFound)
+		}
+		deleted, err := db.Delete(c.Request().Context(), fixtureID)
+		if err != nil {
 			return err
 		}
+		if !deleted {
+			return echo.NewHTTPError(http.StatusNotFound, fixtureNotFound)
+		}
 		return c.JSON(http.StatusOK, nil)
 	}
 }
 
 var fixtureNotFound = errorDto("NotFound", "That fixture does not exist")
 
+// fixtureConflicts are the errors returned when a change
+// conflicts with the current state of a fixture.
+var fixtureConflicts = map[error]string{
+	fixtures.ErrIllegalTransition:   "fixtures/illegal-status-transition",
+	fixtures.ErrNotInPlay:           "fixtures/not-in-play",
+	fixtures.ErrConcurrentUpdate:    "fixtures/concurrent-update",
+	fixtures.ErrResultFromEvents:    "fixtures/result-from-events",
+	fixtures.ErrLineupLocked:        "fixtures/lineup-locked",
+	fixtures.ErrOfficialsLocked:     "fixtures/officials-locked",
+	fixtures.ErrOfficialUnavailable: "fixtures/official-unavailable",
+}
+
+// fixtureError responds to fixture conflicts with 409 Conflict.
+func fixtureError(err error) error {
+	for target, code := range fixtureConflicts {
+		if errors.Is(err, target) {
+			return echo.NewHTTPError(http.StatusConflict, errorDto(code, err.Error()))
+		}
+	}
+	return err
+}
+
 func editFixture(db fixtures.DB) echo.HandlerFunc {
 	return func(c echo.Context) error {
 		fixtureID, err := primitive.ObjectIDFromHex(c.Param("fixture_id"))
 		if err != nil {
 			return echo.NewHTTPError(http.StatusNotFound, fixtureNotFound)
 		}
-		dto := fixtures.CreateFixtureRequest{}
+		fixture, err := db.ByID(c.Request().Context(), fixtureID)
+		if err != nil {
+			return err
+		}
+		if fixture == nil {
+			return echo.NewHTTPError(http.StatusNotFound, fixtureNotFound)
+		}
+		// The venue is kept if it's left out, and cleared if it's null.
+		dto := fixtures.CreateFixtureRequest{Venue: &fixture.Venue}
 		if err := c.Bind(&dto); err != nil {
 			return err
 		}
-		fixture, err := db.Update(c.Request().Context(), fixtureID, dto)
+		if dto.Venue == nil {
+			dto.Venue = new(string)
+		}
+		fixture, err = db.Update(c.Request().Context(), fixtureID, dto)
 		if err != nil {
-			return err
+			return fixtureError(err)
 		}
 		if fixture == nil {
 			return echo.NewHTTPError(http.StatusNotFound, fixtureNotFound)
@@ -90,13 +193,332 @@ func viewFixture(db fixtures.DB) echo.HandlerFunc {
 	}
 }
 
+func recordFixtureResult(db fixtures.DB) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		fixtureID, err := primitive.ObjectIDFromHex(c.Param("fixture_id"))
+		if err != nil {
+			return echo.NewHTTPError(http.StatusNotFound, fixtureNotFound)
+		}
+		dto := fixtures.RecordResultRequest{}
+		if err := c.Bind(&dto); err != nil {
+			return err
+		}
+		fixture, err := db.RecordResult(c.Request().Context(), fixtureID, dto)
+		if err != nil {
+			return fixtureError(err)
+		}
+		if fixture == nil {
+			return echo.NewHTTPError(http.StatusNotFound, fixtureNotFound)
+		}
+		return c.JSON(http.StatusOK,
+			dataResponse("Fixture", "Fixture result recorded successfully", fixture))
+	}
+}
+
+func clearFixtureResult(db fixtures.DB) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		fixtureID, err := primitive.ObjectIDFromHex(c.Param("fixture_id"))
+		if err != nil {
+			return echo.NewHTTPError(http.StatusNotFound, fixtureNotFound)
+		}
+		fixture, err := db.ClearResult(c.Request().Context(), fixtureID)
+		if err != nil {
+			return fixtureError(err)
+		}
+		if fixture == nil {
+			return echo.NewHTTPError(http.StatusNotFound, fixtureNotFound)
+		}
+		return c.JSON(http.StatusOK,
+			dataResponse("Fixture", "Fixture result removed successfully", fixture))
+	}
+}
+
+func transitionFixture(db fixtures.DB) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		fixtureID, err := primitive.ObjectIDFromHex(c.Param("fixture_id"))
+		if err != nil {
+			return echo.NewHTTPError(http.StatusNotFound, fixtureNotFound)
+		}
+		dto := fixtures.TransitionRequest{}
+		if err := c.Bind(&dto); err != nil {
+			return err
+		}
+		fixture, err := db.Transition(c.Request().Context(), fixtureID, dto)
+		if err != nil {
+			return fixtureError(err)
+		}
+		if fixture == nil {
+			return echo.NewHTTPError(http.StatusNotFound, fixtureNotFound)
+		}
+		return c.JSON(http.StatusOK,
+			dataResponse("Fixture", fmt.Sprintf("Fixture is now %s", fixture.Status), fixture))
+	}
+}
+
+func listFixtureEvents(db fixtures.DB) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		fixtureID, err := primitive.ObjectIDFromHex(c.Param("fixture_id"))
+		if err != nil {
+			return echo.NewHTTPError(http.StatusNotFound, fixtureNotFound)
+		}
+		events, err := db.Events(c.Request().Context(), fixtureID)
+		if err != nil {
+			return err
+		}
+		if events == nil {
+			return echo.NewHTTPError(http.StatusNotFound, fixtureNotFound)
+		}
+		return c.JSON(http.StatusOK,
+			dataResponse("Events", "Fixture timeline", events))
+	}
+}
+
+func addFixtureEvent(db fixtures.DB) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		fixtureID, err := primitive.ObjectIDFromHex(c.Param("fixture_id"))
+		if err != nil {
+			return echo.NewHTTPError(http.StatusNotFound, fixtureNotFound)
+		}
+		dto := fixtures.EventRequest{}
+		if err := c.Bind(&dto); err != nil {
+			return err
+		}
+		event, err := db.AddEvent(c.Request().Context(), fixtureID, dto)
+		if err != nil {
+			return fixtureError(err)
+		}
+		if event == nil {
+			return echo.NewHTTPError(http.StatusNotFound, fixtureNotFound)
+		}
+		return c.JSON(http.StatusCreated,
+			dataResponse("Event", "Event added successfully", event))
+	}
+}
+
+func removeFixtureEvent(db fixtures.DB) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		eventNotFound := echo.NewHTTPError(http.StatusNotFound,
+			errorDto("NotFound", "That event does not exist"))
+		fixtureID, err := primitive.ObjectIDFromHex(c.Param("fixture_id"))
+		if err != nil {
+			return eventNotFound
+		}
+		eventID, err := primitive.ObjectIDFromHex(c.Param("event_id"))
+		if err != nil {
+			return eventNotFound
+		}
+		if err := db.RemoveEvent(c.Request().Context(), fixtureID, eventID); err != nil {
+			if errors.Is(err, fixtures.ErrEventNotFound) {
+				return eventNotFound
+			}
+			return fixtureError(err)
+		}
+		return c.JSON(http.StatusOK, nil)
+	}
+}
+
+func viewFixtureLineups(db fixtures.DB) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		fixtureID, err := primitive.ObjectIDFromHex(c.Param("fixture_id"))
+		if err != nil {
+			return echo.NewHTTPError(http.StatusNotFound, fixtureNotFound)
+		}
+		lineups, err := db.Lineups(c.Request().Context(), fixtureID)
+		if err != nil {
+			return err
+		}
+		if lineups == nil {
+			return echo.NewHTTPError(http.StatusNotFound, fixtureNotFound)
+		}
+		return c.JSON(http.StatusOK,
+			dataResponse("Lineups", "Fixture lineups", lineups))
+	}
+}
+
+func submitFixtureLineup(db fixtures.DB) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		fixtureID, err := primitive.ObjectIDFromHex(c.Param("fixture_id"))
+		if err != nil {
+			return echo.NewHTTPError(http.StatusNotFound, fixtureNotFound)
+		}
+		dto := fixtures.LineupRequest{}
+		if err := c.Bind(&dto); err != nil {
+			return err
+		}
+		lineup, err := db.SubmitLineup(c.Request().Context(), fixtureID, dto)
+		if err != nil {
+			return fixtureError(err)
+		}
+		if lineup == nil {
+			return echo.NewHTTPError(http.StatusNotFound, fixtureNotFound)
+		}
+		return c.JSON(http.StatusOK,
+			dataResponse("Lineup", "Lineup submitted successfully", lineup))
+	}
+}
+
+func viewFixtureOfficials(db fixtures.DB) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		fixtureID, err := primitive.ObjectIDFromHex(c.Param("fixture_id"))
+		if err != nil {
+			return echo.NewHTTPError(http.StatusNotFound, fixtureNotFound)
+		}
+		officials, err := db.Officials(c.Request().Context(), fixtureID)
+		if err != nil {
+			return err
+		}
+		if officials == nil {
+			return echo.NewHTTPError(http.StatusNotFound, fixtureNotFound)
+		}
+		return c.JSON(http.StatusOK,
+			dataResponse("FixtureOfficials", "Fixture officials", officials))
+	}
+}
+
+func assignFixtureOfficials(db fixtures.DB) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		fixtureID, err := primitive.ObjectIDFromHex(c.Param("fixture_id"))
+		if err != nil {
+			return echo.NewHTTPError(http.StatusNotFound, fixtureNotFound)
+		}
+		dto := fixtures.OfficialsRequest{}
+		if err := c.Bind(&dto); err != nil {
+			return err
+		}
+		officials, err := db.AssignOfficials(c.Request().Context(), fixtureID, dto)
+		if err != nil {
+			return fixtureError(err)
+		}
+		if officials == nil {
+			return echo.NewHTTPError(http.StatusNotFound, fixtureNotFound)
+		}
+		return c.JSON(http.StatusOK,
+			dataResponse("FixtureOfficials", "Officials assigned successfully", officials))
+	}
+}
+
+func listFixturePerformances(db fixtures.DB) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		fixtureID, err := primitive.ObjectIDFromHex(c.Param("fixture_id"))
+		if err != nil {
+			return echo.NewHTTPError(http.StatusNotFound, fixtureNotFound)
+		}
+		performances, err := db.Performances(c.Request().Context(), fixtureID)
+		if err != nil {
+			return err
+		}
+		if performances == nil {
+			return echo.NewHTTPError(http.StatusNotFound, fixtureNotFound)
+		}
+		return c.JSON(http.StatusOK,
+			dataResponse("Performances", "Player performances", performances))
+	}
+}
+
+func submitFixturePerformance(db fixtures.DB) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		fixtureID, err := primitive.ObjectIDFromHex(c.Param("fixture_id"))
+		if err != nil {
+			return echo.NewHTTPError(http.StatusNotFound, fixtureNotFound)
+		}
+		dto := fixtures.PerformanceRequest{}
+		if err := c.Bind(&dto); err != nil {
+			return err
+		}
+		performance, err := db.SubmitPerformance(c.Request().Context(), fixtureID, dto)
+		if err != nil {
+			return fixtureError(err)
+		}
+		if performance == nil {
+			return echo.NewHTTPError(http.StatusNotFound, fixtureNotFound)
+		}
+		return c.JSON(http.StatusOK,
+			dataResponse("Performance", "Performance recorded successfully", performance))
+	}
+}
+
+const (
+	defaultNearRadiusKM = 25
+	maxNearRadiusKM     = 500
+	defaultNearLimit    = 20
+	maxNearLimit        = 100
+)
+
+// queryFloat reads a number from the query, refusing it with
+// a bad request if it's outside the bounds.
+func queryFloat(c echo.Context, name string, fallback, min, max float64, code string) (float64, error) {
+	value := c.QueryParam(name)
+	if value == "" {
+		return fallback, nil
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil || f < min || f > max {
+		return 0, echo.NewHTTPError(http.StatusBadRequest,
+			errorDto(code, fmt.Sprintf("%s must be a number from %g to %g", name, min, max)))
+	}
+	return f, nil
+}
+
+func listFixturesNear(db fixtures.DB) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		const code = "fixtures/invalid-location"
+		if c.QueryParam("lat") == "" || c.QueryParam("lng") == "" {
+			return echo.NewHTTPError(http.StatusBadRequest,
+				errorDto(code, "lat and lng are required"))
+		}
+		q := fixtures.NearQuery{}
+		var err error
+		if q.Latitude, err = queryFloat(c, "lat", 0, -90, 90, code); err != nil {
+			return err
+		}
+		if q.Longitude, err = queryFloat(c, "lng", 0, -180, 180, code); err != nil {
+			return err
+		}
+		if q.RadiusKM, err = queryFloat(c, "radius", defaultNearRadiusKM, 0, maxNearRadiusKM, code); err != nil {
+			return err
+		}
+		q.Limit = defaultNearLimit
+		if l := c.QueryParam("limit"); l != "" {
+			q.Limit, err = strconv.Atoi(l)
+			if err != nil || q.Limit < 1 || q.Limit > maxNearLimit {
+				return echo.NewHTTPError(http.StatusBadRequest,
+					errorDto("fixtures/invalid-query",
+						fmt.Sprintf("The number of fixtures must be from 1 to %d", maxNearLimit)))
+			}
+		}
+		if q.From, err = queryDate(c, "from", clock.Now(db.Clock), "fixtures/invalid-query"); err != nil {
+			return err
+		}
+		nearby, err := db.Near(c.Request().Context(), q)
+		if err != nil {
+			return err
+		}
+		return c.JSON(http.StatusOK,
+			dataResponse("NearbyFixtures", "Upcoming fixtures nearby", nearby))
+	}
+}
+
 func fixturesRoutesProvider(db fixtures.DB) RouteProvider {
 	return func(e *echo.Echo) {
 		fixturesRoutes := e.Group("/fixtures", jwtMiddleware)
 		fixturesRoutes.POST("/", createFixture(db), onlyAdmins)
+		fixturesRoutes.POST("/import", importFixtures(db), onlyAdmins, middleware.BodyLimit(importBodyLimit))
 		fixturesRoutes.GET("/", listFixtures(db))
+		fixturesRoutes.GET("/near", listFixturesNear(db))
 		fixturesRoutes.DELETE("/:fixture_id", deleteFixture(db), onlyAdmins)
 		fixturesRoutes.GET("/:fixture_id", viewFixture(db))
 		fixturesRoutes.PATCH("/:fixture_id", editFixture(db), onlyAdmins)
+		fixturesRoutes.PUT("/:fixture_id/result", recordFixtureResult(db), onlyAdmins)
+		fixturesRoutes.DELETE("/:fixture_id/result", clearFixtureResult(db), onlyAdmins)
+		fixturesRoutes.POST("/:fixture_id/status", transitionFixture(db), onlyAdmins)
+		fixturesRoutes.GET("/:fixture_id/events", listFixtureEvents(db))
+		fixturesRoutes.POST("/:fixture_id/events", addFixtureEvent(db), onlyAdmins)
+		fixturesRoutes.DELETE("/:fixture_id/events/:event_id", removeFixtureEvent(db), onlyAdmins)
+		fixturesRoutes.GET("/:fixture_id/lineups", viewFixtureLineups(db))
+		fixturesRoutes.PUT("/:fixture_id/lineups", submitFixtureLineup(db), onlyAdmins)
+		fixturesRoutes.GET("/:fixture_id/officials", viewFixtureOfficials(db))
+		fixturesRoutes.PUT("/:fixture_id/officials", assignFixtureOfficials(db), onlyAdmins)
+		fixturesRoutes.GET("/:fixture_id/performances", listFixturePerformances(db))
+		fixturesRoutes.PUT("/:fixture_id/performances", submitFixturePerformance(db), onlyAdmins)
 	}
 }
@@ -0,0 +1,111 @@
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"gomoney-mock-epl/fixtures"
+	"gomoney-mock-epl/live"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// keepAliveInterval is how often a comment is sent on idle streams, so
+// that proxies don't close them.
+const keepAliveInterval = 15 * time.Second
+
+// lastEventID is the ID of the last message a reconnecting client
+// received. Browsers send it in the Last-Event-ID header; the query
+// parameter is for clients that can't set headers.
+func lastEventID(c echo.Context) uint64 {
+	value := c.Request().Header.Get("Last-Event-ID")
+	if value == "" {
+		value = c.QueryParam("last_event_id")
+	}
+	id, _ := strconv.ParseUint(value, 10, 64)
+	return id
+}
+
+func writeServerSentEvent(w *echo.Response, message live.Message) error {
+	data, err := json.Marshal(dataResponse("FixtureChange", "", message.Change))
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", message.ID, message.Change.Type, data)
+	w.Flush()
+	return err
+}
+
+// streamChanges sends the changes published to a topic as Server-Sent
+// Events until the client goes away. Messages missed since the last
+// event the client received are sent first.
+func streamChanges(c echo.Context, hub *live.Hub, topic string) error {
+	subscription, missed := hub.Subscribe(lastEventID(c), topic)
+	defer subscription.Close()
+
+	w := c.Response()
+	w.Header().Set(echo.HeaderContentType, "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, "retry: 3000\n\n")
+	w.Flush()
+	for _, message := range missed {
+		if err := writeServerSentEvent(w, message); err != nil {
+			return nil
+		}
+	}
+
+	keepAlive := time.NewTicker(keepAliveInterval)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case <-c.Request().Context().Done():
+			return nil
+		case <-keepAlive.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+			w.Flush()
+		case message, ok := <-subscription.Messages():
+			if !ok {
+				// The client fell behind. It will reconnect and catch up.
+				return nil
+			}
+			if err := writeServerSentEvent(w, message); err != nil {
+				return nil
+			}
+		}
+	}
+}
+
+func streamFixture(db fixtures.DB, hub *live.Hub) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		fixtureID, err := primitive.ObjectIDFromHex(c.Param("fixture_id"))
+		if err != nil {
+			return echo.NewHTTPError(http.StatusNotFound, fixtureNotFound)
+		}
+		fixture, err := db.ByID(c.Request().Context(), fixtureID)
+		if err != nil {
+			return err
+		}
+		if fixture == nil {
+			return echo.NewHTTPError(http.StatusNotFound, fixtureNotFound)
+		}
+		return streamChanges(c, hub, live.FixtureTopic(fixtureID.Hex()))
+	}
+}
+
+func streamAllFixtures(hub *live.Hub) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		return streamChanges(c, hub, live.AllFixtures)
+	}
+}
+
+func liveRoutesProvider(db fixtures.DB, hub *live.Hub) RouteProvider {
+	return func(e *echo.Echo) {
+		e.GET("/fixtures/:fixture_id/stream", streamFixture(db, hub), tokenFromQuery, jwtMiddleware)
+		e.GET("/live", streamAllFixtures(hub), tokenFromQuery, jwtMiddleware)
+	}
+}
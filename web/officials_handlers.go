@@ -0,0 +1,135 @@
+package web
+
+import (
+	"fmt"
+	"gomoney-mock-epl/fixtures"
+	"gomoney-mock-epl/officials"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+var officialNotFound = errorDto("NotFound", "That official does not exist")
+
+func createOfficial(db officials.OfficialsDB) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		dto := officials.OfficialRequest{}
+		if err := c.Bind(&dto); err != nil {
+			return err
+		}
+		official, err := db.Create(c.Request().Context(), dto)
+		if err != nil {
+			return err
+		}
+		return c.JSON(http.StatusCreated,
+			dataResponse("Official", "Official created successfully", official))
+	}
+}
+
+func listOfficials(db officials.OfficialsDB) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		role := officials.Role(c.QueryParam("role"))
+		switch role {
+		case "", officials.Referee, officials.Assistant, officials.VAR:
+		default:
+			return echo.NewHTTPError(http.StatusBadRequest,
+				errorDto("officials/invalid-role", "The role must be referee, assistant or var"))
+		}
+		officials, err := db.List(c.Request().Context(), role)
+		if err != nil {
+			return err
+		}
+		return c.JSON(http.StatusOK,
+			dataResponse("Officials", "EPL match officials", officials))
+	}
+}
+
+func viewOfficial(db officials.OfficialsDB) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		official, err := db.ByID(c.Request().Context(), c.Param("official_id"))
+		if err != nil {
+			return err
+		}
+		if official == nil {
+			return echo.NewHTTPError(http.StatusNotFound, officialNotFound)
+		}
+		return c.JSON(http.StatusOK,
+			dataResponse("Official", fmt.Sprintf("Official: %q", official.Name), official))
+	}
+}
+
+func editOfficial(db officials.OfficialsDB) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		officialID := c.Param("official_id")
+		official, err := db.ByID(c.Request().Context(), officialID)
+		if err != nil {
+			return err
+		}
+		if official == nil {
+			return echo.NewHTTPError(http.StatusNotFound, officialNotFound)
+		}
+		dto := (&officials.OfficialRequest{}).FromOfficial(*official)
+		if err := c.Bind(dto); err != nil {
+			return err
+		}
+		official, err = db.Update(c.Request().Context(), officialID, *dto)
+		if err != nil {
+			return err
+		}
+		if official == nil {
+			return echo.NewHTTPError(http.StatusNotFound, officialNotFound)
+		}
+		return c.JSON(http.StatusOK,
+			dataResponse("Official", "Official updated successfully", official))
+	}
+}
+
+// deleteOfficial only removes officials that are not
+// assigned to any fixture, so their history is kept.
+func deleteOfficial(db officials.OfficialsDB, fixturesDB fixtures.DB) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		officialID := c.Param("official_id")
+		inUse, err := fixturesDB.OfficialInUse(c.Request().Context(), officialID)
+		if err != nil {
+			return err
+		}
+		if inUse {
+			return echo.NewHTTPError(http.StatusConflict,
+				errorDto("officials/in-use", "The official is assigned to fixtures"))
+		}
+		if err := db.Delete(c.Request().Context(), officialID); err != nil {
+			return err
+		}
+		return c.JSON(http.StatusOK, nil)
+	}
+}
+
+func listOfficialFixtures(db officials.OfficialsDB, fixturesDB fixtures.DB) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		official, err := db.ByID(c.Request().Context(), c.Param("official_id"))
+		if err != nil {
+			return err
+		}
+		if official == nil {
+			return echo.NewHTTPError(http.StatusNotFound, officialNotFound)
+		}
+		fixtures, err := fixturesDB.OfficialFixtures(c.Request().Context(), official.ID)
+		if err != nil {
+			return err
+		}
+		return c.JSON(http.StatusOK,
+			dataResponse("OfficiatedFixtures", fmt.Sprintf("Fixtures worked by %s", official.Name), fixtures))
+	}
+}
+
+func officialRoutesProvider(db officials.OfficialsDB, fixturesDB fixtures.DB) RouteProvider {
+	return func(e *echo.Echo) {
+		officials := e.Group("/officials", jwtMiddleware)
+		officials.POST("/", createOfficial(db), onlyAdmins)
+		officials.GET("/", listOfficials(db))
+		officials.DELETE("/:official_id", deleteOfficial(db, fixturesDB), onlyAdmins)
+		officials.GET("/:official_id", viewOfficial(db))
+		officials.PATCH("/:official_id", editOfficial(db), onlyAdmins)
+		officials.GET("/:official_id/fixtures", listOfficialFixtures(db, fixturesDB))
+	}
+}
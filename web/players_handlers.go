@@ -0,0 +1,125 @@
+package web
+
+import (
+	"fmt"
+	"gomoney-mock-epl/database"
+	"gomoney-mock-epl/players"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+var playerNotFound = errorDto("NotFound", "That player does not exist")
+var shirtNumberTaken = errorDto("players/shirt-number-taken",
+	"Another player in the squad already wears this shirt number")
+
+func createPlayer(db players.PlayersDB) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		dto := players.PlayerRequest{}
+		if err := c.Bind(&dto); err != nil {
+			return err
+		}
+		player, err := db.Create(c.Request().Context(), dto)
+		if err != nil {
+			if database.IsDuplicateKeyError(err) {
+				return echo.NewHTTPError(http.StatusConflict, shirtNumberTaken)
+			}
+			return err
+		}
+		return c.JSON(http.StatusCreated,
+			dataResponse("Player", "Player created successfully", player))
+	}
+}
+
+func listPlayers(db players.PlayersDB) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		players, err := db.List(c.Request().Context())
+		if err != nil {
+			return err
+		}
+		return c.JSON(http.StatusOK,
+			dataResponse("Players", "EPL players", players))
+	}
+}
+
+func viewPlayer(db players.PlayersDB) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		player, err := db.ByID(c.Request().Context(), c.Param("player_id"))
+		if err != nil {
+			return err
+		}
+		if player == nil {
+			return echo.NewHTTPError(http.StatusNotFound, playerNotFound)
+		}
+		return c.JSON(http.StatusOK,
+			dataResponse("Player", fmt.Sprintf("Player: %q", player.Name), player))
+	}
+}
+
+func editPlayer(db players.PlayersDB) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		playerID := c.Param("player_id")
+		player, err := db.ByID(c.Request().Context(), playerID)
+		if err != nil {
+			return err
+		}
+		if player == nil {
+			return echo.NewHTTPError(http.StatusNotFound, playerNotFound)
+		}
+		dto := (&players.PlayerRequest{}).FromPlayer(*player)
+		if err := c.Bind(dto); err != nil {
+			return err
+		}
+		player, err = db.Update(c.Request().Context(), playerID, *dto)
+		if err != nil {
+			if database.IsDuplicateKeyError(err) {
+				return echo.NewHTTPError(http.StatusConflict, shirtNumberTaken)
+			}
+			return err
+		}
+		if player == nil {
+			return echo.NewHTTPError(http.StatusNotFound, playerNotFound)
+		}
+		return c.JSON(http.StatusOK,
+			dataResponse("Player", "Player updated successfully", player))
+	}
+}
+
+func deletePlayer(db players.PlayersDB) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if err := db.Delete(c.Request().Context(), c.Param("player_id")); err != nil {
+			return err
+		}
+		return c.JSON(http.StatusOK, nil)
+	}
+}
+
+func listSquad(db players.PlayersDB) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		team, err := db.TeamsDB.ByID(c.Request().Context(), c.Param("team_id"))
+		if err != nil {
+			return err
+		}
+		if team == nil {
+			return echo.NewHTTPError(http.StatusNotFound, teamNotFound)
+		}
+		squad, err := db.Squad(c.Request().Context(), team.ID, c.QueryParam("season"))
+		if err != nil {
+			return err
+		}
+		return c.JSON(http.StatusOK,
+			dataResponse("Players", fmt.Sprintf("%s squad", team.Name), squad))
+	}
+}
+
+func playerRoutesProvider(db players.PlayersDB) RouteProvider {
+	return func(e *echo.Echo) {
+		players := e.Group("/players", jwtMiddleware)
+		players.POST("/", createPlayer(db), onlyAdmins)
+		players.GET("/", listPlayers(db))
+		players.DELETE("/:player_id", deletePlayer(db), onlyAdmins)
+		players.GET("/:player_id", viewPlayer(db))
+		players.PATCH("/:player_id", editPlayer(db), onlyAdmins)
+		e.GET("/teams/:team_id/squad", listSquad(db), jwtMiddleware)
+	}
+}
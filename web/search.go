@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"gomoney-mock-epl/fixtures"
+	"gomoney-mock-epl/players"
 	"gomoney-mock-epl/teams"
 	"net/http"
 
@@ -19,6 +20,7 @@ type SearchResults struct {
 	Query    string             `json:"query"`
 	Fixtures []fixtures.Fixture `json:"fixures"`
 	Teams    []teams.Team       `json:"teams"`
+	Players  []players.Player   `json:"players"`
 }
 
 func searchTeams(ctx context.Context, db teams.TeamsDB, query string) ([]teams.Team, error) {
@@ -26,6 +28,7 @@ func searchTeams(ctx context.Context, db teams.TeamsDB, query string) ([]teams.T
 		{Key: "$text", Value: bson.D{
 			{Key: "$search", Value: query},
 		}},
+		{Key: "archived_at", Value: bson.D{{Key: "$exists", Value: false}}},
 	}
 	score := bson.D{
 		{Key: "score", Value: bson.D{{Key: "$meta", Value: "textScore"}}},
@@ -45,9 +48,9 @@ func searchTeams(ctx context.Context, db teams.TeamsDB, query string) ([]teams.T
 	return teams, nil
 }
 
-// runSearch executes a text search on both the teams
-// database and the fixtures database.
-func runSearch(ctx context.Context, teamsDB teams.TeamsDB, fixturesDB fixtures.DB, query string) (*SearchResults, error) {
+// runSearch executes a text search on the teams,
+// fixtures and players databases.
+func runSearch(ctx context.Context, teamsDB teams.TeamsDB, fixturesDB fixtures.DB, playersDB players.PlayersDB, query string) (*SearchResults, error) {
 	teams, err := searchTeams(ctx, teamsDB, query)
 	if err != nil {
 		return nil, err
@@ -56,18 +59,23 @@ func runSearch(ctx context.Context, teamsDB teams.TeamsDB, fixturesDB fixtures.D
 	if err != nil {
 		return nil, err
 	}
+	players, err := playersDB.Search(ctx, query)
+	if err != nil {
+		return nil, err
+	}
 	return &SearchResults{
 		Query:    query,
 		Fixtures: fixtures,
 		Teams:    teams,
+		Players:  players,
 	}, nil
 }
 
-func searchRoutesProvider(teamsDB teams.TeamsDB, fixturesDB fixtures.DB) RouteProvider {
+func searchRoutesProvider(teamsDB teams.TeamsDB, fixturesDB fixtures.DB, playersDB players.PlayersDB) RouteProvider {
 	return func(e *echo.Echo) {
 		e.GET("/search", func(c echo.Context) error {
 			query := c.QueryParam("q")
-			results, err := runSearch(c.Request().Context(), teamsDB, fixturesDB, query)
+			results, err := runSearch(c.Request().Context(), teamsDB, fixturesDB, playersDB, query)
 			if err != nil {
 				return err
 			}
@@ -0,0 +1,166 @@
+package web
+
+import (
+	"errors"
+	"fmt"
+	"gomoney-mock-epl/database"
+	"gomoney-mock-epl/fixtures"
+	"gomoney-mock-epl/schedule"
+	"gomoney-mock-epl/seasons"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+var seasonNotFound = errorDto("NotFound", "That season does not exist")
+var seasonExists = errorDto("seasons/already-exists", "A season with this name already exists")
+
+func createSeason(db seasons.SeasonsDB) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		dto := seasons.SeasonRequest{}
+		if err := c.Bind(&dto); err != nil {
+			return err
+		}
+		season, err := db.Create(c.Request().Context(), dto)
+		if err != nil {
+			if database.IsDuplicateKeyError(err) {
+				return echo.NewHTTPError(http.StatusConflict, seasonExists)
+			}
+			return err
+		}
+		return c.JSON(http.StatusCreated,
+			dataResponse("Season", "Season created successfully", season))
+	}
+}
+
+func listSeasons(db seasons.SeasonsDB) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		seasons, err := db.List(c.Request().Context())
+		if err != nil {
+			return err
+		}
+		return c.JSON(http.StatusOK,
+			dataResponse("Seasons", "EPL seasons", seasons))
+	}
+}
+
+func viewSeason(db seasons.SeasonsDB) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		season, err := db.ByID(c.Request().Context(), c.Param("season_id"))
+		if err != nil {
+			return err
+		}
+		if season == nil {
+			return echo.NewHTTPError(http.StatusNotFound, seasonNotFound)
+		}
+		return c.JSON(http.StatusOK,
+			dataResponse("Season", fmt.Sprintf("Season: %q", season.Name), season))
+	}
+}
+
+func editSeason(db seasons.SeasonsDB) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		seasonID := c.Param("season_id")
+		season, err := db.ByID(c.Request().Context(), seasonID)
+		if err != nil {
+			return err
+		}
+		if season == nil {
+			return echo.NewHTTPError(http.StatusNotFound, seasonNotFound)
+		}
+		dto := (&seasons.SeasonRequest{}).FromSeason(*season)
+		if err := c.Bind(dto); err != nil {
+			return err
+		}
+		season, err = db.Update(c.Request().Context(), seasonID, *dto)
+		if err != nil {
+			if database.IsDuplicateKeyError(err) {
+				return echo.NewHTTPError(http.StatusConflict, seasonExists)
+			}
+			return err
+		}
+		if season == nil {
+			return echo.NewHTTPError(http.StatusNotFound, seasonNotFound)
+		}
+		return c.JSON(http.StatusOK,
+			dataResponse("Season", "Season updated successfully", season))
+	}
+}
+
+func deleteSeason(db seasons.SeasonsDB) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if err := db.Delete(c.Request().Context(), c.Param("season_id")); err != nil {
+			if errors.Is(err, seasons.ErrInUse) {
+				return echo.NewHTTPError(http.StatusConflict,
+					errorDto("seasons/in-use", "Fixtures are placed in the season. Delete them or move them out of it first"))
+			}
+			return err
+		}
+		return c.JSON(http.StatusOK, nil)
+	}
+}
+
+func listSeasonFixtures(seasonsDB seasons.SeasonsDB, fixturesDB fixtures.DB) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		season, err := seasonsDB.ByID(c.Request().Context(), c.Param("season_id"))
+		if err != nil {
+			return err
+		}
+		if season == nil {
+			return echo.NewHTTPError(http.StatusNotFound, seasonNotFound)
+		}
+		matchweek := 0
+		if mw := c.QueryParam("matchweek"); mw != "" {
+			matchweek, err = strconv.Atoi(mw)
+			if err != nil || matchweek < 1 {
+				return echo.NewHTTPError(http.StatusBadRequest,
+					errorDto("seasons/invalid-matchweek", "The matchweek must be a positive number"))
+			}
+		}
+		fixtures, err := fixturesDB.BySeason(c.Request().Context(), season.ID, matchweek)
+		if err != nil {
+			return err
+		}
+		message := fmt.Sprintf("%s fixtures", season.Name)
+		if matchweek > 0 {
+			message = fmt.Sprintf("%s matchweek %d fixtures", season.Name, matchweek)
+		}
+		return c.JSON(http.StatusOK, dataResponse("Fixtures", message, fixtures))
+	}
+}
+
+func scheduleSeason(scheduler schedule.Scheduler) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		opts := schedule.Options{}
+		if err := c.Bind(&opts); err != nil {
+			return err
+		}
+		fixtures, err := scheduler.Schedule(c.Request().Context(), c.Param("season_id"), opts)
+		if err != nil {
+			if errors.Is(err, schedule.ErrAlreadyScheduled) {
+				return echo.NewHTTPError(http.StatusConflict,
+					errorDto("schedule/already-scheduled", err.Error()))
+			}
+			return err
+		}
+		if fixtures == nil {
+			return echo.NewHTTPError(http.StatusNotFound, seasonNotFound)
+		}
+		return c.JSON(http.StatusCreated,
+			dataResponse("Fixtures", fmt.Sprintf("%d fixtures scheduled", len(fixtures)), fixtures))
+	}
+}
+
+func seasonRoutesProvider(seasonsDB seasons.SeasonsDB, fixturesDB fixtures.DB) RouteProvider {
+	return func(e *echo.Echo) {
+		seasons := e.Group("/seasons", jwtMiddleware)
+		seasons.POST("/", createSeason(seasonsDB), onlyAdmins)
+		seasons.GET("/", listSeasons(seasonsDB))
+		seasons.DELETE("/:season_id", deleteSeason(seasonsDB), onlyAdmins)
+		seasons.GET("/:season_id", viewSeason(seasonsDB))
+		seasons.PATCH("/:season_id", editSeason(seasonsDB), onlyAdmins)
+		seasons.GET("/:season_id/fixtures", listSeasonFixtures(seasonsDB, fixturesDB))
+		seasons.POST("/:season_id/schedule", scheduleSeason(schedule.Scheduler{DB: fixturesDB}), onlyAdmins)
+	}
+}
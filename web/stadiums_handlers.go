@@ -0,0 +1,109 @@
+package web
+
+import (
+	"fmt"
+	"gomoney-mock-epl/fixtures"
+	"gomoney-mock-epl/stadiums"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+var stadiumNotFound = errorDto("NotFound", "That stadium does not exist")
+
+func createStadium(db stadiums.StadiumsDB) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		dto := stadiums.StadiumRequest{}
+		if err := c.Bind(&dto); err != nil {
+			return err
+		}
+		stadium, err := db.Create(c.Request().Context(), dto)
+		if err != nil {
+			return err
+		}
+		return c.JSON(http.StatusCreated,
+			dataResponse("Stadium", "Stadium created successfully", stadium))
+	}
+}
+
+func listStadiums(db stadiums.StadiumsDB) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		stadiums, err := db.List(c.Request().Context())
+		if err != nil {
+			return err
+		}
+		return c.JSON(http.StatusOK,
+			dataResponse("Stadiums", "EPL stadiums", stadiums))
+	}
+}
+
+func viewStadium(db stadiums.StadiumsDB) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		stadium, err := db.ByID(c.Request().Context(), c.Param("stadium_id"))
+		if err != nil {
+			return err
+		}
+		if stadium == nil {
+			return echo.NewHTTPError(http.StatusNotFound, stadiumNotFound)
+		}
+		return c.JSON(http.StatusOK,
+			dataResponse("Stadium", fmt.Sprintf("Stadium: %q", stadium.Name), stadium))
+	}
+}
+
+func editStadium(db stadiums.StadiumsDB) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		stadiumID := c.Param("stadium_id")
+		stadium, err := db.ByID(c.Request().Context(), stadiumID)
+		if err != nil {
+			return err
+		}
+		if stadium == nil {
+			return echo.NewHTTPError(http.StatusNotFound, stadiumNotFound)
+		}
+		dto := (&stadiums.StadiumRequest{}).FromStadium(*stadium)
+		if err := c.Bind(dto); err != nil {
+			return err
+		}
+		stadium, err = db.Update(c.Request().Context(), stadiumID, *dto)
+		if err != nil {
+			return err
+		}
+		if stadium == nil {
+			return echo.NewHTTPError(http.StatusNotFound, stadiumNotFound)
+		}
+		return c.JSON(http.StatusOK,
+			dataResponse("Stadium", "Stadium updated successfully", stadium))
+	}
+}
+
+// deleteStadium only removes stadiums that no team
+// or fixture is played at.
+func deleteStadium(db stadiums.StadiumsDB, fixturesDB fixtures.DB) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		stadiumID := c.Param("stadium_id")
+		inUse, err := fixturesDB.StadiumInUse(c.Request().Context(), stadiumID)
+		if err != nil {
+			return err
+		}
+		if inUse {
+			return echo.NewHTTPError(http.StatusConflict,
+				errorDto("stadiums/in-use", "Teams or fixtures are still played at this stadium"))
+		}
+		if err := db.Delete(c.Request().Context(), stadiumID); err != nil {
+			return err
+		}
+		return c.JSON(http.StatusOK, nil)
+	}
+}
+
+func stadiumRoutesProvider(db stadiums.StadiumsDB, fixturesDB fixtures.DB) RouteProvider {
+	return func(e *echo.Echo) {
+		stadiums := e.Group("/stadiums", jwtMiddleware)
+		stadiums.POST("/", createStadium(db), onlyAdmins)
+		stadiums.GET("/", listStadiums(db))
+		stadiums.DELETE("/:stadium_id", deleteStadium(db, fixturesDB), onlyAdmins)
+		stadiums.GET("/:stadium_id", viewStadium(db))
+		stadiums.PATCH("/:stadium_id", editStadium(db), onlyAdmins)
+	}
+}
@@ -0,0 +1,28 @@
+package web
+
+import (
+	"gomoney-mock-epl/standings"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+func viewStandings(s standings.Service) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		table, err := s.Table(c.Request().Context(), c.QueryParam("season"))
+		if err != nil {
+			return err
+		}
+		if table == nil {
+			return echo.NewHTTPError(http.StatusNotFound, seasonNotFound)
+		}
+		return c.JSON(http.StatusOK,
+			dataResponse("Standings", "EPL standings", table))
+	}
+}
+
+func standingsRoutesProvider(s standings.Service) RouteProvider {
+	return func(e *echo.Echo) {
+		e.GET("/standings", viewStandings(s), jwtMiddleware)
+	}
+}
@@ -0,0 +1,132 @@
+package web
+
+import (
+	"fmt"
+	"gomoney-mock-epl/stats"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+func viewHeadToHead(s stats.Service) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		h2h, err := s.HeadToHead(c.Request().Context(), c.Param("team_id"), c.Param("other_team_id"))
+		if err != nil {
+			return err
+		}
+		if h2h == nil {
+			return echo.NewHTTPError(http.StatusNotFound, teamNotFound)
+		}
+		return c.JSON(http.StatusOK,
+			dataResponse("HeadToHead", fmt.Sprintf("%s v %s",
+				h2h.Team.Team.ShortName, h2h.OtherTeam.Team.ShortName), h2h))
+	}
+}
+
+const (
+	defaultFormLength = 5
+	maxFormLength     = 38
+)
+
+func viewTeamForm(s stats.Service) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		last := defaultFormLength
+		if l := c.QueryParam("last"); l != "" {
+			var err error
+			last, err = strconv.Atoi(l)
+			if err != nil || last < 1 || last > maxFormLength {
+				return echo.NewHTTPError(http.StatusBadRequest,
+					errorDto("stats/invalid-form-length",
+						fmt.Sprintf("The number of results must be from 1 to %d", maxFormLength)))
+			}
+		}
+		form, err := s.Form(c.Request().Context(), c.Param("team_id"))
+		if err != nil {
+			return err
+		}
+		if form == nil {
+			return echo.NewHTTPError(http.StatusNotFound, teamNotFound)
+		}
+		return c.JSON(http.StatusOK,
+			dataResponse("Form", fmt.Sprintf("%s form", form.Team.Name), form.Last(last)))
+	}
+}
+
+// statsPeriod reads the period statistics are taken
+// from, which is open unless the query bounds it.
+func statsPeriod(c echo.Context) (stats.Period, error) {
+	from, err := queryDate(c, "from", time.Time{}, "stats/invalid-date")
+	if err != nil {
+		return stats.Period{}, err
+	}
+	to, err := queryDate(c, "to", time.Time{}, "stats/invalid-date")
+	if err != nil {
+		return stats.Period{}, err
+	}
+	if !from.IsZero() && !to.IsZero() && !to.After(from) {
+		return stats.Period{}, echo.NewHTTPError(http.StatusBadRequest,
+			errorDto("stats/invalid-date", "The to date must be after the from date"))
+	}
+	return stats.Period{From: from, To: to}, nil
+}
+
+func viewPlayerStats(s stats.Service) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		period, err := statsPeriod(c)
+		if err != nil {
+			return err
+		}
+		totals, err := s.PlayerStats(c.Request().Context(), c.Param("player_id"), period)
+		if err != nil {
+			return err
+		}
+		if totals == nil {
+			return echo.NewHTTPError(http.StatusNotFound, playerNotFound)
+		}
+		return c.JSON(http.StatusOK,
+			dataResponse("PlayerStats", fmt.Sprintf("%s stats", totals.Player.Name), totals))
+	}
+}
+
+const (
+	defaultLeadersLimit = 20
+	maxLeadersLimit     = 100
+)
+
+func viewLeaderboard(s stats.Service, board stats.Leaderboard, message string) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		limit := defaultLeadersLimit
+		if l := c.QueryParam("limit"); l != "" {
+			var err error
+			limit, err = strconv.Atoi(l)
+			if err != nil || limit < 1 || limit > maxLeadersLimit {
+				return echo.NewHTTPError(http.StatusBadRequest,
+					errorDto("stats/invalid-limit",
+						fmt.Sprintf("The number of players must be from 1 to %d", maxLeadersLimit)))
+			}
+		}
+		period, err := statsPeriod(c)
+		if err != nil {
+			return err
+		}
+		leaders, err := s.Leaders(c.Request().Context(), board, period, limit)
+		if err != nil {
+			return err
+		}
+		return c.JSON(http.StatusOK, dataResponse("Leaderboard", message, leaders))
+	}
+}
+
+func statsRoutesProvider(s stats.Service) RouteProvider {
+	return func(e *echo.Echo) {
+		e.GET("/teams/:team_id/head-to-head/:other_team_id", viewHeadToHead(s), jwtMiddleware)
+		e.GET("/teams/:team_id/form", viewTeamForm(s), jwtMiddleware)
+		e.GET("/players/:player_id/stats", viewPlayerStats(s), jwtMiddleware)
+		statsRoutes := e.Group("/stats", jwtMiddleware)
+		statsRoutes.GET("/top-scorers", viewLeaderboard(s, stats.TopScorers, "Top scorers"))
+		statsRoutes.GET("/assists", viewLeaderboard(s, stats.Assists, "Most assists"))
+		statsRoutes.GET("/cards", viewLeaderboard(s, stats.Cards, "Most cards"))
+	}
+}
@@ -0,0 +1,142 @@
+package web
+
+import (
+	"fmt"
+	"gomoney-mock-epl/clock"
+	"gomoney-mock-epl/live"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/labstack/echo/v4"
+	"golang.org/x/net/websocket"
+)
+
+const (
+	subscribeAction   = "subscribe"
+	unsubscribeAction = "unsubscribe"
+)
+
+// subscriptionRequest is the message clients send over
+// the WebSocket to change the topics they follow.
+type subscriptionRequest struct {
+	Action string   `json:"action"`
+	Topics []string `json:"topics"`
+}
+
+type subscriptionDto struct {
+	Topics []string `json:"topics"`
+}
+
+func subscriptionError(code, message string) DataDto {
+	return dataResponse("Error", message, errorDto(code, message))
+}
+
+// changeSubscription applies a client's request to the subscription,
+// and returns the message to reply with.
+func changeSubscription(subscription *live.Subscription, request subscriptionRequest) DataDto {
+	for _, topic := range request.Topics {
+		if !live.ValidTopic(topic) {
+			return subscriptionError("subscriptions/invalid-topic",
+				fmt.Sprintf("Unknown topic %q", topic))
+		}
+	}
+	switch request.Action {
+	case subscribeAction:
+		subscription.Follow(request.Topics...)
+	case unsubscribeAction:
+		subscription.Unfollow(request.Topics...)
+	default:
+		return subscriptionError("subscriptions/invalid-action",
+			fmt.Sprintf("Unknown action %q, expected subscribe or unsubscribe", request.Action))
+	}
+	return dataResponse("Subscription", "Topics followed",
+		subscriptionDto{Topics: subscription.Topics()})
+}
+
+// expiryCheckInterval is how often connections check whether the token
+// used to open them has expired. The server's clock can be moved, so
+// connections check rather than wait for the expiry.
+const expiryCheckInterval = time.Second
+
+// tokenExpiry is when the token used to open a connection expires. It's
+// the zero time if the token doesn't expire.
+func tokenExpiry(c echo.Context) time.Time {
+	claims := c.Get("user").(*jwt.Token).Claims.(jwt.MapClaims)
+	expiresAt, ok := claims["exp"].(float64)
+	if !ok {
+		return time.Time{}
+	}
+	return time.Unix(int64(expiresAt), 0)
+}
+
+// subscribe lets clients follow the changes to fixtures, teams and the
+// day's fixtures over a single WebSocket. Messages are sent in the same
+// envelope as the rest of the API. The connection is closed when the
+// token used to open it expires.
+func subscribe(hub *live.Hub, clk clock.Clock) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		expiry := tokenExpiry(c)
+		server := websocket.Server{Handler: func(ws *websocket.Conn) {
+			defer ws.Close()
+			subscription, _ := hub.Subscribe(0)
+			defer subscription.Close()
+			expiryCheck := time.NewTicker(expiryCheckInterval)
+			defer expiryCheck.Stop()
+
+			// The reader stops once the handler is done, even if it's
+			// holding a request nobody will take.
+			done := make(chan struct{})
+			defer close(done)
+			requests := make(chan subscriptionRequest)
+			go func() {
+				defer close(requests)
+				for {
+					request := subscriptionRequest{}
+					if err := websocket.JSON.Receive(ws, &request); err != nil {
+						return
+					}
+					select {
+					case requests <- request:
+					case <-done:
+						return
+					}
+				}
+			}()
+
+			for {
+				var reply DataDto
+				select {
+				case <-expiryCheck.C:
+					if expiry.IsZero() || clock.Now(clk).Before(expiry) {
+						continue
+					}
+					websocket.JSON.Send(ws, subscriptionError(unauthorizedErrorCode, "Token has expired"))
+					return
+				case request, ok := <-requests:
+					if !ok {
+						return
+					}
+					reply = changeSubscription(subscription, request)
+				case message, ok := <-subscription.Messages():
+					if !ok {
+						websocket.JSON.Send(ws, subscriptionError("subscriptions/too-slow",
+							"Messages were not received fast enough"))
+						return
+					}
+					reply = dataResponse("FixtureChange", "", message.Change)
+				}
+				if err := websocket.JSON.Send(ws, reply); err != nil {
+					return
+				}
+			}
+		}}
+		server.ServeHTTP(c.Response(), c.Request())
+		return nil
+	}
+}
+
+func subscriptionRoutesProvider(hub *live.Hub, clk clock.Clock) RouteProvider {
+	return func(e *echo.Echo) {
+		e.GET("/ws", subscribe(hub, clk), tokenFromQuery, jwtMiddleware)
+	}
+}
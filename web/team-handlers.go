@@ -1,30 +1,43 @@
 package web
 
 import (
+	"context"
 	"fmt"
 	"gomoney-mock-epl/database"
+	customErrors "gomoney-mock-epl/errors"
+	"gomoney-mock-epl/fixtures"
+	"gomoney-mock-epl/players"
+	"gomoney-mock-epl/seasons"
+	"gomoney-mock-epl/stadiums"
 	"gomoney-mock-epl/teams"
+	"gomoney-mock-epl/transfers"
 	"net/http"
+	"strconv"
 
 	"github.com/labstack/echo/v4"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type CreateTeamRequest struct {
 	City        string `json:"city"`
 	HomeStadium string `json:"home_stadium"`
+	Stadium     string `json:"stadium"`
 	LogoURL     string `json:"logo_url"`
 	Name        string `json:"name"`
 	NameAbbr    string `json:"name_abbr"`
 	ShortName   string `json:"short_name"`
+	Rating      int    `json:"rating"`
 }
 
 func (c *CreateTeamRequest) FromTeam(team teams.Team) *CreateTeamRequest {
 	c.City = team.City
 	c.HomeStadium = team.HomeStadium
+	c.Stadium = team.Stadium
 	c.LogoURL = team.LogoURL
 	c.Name = team.Name
 	c.NameAbbr = team.NameAbbr
 	c.ShortName = team.ShortName
+	c.Rating = team.Rating
 	return c
 }
 
@@ -33,26 +46,56 @@ func (c CreateTeamRequest) ToTeam(teamID string) teams.Team {
 		ID:          teamID,
 		City:        c.City,
 		HomeStadium: c.HomeStadium,
+		Stadium:     c.Stadium,
 		LogoURL:     c.LogoURL,
 		Name:        c.Name,
 		NameAbbr:    c.NameAbbr,
 		ShortName:   c.ShortName,
+		Rating:      c.Rating,
 	}
 }
 
-func createTeam(db teams.TeamsDB) echo.HandlerFunc {
+// linkStadium takes a team's home stadium and city from
+// the stadium it's linked to, if any.
+func linkStadium(ctx context.Context, db stadiums.StadiumsDB, team *teams.Team) error {
+	if team.Stadium == "" {
+		return nil
+	}
+	stadium, err := db.ByID(ctx, team.Stadium)
+	if err != nil {
+		return err
+	}
+	if stadium == nil {
+		return customErrors.ValidationError{
+			Code:    "teams/invalid-team",
+			Message: "Parts of the team supplied are invalid.",
+			Details: []customErrors.ValidationErrorDetails{{Field: "stadium", Message: "Unknown stadium"}},
+		}
+	}
+	team.HomeStadium = stadium.Name
+	team.City = stadium.City
+	return nil
+}
+
+func createTeam(db teams.TeamsDB, stadiumsDB stadiums.StadiumsDB) echo.HandlerFunc {
 	return func(c echo.Context) error {
 		dto := CreateTeamRequest{}
 		if err := c.Bind(&dto); err != nil {
 			return err
 		}
-		team, err := db.Create(c.Request().Context(), teams.Team{
+		team := teams.Team{
 			HomeStadium: dto.HomeStadium,
+			Stadium:     dto.Stadium,
 			LogoURL:     dto.LogoURL,
 			Name:        dto.Name,
 			NameAbbr:    dto.NameAbbr,
 			ShortName:   dto.ShortName,
-		})
+			Rating:      dto.Rating,
+		}
+		if err := linkStadium(c.Request().Context(), stadiumsDB, &team); err != nil {
+			return err
+		}
+		created, err := db.Create(c.Request().Context(), team)
 		if err != nil {
 			if database.IsDuplicateKeyError(err) {
 				return echo.NewHTTPError(http.StatusConflict,
@@ -61,32 +104,183 @@ func createTeam(db teams.TeamsDB) echo.HandlerFunc {
 			return err
 		}
 		return c.JSON(http.StatusCreated,
-			dataResponse("Team", "Team created successfully", team))
+			dataResponse("Team", "Team created successfully", created))
 	}
 }
 
+// listTeams lists the teams in the league, or the archived
+// teams with ?archived=true.
 func listTeams(db teams.TeamsDB) echo.HandlerFunc {
 	return func(c echo.Context) error {
-		teams, err := db.List(c.Request().Context())
+		archived := false
+		if s := c.QueryParam("archived"); s != "" {
+			var err error
+			if archived, err = strconv.ParseBool(s); err != nil {
+				return echo.NewHTTPError(http.StatusBadRequest,
+					errorDto("teams/invalid-query", "archived must be true or false"))
+			}
+		}
+		list, message := db.List, "Available EPL teams"
+		if archived {
+			list, message = db.Archived, "Archived EPL teams"
+		}
+		teams, err := list(c.Request().Context())
 		if err != nil {
 			return err
 		}
 		return c.JSON(http.StatusOK,
-			dataResponse("Teams", "Available EPL teams", teams))
+			dataResponse("Teams", message, teams))
+	}
+}
+
+// teamInUseLimit is how many of the fixtures referencing a team are
+// listed when it can't be deleted.
+const teamInUseLimit = 20
+
+// TeamReferencesDto counts what references a team.
+type TeamReferencesDto struct {
+	Fixtures  int64 `json:"fixtures"`
+	Seasons   int64 `json:"seasons"`
+	Players   int64 `json:"players"`
+	Transfers int64 `json:"transfers"`
+}
+
+// others counts the references deleting the team's fixtures leaves.
+func (r TeamReferencesDto) others() int64 {
+	return r.Seasons + r.Players + r.Transfers
+}
+
+// blocks reports whether the references keep a team from being deleted
+// with the policy. Cascades only delete the team's fixtures.
+func (r TeamReferencesDto) blocks(policy teams.DeletePolicy) bool {
+	if policy == teams.Cascade {
+		return r.others() > 0
 	}
+	return r.Fixtures+r.others() > 0
 }
 
-func deleteTeam(db teams.TeamsDB) echo.HandlerFunc {
+// TeamInUseDto is the error sent when a team can't be deleted. It counts
+// what still references the team, and lists its first fixtures' IDs.
+type TeamInUseDto struct {
+	ErrorDto
+	References TeamReferencesDto    `json:"references"`
+	Fixtures   []primitive.ObjectID `json:"fixtures"`
+}
+
+// teamReferences finds the documents referencing teams, which archives
+// can't be restored without.
+type teamReferences struct {
+	fixtures  fixtures.DB
+	seasons   seasons.SeasonsDB
+	players   players.PlayersDB
+	transfers transfers.DB
+}
+
+func (r teamReferences) count(ctx context.Context, teamID string) (TeamReferencesDto, error) {
+	counts := TeamReferencesDto{}
+	var err error
+	if counts.Fixtures, err = r.fixtures.CountByTeam(ctx, teamID); err != nil {
+		return counts, err
+	}
+	if counts.Seasons, err = r.seasons.CountByTeam(ctx, teamID); err != nil {
+		return counts, err
+	}
+	if counts.Players, err = r.players.CountByTeam(ctx, teamID); err != nil {
+		return counts, err
+	}
+	counts.Transfers, err = r.transfers.CountByTeam(ctx, teamID)
+	return counts, err
+}
+
+// inUse is the error for a team the references keep from being deleted.
+func (r teamReferences) inUse(ctx context.Context, teamID string, counts TeamReferencesDto) error {
+	ids, err := r.fixtures.TeamFixtureIDs(ctx, teamID, teamInUseLimit)
+	if err != nil {
+		return err
+	}
+	message := "The team is still referenced. Delete its fixtures with policy=cascade, or archive the team with policy=archive"
+	if counts.others() > 0 {
+		message = "Seasons, players or transfers still reference the team. Archive it with policy=archive"
+	}
+	return echo.NewHTTPError(http.StatusConflict, TeamInUseDto{
+		ErrorDto:   errorDto("teams/in-use", message),
+		References: counts,
+		Fixtures:   ids,
+	})
+}
+
+// deleteTeam removes a team following the policy in ?policy=. Restrict,
+// the default, refuses while fixtures, seasons, players or transfers
+// reference the team. Cascade deletes the team's fixtures with it, but
+// refuses while anything else references it. Archive keeps the team,
+// archived, so what references it still resolves.
+func deleteTeam(db teams.TeamsDB, refs teamReferences) echo.HandlerFunc {
 	return func(c echo.Context) error {
+		ctx := c.Request().Context()
 		teamID := c.Param("team_id")
-		if err := db.Delete(c.Request().Context(), teamID); err != nil {
+		policy := teams.DeletePolicy(c.QueryParam("policy"))
+		switch policy {
+		case "", teams.Restrict, teams.Cascade, teams.Archive:
+		default:
+			return echo.NewHTTPError(http.StatusBadRequest,
+				errorDto("teams/invalid-policy", "The policy must be restrict, cascade or archive"))
+		}
+		// Unknown teams are turned away before any fixture is touched.
+		team, err := db.ByID(ctx, teamID)
+		if err != nil {
+			return err
+		}
+		if team == nil {
+			return echo.NewHTTPError(http.StatusNotFound, teamNotFound)
+		}
+		if policy == teams.Archive {
+			team, err := db.Archive(ctx, teamID)
+			if err != nil {
+				return err
+			}
+			if team == nil {
+				return echo.NewHTTPError(http.StatusNotFound, teamNotFound)
+			}
+			return c.JSON(http.StatusOK,
+				dataResponse("Team", "Team archived successfully", team))
+		}
+		counts, err := refs.count(ctx, teamID)
+		if err != nil {
+			return err
+		}
+		if counts.blocks(policy) {
+			return refs.inUse(ctx, teamID, counts)
+		}
+		deleted, err := db.Delete(ctx, teamID)
+		if err != nil {
+			return err
+		}
+		if !deleted {
+			return echo.NewHTTPError(http.StatusNotFound, teamNotFound)
+		}
+		// Fixtures are only deleted once the team is gone, so none can be
+		// added for it after. What was added while the team was being
+		// deleted is found by counting again, and the team is put back.
+		if policy == teams.Cascade {
+			if _, err := refs.fixtures.DeleteTeamFixtures(ctx, teamID); err != nil {
+				return err
+			}
+		}
+		counts, err = refs.count(ctx, teamID)
+		if err != nil {
 			return err
 		}
+		if counts.blocks(teams.Restrict) {
+			if err := db.Reinsert(ctx, *team); err != nil {
+				return err
+			}
+			return refs.inUse(ctx, teamID, counts)
+		}
 		return c.JSON(http.StatusOK, nil)
 	}
 }
 
-func editTeam(db teams.TeamsDB) echo.HandlerFunc {
+func editTeam(db teams.TeamsDB, stadiumsDB stadiums.StadiumsDB) echo.HandlerFunc {
 	return func(c echo.Context) error {
 		teamID := c.Param("team_id")
 		team, err := db.ByID(c.Request().Context(), teamID)
@@ -103,6 +297,10 @@ func editTeam(db teams.TeamsDB) echo.HandlerFunc {
 		}
 		update := dto.ToTeam(team.ID)
 		update.CreatedAt = team.CreatedAt
+		update.ArchivedAt = team.ArchivedAt
+		if err := linkStadium(c.Request().Context(), stadiumsDB, &update); err != nil {
+			return err
+		}
 		team, err = db.Update(c.Request().Context(), update)
 		if err != nil {
 			return err
@@ -127,13 +325,89 @@ func viewTeam(db teams.TeamsDB) echo.HandlerFunc {
 			dataResponse("Team", fmt.Sprintf("Team: %q", team.Name), team))
 	}
 }
-func teamRoutesProvider(db teams.TeamsDB) RouteProvider {
+
+var teamNotFound = errorDto("NotFound", "That team does not exist")
+
+// listTeamFixtures pages through a team's fixtures, taking the same
+// query as the fixtures listing apart from the team.
+func listTeamFixtures(teamsDB teams.TeamsDB, fixturesDB fixtures.DB) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		team, err := teamsDB.ByID(c.Request().Context(), c.Param("team_id"))
+		if err != nil {
+			return err
+		}
+		if team == nil {
+			return echo.NewHTTPError(http.StatusNotFound, teamNotFound)
+		}
+		dto := fixtures.ListRequest{}
+		if err := (&echo.DefaultBinder{}).BindQueryParams(c, &dto); err != nil {
+			return err
+		}
+		dto.Team = team.ID
+		page, err := fixturesDB.Page(c.Request().Context(), dto)
+		if err != nil {
+			return err
+		}
+		return c.JSON(http.StatusOK,
+			pagedResponse("Fixtures", fmt.Sprintf("%s fixtures", team.Name), page.Fixtures, Pagination{
+				Limit: page.Limit,
+				Next:  page.Next,
+				Prev:  page.Prev,
+			}))
+	}
+}
+
+func listTeamResults(teamsDB teams.TeamsDB, fixturesDB fixtures.DB) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		team, err := teamsDB.ByID(c.Request().Context(), c.Param("team_id"))
+		if err != nil {
+			return err
+		}
+		if team == nil {
+			return echo.NewHTTPError(http.StatusNotFound, teamNotFound)
+		}
+		results, err := fixturesDB.TeamResults(c.Request().Context(), team.ID)
+		if err != nil {
+			return err
+		}
+		return c.JSON(http.StatusOK,
+			dataResponse("Fixtures", fmt.Sprintf("%s results", team.Name), results))
+	}
+}
+
+func viewNextTeamFixture(teamsDB teams.TeamsDB, fixturesDB fixtures.DB) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		team, err := teamsDB.ByID(c.Request().Context(), c.Param("team_id"))
+		if err != nil {
+			return err
+		}
+		if team == nil {
+			return echo.NewHTTPError(http.StatusNotFound, teamNotFound)
+		}
+		fixture, err := fixturesDB.NextFixture(c.Request().Context(), team.ID)
+		if err != nil {
+			return err
+		}
+		if fixture == nil {
+			return echo.NewHTTPError(http.StatusNotFound,
+				errorDto("NotFound", fmt.Sprintf("%s have no upcoming fixtures", team.Name)))
+		}
+		return c.JSON(http.StatusOK,
+			dataResponse("Fixture", fmt.Sprintf("%s - %s",
+				fixture.HomeTeam.ShortName, fixture.AwayTeam.ShortName), fixture))
+	}
+}
+
+func teamRoutesProvider(db teams.TeamsDB, fixturesDB fixtures.DB, stadiumsDB stadiums.StadiumsDB, refs teamReferences) RouteProvider {
 	return func(e *echo.Echo) {
 		teams := e.Group("/teams", jwtMiddleware)
-		teams.POST("/", createTeam(db), onlyAdmins)
+		teams.POST("/", createTeam(db, stadiumsDB), onlyAdmins)
 		teams.GET("/", listTeams(db))
-		teams.DELETE("/:team_id", deleteTeam(db), onlyAdmins)
+		teams.DELETE("/:team_id", deleteTeam(db, refs), onlyAdmins)
 		teams.GET("/:team_id", viewTeam(db))
-		teams.PATCH("/:team_id", editTeam(db), onlyAdmins)
+		teams.PATCH("/:team_id", editTeam(db, stadiumsDB), onlyAdmins)
+		teams.GET("/:team_id/fixtures", listTeamFixtures(db, fixturesDB))
+		teams.GET("/:team_id/results", listTeamResults(db, fixturesDB))
+		teams.GET("/:team_id/next", viewNextTeamFixture(db, fixturesDB))
 	}
 }
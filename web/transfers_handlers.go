@@ -0,0 +1,138 @@
+package web
+
+import (
+	"fmt"
+	"gomoney-mock-epl/transfers"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+var transferNotFound = errorDto("NotFound", "That transfer does not exist")
+
+func recordTransfer(db transfers.DB) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		dto := transfers.TransferRequest{}
+		if err := c.Bind(&dto); err != nil {
+			return err
+		}
+		transfer, err := db.Record(c.Request().Context(), dto)
+		if err != nil {
+			return err
+		}
+		return c.JSON(http.StatusCreated,
+			dataResponse("Transfer", "Transfer recorded successfully", transfer))
+	}
+}
+
+func viewTransfer(db transfers.DB) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		transfer, err := db.ByID(c.Request().Context(), c.Param("transfer_id"))
+		if err != nil {
+			return err
+		}
+		if transfer == nil {
+			return echo.NewHTTPError(http.StatusNotFound, transferNotFound)
+		}
+		return c.JSON(http.StatusOK,
+			dataResponse("Transfer", fmt.Sprintf("Transfer in the %s window", transfer.Window), transfer))
+	}
+}
+
+func viewPlayerTransfers(db transfers.DB) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		history, err := db.PlayerHistory(c.Request().Context(), c.Param("player_id"))
+		if err != nil {
+			return err
+		}
+		if history == nil {
+			return echo.NewHTTPError(http.StatusNotFound, playerNotFound)
+		}
+		return c.JSON(http.StatusOK,
+			dataResponse("TransferHistory", "Player transfer history", history))
+	}
+}
+
+func listTeamTransfers(db transfers.DB) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		team, err := db.TeamsDB.ByID(c.Request().Context(), c.Param("team_id"))
+		if err != nil {
+			return err
+		}
+		if team == nil {
+			return echo.NewHTTPError(http.StatusNotFound, teamNotFound)
+		}
+		window := transfers.Window(c.QueryParam("window"))
+		if _, ok := window.Opens(); window != "" && !ok {
+			return echo.NewHTTPError(http.StatusBadRequest,
+				errorDto("transfers/invalid-window", "Windows look like 2024-summer or 2025-winter"))
+		}
+		windows, err := db.ByTeam(c.Request().Context(), team.ID, window)
+		if err != nil {
+			return err
+		}
+		return c.JSON(http.StatusOK,
+			dataResponse("TransferWindows", fmt.Sprintf("%s transfers", team.Name), windows))
+	}
+}
+
+// queryDate reads a date from the query, as an RFC 3339 timestamp or a
+// YYYY-MM-DD date. Invalid dates are refused with the error code given.
+func queryDate(c echo.Context, name string, fallback time.Time, code string) (time.Time, error) {
+	value := c.QueryParam(name)
+	if value == "" {
+		return fallback, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return t, echo.NewHTTPError(http.StatusBadRequest,
+			errorDto(code, fmt.Sprintf("The %s date must look like 2024-08-16 or 2024-08-16T19:00:00Z", name)))
+	}
+	return t, nil
+}
+
+func listTeamMembers(db transfers.DB) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		team, err := db.TeamsDB.ByID(c.Request().Context(), c.Param("team_id"))
+		if err != nil {
+			return err
+		}
+		if team == nil {
+			return echo.NewHTTPError(http.StatusNotFound, teamNotFound)
+		}
+		now := db.Now()
+		from, err := queryDate(c, "from", now, "transfers/invalid-date")
+		if err != nil {
+			return err
+		}
+		to, err := queryDate(c, "to", from.Add(time.Nanosecond), "transfers/invalid-date")
+		if err != nil {
+			return err
+		}
+		if !to.After(from) {
+			return echo.NewHTTPError(http.StatusBadRequest,
+				errorDto("transfers/invalid-date", "The to date must be after the from date"))
+		}
+		members, err := db.Members(c.Request().Context(), team.ID, from, to)
+		if err != nil {
+			return err
+		}
+		return c.JSON(http.StatusOK,
+			dataResponse("Members", fmt.Sprintf("%s players", team.Name), members))
+	}
+}
+
+func transferRoutesProvider(db transfers.DB) RouteProvider {
+	return func(e *echo.Echo) {
+		transfers := e.Group("/transfers", jwtMiddleware)
+		transfers.POST("/", recordTransfer(db), onlyAdmins)
+		transfers.GET("/:transfer_id", viewTransfer(db))
+		e.GET("/players/:player_id/transfers", viewPlayerTransfers(db), jwtMiddleware)
+		e.GET("/teams/:team_id/transfers", listTeamTransfers(db), jwtMiddleware)
+		e.GET("/teams/:team_id/members", listTeamMembers(db), jwtMiddleware)
+	}
+}
@@ -2,11 +2,22 @@ package web
 
 import (
 	"fmt"
+	"time"
 
+	"gomoney-mock-epl/backup"
+	"gomoney-mock-epl/clock"
 	"gomoney-mock-epl/config"
 	"gomoney-mock-epl/database"
 	"gomoney-mock-epl/fixtures"
+	"gomoney-mock-epl/live"
+	"gomoney-mock-epl/officials"
+	"gomoney-mock-epl/players"
+	"gomoney-mock-epl/seasons"
+	"gomoney-mock-epl/stadiums"
+	"gomoney-mock-epl/standings"
+	"gomoney-mock-epl/stats"
 	"gomoney-mock-epl/teams"
+	"gomoney-mock-epl/transfers"
 	"gomoney-mock-epl/users"
 
 	"github.com/labstack/echo/v4"
@@ -16,52 +27,154 @@ import (
 
 type RouteProvider func(*echo.Echo)
 
+// liveHistorySize is the number of fixture changes kept
+// for clients that reconnect to live streams.
+const liveHistorySize = 1024
+
+// formCacheTTL is how long team form guides are cached for, at most.
+const formCacheTTL = 10 * time.Minute
+
+// bodyLimit is the most a request body can hold, except on the routes
+// in ownBodyLimits, which set limits of their own.
+const bodyLimit = "8K"
+
+var ownBodyLimits = map[string]bool{
+	"/fixtures/import": true,
+	"/admin/import":    true,
+}
+
 type Application struct {
 	*config.Config
-	DBClient   *mongo.Client
-	DefaultDB  *mongo.Database
-	AdminDB    users.AdminsDB
-	FixturesDB fixtures.DB
-	UsersDB    users.UsersDB
-	TeamsDB    teams.TeamsDB
+	DBClient    *mongo.Client
+	DefaultDB   *mongo.Database
+	AdminDB     users.AdminsDB
+	FixturesDB  fixtures.DB
+	SeasonsDB   seasons.SeasonsDB
+	UsersDB     users.UsersDB
+	TeamsDB     teams.TeamsDB
+	PlayersDB   players.PlayersDB
+	StadiumsDB  stadiums.StadiumsDB
+	OfficialsDB officials.OfficialsDB
+	Transfers   transfers.DB
+	Standings   standings.Service
+	Stats       stats.Service
+	Live        *live.Hub
+	Backups     backup.Service
+	// Clock is the server's notion of the current time. It's a virtual
+	// clock that admins can move, except in production.
+	Clock clock.Clock
 	*echo.Echo
 }
 
 func NewApplication(db *mongo.Client, cfg config.Config) (*Application, error) {
+	var clk clock.Clock = clock.Real{}
+	var virtualClock *clock.Virtual
+	if !cfg.IsProduction() {
+		virtualClock = clock.NewVirtual()
+		clk = virtualClock
+	}
+
 	defaultDB := db.Database(database.MockEPLDatabase)
 	adminsCollection := defaultDB.Collection(database.AdminsCollection)
-	adminsDB := users.AdminsDB{Collection: adminsCollection}
+	adminsDB := users.AdminsDB{Collection: adminsCollection, Clock: clk}
 	usersCollection := defaultDB.Collection(database.UsersCollection)
-	usersDB := users.UsersDB{Collection: usersCollection}
+	usersDB := users.UsersDB{Collection: usersCollection, Clock: clk}
 	teamsCollection := defaultDB.Collection(database.TeamsCollection)
-	teamsDB := teams.TeamsDB{Collection: teamsCollection}
+	teamsDB := teams.TeamsDB{Collection: teamsCollection, Clock: clk}
+	seasonsCollection := defaultDB.Collection(database.SeasonsCollection)
+	seasonsDB := seasons.SeasonsDB{Collection: seasonsCollection, TeamsDB: teamsDB, Clock: clk}
+	playersCollection := defaultDB.Collection(database.PlayersCollection)
+	playersDB := players.PlayersDB{
+		Collection: playersCollection,
+		TeamsDB:    teamsDB,
+		SeasonsDB:  seasonsDB,
+		Clock:      clk,
+	}
+	stadiumsCollection := defaultDB.Collection(database.StadiumsCollection)
+	stadiumsDB := stadiums.StadiumsDB{Collection: stadiumsCollection, Clock: clk}
+	officialsCollection := defaultDB.Collection(database.OfficialsCollection)
+	officialsDB := officials.OfficialsDB{Collection: officialsCollection, Clock: clk}
+	transfersCollection := defaultDB.Collection(database.TransfersCollection)
+	transfersDB := transfers.DB{Collection: transfersCollection, PlayersDB: playersDB, Clock: clk}
 	fixturesCollection := defaultDB.Collection(database.FixturesCollection)
-	fixturesDB := fixtures.DB{Collection: fixturesCollection, TeamsDB: teamsDB}
+	hub := live.NewHub(liveHistorySize)
+	forms := stats.NewFormCache(formCacheTTL)
+	fixturesDB := fixtures.DB{
+		Collection:  fixturesCollection,
+		TeamsDB:     teamsDB,
+		SeasonsDB:   seasonsDB,
+		Players:     playersDB,
+		Stadiums:    stadiumsDB,
+		OfficialsDB: officialsDB,
+		Publisher:   fixtures.Publishers{hub, forms},
+		Clock:       clk,
+	}
+	// Teams are updated through the application's TeamsDB, which keeps
+	// the team names copied into fixtures in sync, and stadiums through
+	// its StadiumsDB, which does the same for teams. Seasons are updated
+	// through its SeasonsDB, which checks them against their fixtures.
+	teamsDB.Syncer = fixturesDB
+	stadiumsDB.Syncer = teamsDB
+	seasonsDB.Fixtures = fixturesDB
 
 	e := echo.New()
-	e.Use(middleware.Logger(),
+	e.Use(redactTokens,
+		withClock(clk),
+		middleware.Logger(),
 		middleware.Recover(),
 		middleware.CORS(),
-		middleware.BodyLimit("8K"))
+		middleware.BodyLimitWithConfig(middleware.BodyLimitConfig{
+			Limit:   bodyLimit,
+			Skipper: func(c echo.Context) bool { return ownBodyLimits[c.Path()] },
+		}))
 	e.HTTPErrorHandler = DefaultErrorHandler
 	e.Server.Addr = fmt.Sprintf("0.0.0.0:%d", cfg.HttpBindPort)
 
 	app := &Application{
-		AdminDB:    adminsDB,
-		Config:     &cfg,
-		DBClient:   db,
-		DefaultDB:  defaultDB,
-		Echo:       e,
-		UsersDB:    usersDB,
-		TeamsDB:    teamsDB,
-		FixturesDB: fixturesDB,
+		AdminDB:     adminsDB,
+		Config:      &cfg,
+		DBClient:    db,
+		DefaultDB:   defaultDB,
+		Echo:        e,
+		UsersDB:     usersDB,
+		TeamsDB:     teamsDB,
+		FixturesDB:  fixturesDB,
+		SeasonsDB:   seasonsDB,
+		PlayersDB:   playersDB,
+		StadiumsDB:  stadiumsDB,
+		OfficialsDB: officialsDB,
+		Transfers:   transfersDB,
+		Standings:   standings.Service{Fixtures: fixturesDB, Teams: teamsDB},
+		Stats:       stats.Service{Fixtures: fixturesDB, Teams: teamsDB, Players: playersDB, Forms: forms},
+		Live:        hub,
+		Backups:     backup.Service{DB: defaultDB, Clock: clk},
+		Clock:       clk,
 	}
 
 	adminAuthRoutesProvider(app.AdminDB)(app.Echo)
 	userAuthRoutesProvider(app.UsersDB)(app.Echo)
-	teamRoutesProvider(app.TeamsDB)(app.Echo)
+	teamRoutesProvider(app.TeamsDB, app.FixturesDB, app.StadiumsDB, teamReferences{
+		fixtures:  app.FixturesDB,
+		seasons:   app.SeasonsDB,
+		players:   app.PlayersDB,
+		transfers: app.Transfers,
+	})(app.Echo)
 	fixturesRoutesProvider(app.FixturesDB)(app.Echo)
-	searchRoutesProvider(app.TeamsDB, app.FixturesDB)(app.Echo)
+	seasonRoutesProvider(app.SeasonsDB, app.FixturesDB)(app.Echo)
+	playerRoutesProvider(app.PlayersDB)(app.Echo)
+	stadiumRoutesProvider(app.StadiumsDB, app.FixturesDB)(app.Echo)
+	officialRoutesProvider(app.OfficialsDB, app.FixturesDB)(app.Echo)
+	transferRoutesProvider(app.Transfers)(app.Echo)
+	searchRoutesProvider(app.TeamsDB, app.FixturesDB, app.PlayersDB)(app.Echo)
+	standingsRoutesProvider(app.Standings)(app.Echo)
+	statsRoutesProvider(app.Stats)(app.Echo)
+	liveRoutesProvider(app.FixturesDB, app.Live)(app.Echo)
+	calendarRoutesProvider(app.TeamsDB, app.FixturesDB)(app.Echo)
+	subscriptionRoutesProvider(app.Live, app.Clock)(app.Echo)
+	backupRoutesProvider(app.Backups)(app.Echo)
+	if virtualClock != nil {
+		clockRoutesProvider(virtualClock)(app.Echo)
+	}
 	app.GET("/", func(c echo.Context) error {
 		return c.File("docs/index.html")
 	})